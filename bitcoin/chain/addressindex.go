@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"bytes"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"strconv"
+)
+
+// Optional index from each address to the outputs paying it and the
+// inputs which spent them, so looking up any address doesn't mean
+// scanning every block. Only lives in memory, it is rebuilt as the
+// blocks are loaded on start up
+type AddressIndex struct {
+	outputs   map[string][]*IndexedOutput // By AddressKey, in the order they were connected
+	outpoints map[string]*IndexedOutput   // To find what an input spends
+}
+
+// An output paying an indexed address and what spent it, if anything
+type IndexedOutput struct {
+	TxID        []byte
+	Index       uint64
+	Value       uint64
+	Height      uint64 // Block the output confirmed in
+	Coinbase    bool
+	SpentBy     []byte // Transaction which spent it, nil while unspent
+	SpentHeight uint64
+}
+
+func NewAddressIndex() *AddressIndex {
+	return &AddressIndex{
+		outputs:   make(map[string][]*IndexedOutput),
+		outpoints: make(map[string]*IndexedOutput)}
+}
+
+// Outputs locked by a script are indexed by the script, the rest by the
+// key they pay
+func AddressKey(pubKey []byte, script []byte) string {
+	if len(script) != 0 {
+		return "s" + string(script)
+	}
+	return "k" + string(pubKey)
+}
+
+func outputAddressKey(output *pb.TXO) string {
+	return AddressKey(output.ReceiverPubKey, output.LockingScript)
+}
+
+func outpointKey(txID []byte, index uint64) string {
+	return string(txID) + ":" + strconv.FormatUint(index, 10)
+}
+
+// Index the outputs of a block and mark the ones its inputs spend.
+// Blocks have to be connected in chain order
+func (idx *AddressIndex) ConnectBlock(block *pb.Block) {
+	height := block.Header.Height
+	for _, transaction := range block.Transactions {
+		txID := GetTransactionHash(transaction)
+		for _, input := range transaction.Vin {
+			if spent, ok := idx.outpoints[outpointKey(input.TxID, input.Index)]; ok {
+				spent.SpentBy = txID
+				spent.SpentHeight = height
+			}
+		}
+		for i, output := range transaction.Vout {
+			indexed := &IndexedOutput{
+				TxID:     txID,
+				Index:    uint64(i),
+				Value:    output.Value,
+				Height:   height,
+				Coinbase: IsCoinbase(transaction)}
+			key := outputAddressKey(output)
+			idx.outputs[key] = append(idx.outputs[key], indexed)
+			idx.outpoints[outpointKey(txID, uint64(i))] = indexed
+		}
+	}
+}
+
+// Undo ConnectBlock for the tip, when a block from another fork replaces it
+func (idx *AddressIndex) DisconnectBlock(block *pb.Block) {
+	for i := len(block.Transactions) - 1; i >= 0; i-- {
+		transaction := block.Transactions[i]
+		txID := GetTransactionHash(transaction)
+		for j := len(transaction.Vout) - 1; j >= 0; j-- {
+			delete(idx.outpoints, outpointKey(txID, uint64(j)))
+			key := outputAddressKey(transaction.Vout[j])
+			outputs := idx.outputs[key]
+			// Connected last, so it's at the end
+			for k := len(outputs) - 1; k >= 0; k-- {
+				if outputs[k].Index == uint64(j) && bytes.Equal(outputs[k].TxID, txID) {
+					outputs = append(outputs[:k], outputs[k+1:]...)
+					break
+				}
+			}
+			if len(outputs) == 0 {
+				delete(idx.outputs, key)
+			} else {
+				idx.outputs[key] = outputs
+			}
+		}
+		for _, input := range transaction.Vin {
+			if spent, ok := idx.outpoints[outpointKey(input.TxID, input.Index)]; ok && bytes.Equal(spent.SpentBy, txID) {
+				spent.SpentBy = nil
+				spent.SpentHeight = 0
+			}
+		}
+	}
+}
+
+// Every output which ever paid the address, oldest first
+func (idx *AddressIndex) GetOutputs(key string) []*IndexedOutput {
+	return idx.outputs[key]
+}
+
+func (idx *AddressIndex) GetUnspent(key string) []*IndexedOutput {
+	var unspent []*IndexedOutput
+	for _, output := range idx.outputs[key] {
+		if output.SpentBy == nil {
+			unspent = append(unspent, output)
+		}
+	}
+	return unspent
+}
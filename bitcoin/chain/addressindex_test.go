@@ -0,0 +1,63 @@
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+func TestAddressIndex(t *testing.T) {
+	alice, bob := []byte("alice"), []byte("bob")
+	script := []byte{OP_RETURN}
+	coinbase := &pb.Transaction{Vout: []*pb.TXO{{ReceiverPubKey: alice, Value: 10}}, Height: 2}
+	first := &pb.Block{Header: &pb.BlockHeader{Height: 2}, Transactions: []*pb.Transaction{coinbase}}
+	spend := &pb.Transaction{Vin: []*pb.TXI{{TxID: GetTransactionHash(coinbase), Index: 0}},
+		Vout: []*pb.TXO{{ReceiverPubKey: bob, Value: 6}, {ReceiverPubKey: alice, Value: 3}, {LockingScript: script, Value: 1}}}
+	second := &pb.Block{Header: &pb.BlockHeader{Height: 3},
+		Transactions: []*pb.Transaction{{Vout: []*pb.TXO{{ReceiverPubKey: bob, Value: 10}}, Height: 3}, spend}}
+	idx := NewAddressIndex()
+	idx.ConnectBlock(first)
+	idx.ConnectBlock(second)
+	outputs := idx.GetOutputs(AddressKey(alice, nil))
+	if len(outputs) != 2 || !outputs[0].Coinbase || outputs[0].SpentHeight != 3 || outputs[1].Value != 3 {
+		t.Errorf("Alice was paid the coinbase then change %+v", outputs)
+	}
+	if unspent := idx.GetUnspent(AddressKey(alice, nil)); len(unspent) != 1 || unspent[0].Index != 1 {
+		t.Errorf("Only the change is unspent %+v", unspent)
+	}
+	if len(idx.GetUnspent(AddressKey(bob, nil))) != 2 || len(idx.GetOutputs(AddressKey(nil, script))) != 1 {
+		t.Error("Bob has two outputs and the script one")
+	}
+	idx.DisconnectBlock(second)
+	if unspent := idx.GetUnspent(AddressKey(alice, nil)); len(unspent) != 1 || !unspent[0].Coinbase || unspent[0].SpentBy != nil {
+		t.Errorf("Disconnecting the spend leaves the coinbase unspent %+v", unspent)
+	}
+	if len(idx.GetOutputs(AddressKey(bob, nil))) != 0 || len(idx.outpoints) != 1 {
+		t.Errorf("Nothing left of the second block %v", idx.outpoints)
+	}
+}
+
+// A block from another fork at the height after the tip's parent takes
+// the tip's place, and its outputs with it
+func TestAddressIndexReplacedTip(t *testing.T) {
+	alice, bob := []byte("alice"), []byte("bob")
+	chain := Blockchain{Blocks: make(map[string]*pb.Block), TxIndex: make(map[string]TxIndex), AddressIndex: NewAddressIndex()}
+	chain.AddGenesisBlock()
+	genesis := chain.TipsOfChains[0]
+	mined := func(prev *pb.Block, pubKey []byte) *pb.Block {
+		transactions := []*pb.Transaction{{Vout: []*pb.TXO{{ReceiverPubKey: pubKey, Value: 10}}, Height: prev.Header.Height + 1}}
+		return &pb.Block{Header: &pb.BlockHeader{Height: prev.Header.Height + 1, PrevBlockHash: GetBlockHash(prev),
+			MerkleRoot: GetMerkleRoot(transactions)}, Transactions: transactions}
+	}
+	ours := mined(genesis, alice)
+	chain.AddBlock(ours)
+	chain.AddBlock(mined(ours, alice))
+	// Their fork, we only hear about from its second block
+	theirs := mined(ours, bob)
+	chain.AddBlock(mined(theirs, bob))
+	if outputs := chain.AddressIndex.GetOutputs(AddressKey(alice, nil)); len(outputs) != 1 || outputs[0].Height != 2 {
+		t.Errorf("Alice should only have the block both forks share %+v", outputs)
+	}
+	if outputs := chain.AddressIndex.GetOutputs(AddressKey(bob, nil)); len(outputs) != 1 || outputs[0].Height != 4 {
+		t.Errorf("Bob should have the new tip's coinbase %+v", outputs)
+	}
+}
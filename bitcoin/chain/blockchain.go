@@ -0,0 +1,373 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/serialize"
+	"strconv"
+	"time"
+)
+
+type Blockchain struct {
+	Blocks map[string]*pb.Block
+	// Could be extended to handle temporary forks
+	TipsOfChains []*pb.Block
+	// Would be the pool of orphan blocks
+	//     orphanBlocks []*pb.Block
+	// Blocks whose parent we never got, to the block they follow on from
+	// in our chain instead. See AddBlock
+	gaps         map[string]*pb.Block
+	NextBlockNum int
+	Target       []byte // difficulty for mining the next block on our tip
+	// What the first blocks after genesis are mined at, it moves from there
+	StartTarget []byte
+	// This an index to lookup a block hash by transaction hash,
+	// the real bitcoin implementation has something similar but heavily cached/optimized
+	// see bitcoin/src/index/txindex.h
+	TxIndex map[string]TxIndex
+	// Outputs and spends of every address, nil unless enabled
+	AddressIndex *AddressIndex
+	// Network this chain is for, nil means a fixed target
+	Params *ChainParams
+	// Subsidy schedule and how many confirmations coinbase outputs need
+	BlockReward      uint64
+	HalvingInterval  uint64
+	CoinbaseMaturity uint64
+	// Clock blocks are checked against and how far ahead of it they can be
+	Clock          Clock
+	MaxFutureDrift uint64
+}
+
+// Block and index of transaction
+// to quickly look up a transaction
+type TxIndex struct {
+	BlockHash string
+	Index     int
+}
+
+// Wrapper with details about the location
+// of a specific TXI / TXO
+type UTXO struct {
+	Transaction *pb.Transaction // transaction which has the UTXO
+	Index       int             // vout index
+}
+
+// Starting target, blocks already in the chain are checked against it too
+func (b *Blockchain) SetTarget(inputTarget []byte) {
+	b.StartTarget = inputTarget
+	b.Target = GetTargetFromCompact(GetCompactTarget(inputTarget))
+}
+
+// Index the transactions of a block and make it the new tip
+func (b *Blockchain) AddBlock(block *pb.Block) {
+	blockHash := string(GetBlockHash(block))
+	if len(b.TipsOfChains) != 0 && !bytes.Equal(block.Header.PrevBlockHash, GetBlockHash(b.TipsOfChains[0])) {
+		b.replaceTip(block)
+	}
+	for i := range block.Transactions {
+		b.TxIndex[string(GetTransactionHash(block.Transactions[i]))] = TxIndex{BlockHash: blockHash,
+			Index: i}
+	}
+	if b.AddressIndex != nil {
+		b.AddressIndex.ConnectBlock(block)
+	}
+	b.Blocks[blockHash] = block
+	b.TipsOfChains[0] = block
+	b.NextBlockNum = int(block.Header.Height) + 1
+	b.Target = b.GetNextTarget(block)
+	if b.isRetargetHeight(block.Header.Height) {
+		logging.Chain.Info("Retargeted", "height", block.Header.Height, "target", hex.EncodeToString(b.Target))
+	}
+}
+
+// Built on another fork, so our tip is no longer in the chain. We never get
+// the rest of that fork, only the next block, so the block takes the tip's
+// place and follows on from the tip's parent. If the fork goes back further
+// our blocks below the tip stay in the chain, there's nothing to replace
+// them with until blocks can be fetched from peers
+func (b *Blockchain) replaceTip(block *pb.Block) {
+	tip := b.TipsOfChains[0]
+	tipHash := string(GetBlockHash(tip))
+	for _, transaction := range tip.Transactions {
+		txID := string(GetTransactionHash(transaction))
+		if b.TxIndex[txID].BlockHash == tipHash {
+			delete(b.TxIndex, txID)
+		}
+	}
+	if b.AddressIndex != nil {
+		b.AddressIndex.DisconnectBlock(tip)
+	}
+	if _, ok := b.Blocks[string(block.Header.PrevBlockHash)]; !ok {
+		if b.gaps == nil {
+			b.gaps = make(map[string]*pb.Block)
+		}
+		b.gaps[string(GetBlockHash(block))] = b.GetPrevBlock(tip)
+	}
+}
+
+// The block before this one in our chain, nil for genesis
+func (blockChain Blockchain) GetPrevBlock(block *pb.Block) *pb.Block {
+	if prev, ok := blockChain.Blocks[string(block.Header.PrevBlockHash)]; ok {
+		return prev
+	}
+	return blockChain.gaps[string(GetBlockHash(block))]
+}
+
+// Blocks of our chain from the tip back to genesis, leaving out tips
+// replaced by another fork
+func (blockChain Blockchain) GetMainChain() []*pb.Block {
+	var blocks []*pb.Block
+	if len(blockChain.TipsOfChains) == 0 {
+		return nil
+	}
+	for block := blockChain.TipsOfChains[0]; block != nil; block = blockChain.GetPrevBlock(block) {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func (b *Blockchain) AddGenesisBlock() {
+	params := b.Params
+	if params == nil {
+		params = &MAINNET
+	}
+	genesis := params.GenesisBlock()
+	b.NextBlockNum = 2 // Next block num
+	b.Blocks[string(GetBlockHash(genesis))] = genesis
+	// Currently the longest chain is this block to build on
+	// top of
+	b.TipsOfChains = append(b.TipsOfChains, genesis)
+}
+
+func (blockChain Blockchain) GetBalance(key *ecdsa.PublicKey) uint64 {
+	var balance uint64
+	for _, utxo := range blockChain.GetUTXOs(key) {
+		balance += blockChain.GetValueUTXO(utxo)
+	}
+	return balance
+}
+
+func (blockChain Blockchain) GetTransaction(hash []byte) *pb.Transaction {
+	idx, ok := blockChain.TxIndex[string(hash)]
+	if !ok {
+		return nil
+	}
+	return blockChain.Blocks[idx.BlockHash].Transactions[idx.Index]
+}
+
+func (blockChain Blockchain) GetValueUTXO(utxo *UTXO) uint64 {
+	txIndex := blockChain.TxIndex[string(GetTransactionHash(utxo.Transaction))]
+	block := blockChain.Blocks[txIndex.BlockHash]
+	return block.Transactions[txIndex.Index].Vout[utxo.Index].Value
+}
+
+func (blockChain Blockchain) GetTXO(utxo *UTXO) *pb.TXO {
+	txIndex := blockChain.TxIndex[string(GetTransactionHash(utxo.Transaction))]
+	block := blockChain.Blocks[txIndex.BlockHash]
+	return block.Transactions[txIndex.Index].Vout[utxo.Index]
+}
+
+// Given a transaction input, lookup the pubkey of the transaction hash
+// that input references
+func (blockChain Blockchain) GetSenderPubKey(txi *pb.TXI) []byte {
+	txIndex := blockChain.TxIndex[string(txi.TxID)]
+	block := blockChain.Blocks[txIndex.BlockHash]
+	trans := block.Transactions[txIndex.Index]
+	txo := trans.Vout[txi.Index]
+	return txo.ReceiverPubKey
+}
+
+// Whether any transaction in the chain already spends the output txi references
+func (blockChain Blockchain) IsSpent(txi *pb.TXI) bool {
+	for _, block := range blockChain.GetMainChain() {
+		for _, transaction := range block.Transactions {
+			for _, input := range transaction.Vin {
+				if bytes.Equal(input.TxID, txi.TxID) && input.Index == txi.Index {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Unspent outputs locked by exactly this script
+func (blockChain Blockchain) GetScriptUTXOs(script []byte) []*UTXO {
+	var utxos []*UTXO
+	for _, block := range blockChain.GetMainChain() {
+		for _, transaction := range block.Transactions {
+			for i, outputTX := range transaction.Vout {
+				if !bytes.Equal(outputTX.LockingScript, script) {
+					continue
+				}
+				if !blockChain.IsSpent(&pb.TXI{TxID: GetTransactionHash(transaction), Index: uint64(i)}) {
+					utxos = append(utxos, &UTXO{Transaction: transaction, Index: i})
+				}
+			}
+		}
+	}
+	return utxos
+}
+
+func (blockChain Blockchain) GetUTXOs(key *ecdsa.PublicKey) []*UTXO {
+	sent := make([]*UTXO, 0)
+	received := make([]*UTXO, 0)
+	utxos := make([]*UTXO, 0)
+	// Make two lists --> inputs from our pubkey and outputs to our pubkey
+	// Then walk the outputs looking to see if that output transaction is referenced
+	// anywhere in an input, then the utxo was spent
+	for _, block := range blockChain.GetMainChain() {
+		for _, transaction := range block.Transactions {
+			for _, inputUTXO := range transaction.Vin {
+				// If the transaction hash and index in this vin references an output which has our pub key
+				// that means we spent that index
+				if bytes.Equal(blockChain.GetSenderPubKey(inputUTXO), GetPubKeyBytesFromPublicKey(key)) {
+					sent = append(sent, &UTXO{Transaction: blockChain.GetTransaction(inputUTXO.TxID),
+						Index: int(inputUTXO.Index)})
+				}
+			}
+			for i, outputTX := range transaction.Vout {
+				if bytes.Equal(outputTX.ReceiverPubKey, GetPubKeyBytesFromPublicKey(key)) {
+					received = append(received, &UTXO{Transaction: transaction,
+						Index: i})
+				}
+			}
+		}
+	}
+	spent := false
+	// Walk through all transactions which reference us as an output (all received)
+	// check if each one is spent, if not it is a UTXO
+	for _, candidateUTXO := range received {
+		spent = false
+		// Loop over the Vin's with our pubkey as the sender (spent)
+		// If the transaction hash of the candidateUTXO matches, we cannot use it
+		for _, spentTX := range sent {
+			if bytes.Equal(GetTransactionHash(spentTX.Transaction), GetTransactionHash(candidateUTXO.Transaction)) && spentTX.Index == candidateUTXO.Index {
+				spent = true
+			}
+		}
+		if !spent {
+			utxos = append(utxos, candidateUTXO)
+		}
+	}
+	return utxos
+}
+
+func GetBlockString(block *pb.Block) string {
+	var buf bytes.Buffer
+	buf.WriteString("\nBlock Hash: ")
+	buf.WriteString(hex.EncodeToString(GetBlockHash(block)))
+	buf.WriteString("\nBlock Header: ")
+	buf.WriteString("\n  prevBlockHash: ")
+	buf.WriteString(hex.EncodeToString(block.Header.PrevBlockHash[:]))
+	buf.WriteString("\n  timestamp: ")
+	buf.WriteString(time.Unix(int64(block.Header.TimeStamp), 0).String())
+	buf.WriteString("\n  nonce: ")
+	buf.WriteString(strconv.Itoa(int(block.Header.Nonce)))
+	buf.WriteString("\n  height: ")
+	buf.WriteString(strconv.Itoa(int(block.Header.Height)))
+	buf.WriteString("\nTransactions:\n\n")
+	for i := range block.Transactions {
+		buf.WriteString("\n")
+		buf.WriteString(GetTransactionString(block.Transactions[i]))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func (blockChain Blockchain) BlockIsValid(block *pb.Block) bool {
+	// Check whether the block is mined at the target due after its
+	// previous block and all transactions are valid
+	hash := GetBlockHash(block)
+	invalid := func(reason string, args ...interface{}) bool {
+		logging.Chain.Info("Invalid block", append([]interface{}{logging.Block(hash), "height", block.Header.Height, "reason", reason}, args...)...)
+		return false
+	}
+	// Without its parent the best we can do is what is due on our tip
+	target := blockChain.Target
+	if prev, ok := blockChain.Blocks[string(block.Header.PrevBlockHash)]; ok {
+		target = blockChain.GetNextTarget(prev)
+	}
+	if block.Header.DifficultyTarget != GetCompactTarget(target) {
+		return invalid("wrong difficulty target", "bits", fmt.Sprintf("%08x", block.Header.DifficultyTarget),
+			"target", hex.EncodeToString(target))
+	}
+	if !CheckHashMined(target, hash) {
+		return invalid("hash not mined", "target", hex.EncodeToString(target))
+	}
+	if len(block.Transactions) == 0 || !bytes.Equal(block.Header.MerkleRoot, GetMerkleRoot(block.Transactions)) {
+		return invalid("merkle root doesn't match the transactions")
+	}
+	if err := CheckBlockLimits(block); err != nil {
+		return invalid(err.Error())
+	}
+	if err := blockChain.CheckBlockTime(block); err != nil {
+		return invalid(err.Error())
+	}
+	medianTime := blockChain.GetPrevMedianTimePast(block)
+	var fees uint64
+	spent := make(map[string]bool)
+	for i, trans := range block.Transactions {
+		for _, txi := range trans.Vin {
+			// verifyTransaction only checks against the chain so far
+			outpoint := fmt.Sprintf("%x:%d", txi.TxID, txi.Index)
+			if spent[outpoint] {
+				return invalid("two transactions spend the same output", "outpoint", outpoint)
+			}
+			spent[outpoint] = true
+		}
+		if !blockChain.VerifyTransactionAt(trans, block.Header.Height, medianTime) {
+			return invalid("invalid transaction", logging.Tx(GetTransactionHash(trans)))
+		}
+		if i == 0 {
+			continue
+		}
+		if len(trans.Vin) == 0 {
+			return invalid("coinbase transaction isn't first")
+		}
+		fees += blockChain.GetTransactionFee(trans)
+	}
+	// The miner may claim the subsidy plus the fees of every transaction
+	if len(block.Transactions) > 0 && len(block.Transactions[0].Vin) == 0 &&
+		block.Transactions[0].Vout[0].Value > blockChain.GetBlockSubsidy(block.Header.Height)+fees {
+		return invalid("coinbase claims more than the subsidy plus fees")
+	}
+	return true
+}
+
+// Only the header is hashed, the merkle root covers the transactions
+func GetBlockHash(block *pb.Block) []byte {
+	return serialize.BlockHash(block)
+}
+
+// Note having the merkle root in the block header allows one to
+// hash only the block header and obtain a unique hash for that whole block
+// because any transaction change in the block will alter the merkle root
+func GetMerkleRoot(input []*pb.Transaction) []byte {
+	numTransactions := len(input)
+	if numTransactions == 1 {
+		return GetTransactionHash(input[0])
+	}
+	if numTransactions%2 != 0 {
+		// Odd number of transactions need to double the last input,
+		// could only happen on the first recursive call
+		input = append(input, input[numTransactions-1])
+		numTransactions += 1
+	}
+	m1 := GetMerkleRoot(input[:numTransactions/2])
+	m2 := GetMerkleRoot(input[numTransactions/2:])
+	buf := new(bytes.Buffer)
+	buf.Write(m1)
+	buf.Write(m2)
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:]
+}
+
+func CheckHashMined(target []byte, hash []byte) bool {
+	return bytes.Compare(hash, target) < 0
+}
@@ -0,0 +1,17 @@
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	test := make([]*pb.Transaction, 0)
+	for i := 0; i < 3; i++ {
+		var t1 pb.Transaction
+		test = append(test, &t1)
+	}
+	t.Log(test)
+	root := GetMerkleRoot(test)
+	t.Log(root)
+}
@@ -0,0 +1,113 @@
+// Difficulty adjustment. Like bitcoin the target only changes every
+// RetargetInterval blocks, scaled by how long those blocks took compared
+// to how long they should have, at most a factor of 4 either way.
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"math/big"
+)
+
+const MAX_RETARGET_FACTOR = 4
+
+// Targets are compared to 32 byte hashes as bytes, so a shorter target
+// is the same as padding it with zeros
+func targetToInt(target []byte) *big.Int {
+	padded := make([]byte, 32)
+	copy(padded, target)
+	return new(big.Int).SetBytes(padded)
+}
+
+func intToTarget(n *big.Int) []byte {
+	target := make([]byte, 32)
+	n.FillBytes(target)
+	return target
+}
+
+// Headers carry the target compactly like bitcoin's bits, how many bytes
+// the target is then its first 3 bytes. The rest are dropped, which can
+// only make it harder
+func GetCompactTarget(target []byte) uint32 {
+	n := targetToInt(target)
+	size := (n.BitLen() + 7) / 8
+	if size <= 3 {
+		n.Lsh(n, uint(8*(3-size)))
+	} else {
+		n.Rsh(n, uint(8*(size-3)))
+	}
+	return uint32(size)<<24 | uint32(n.Uint64())
+}
+
+// Nil if it doesn't fit in a hash, which nothing can be mined at
+func GetTargetFromCompact(compact uint32) []byte {
+	size := int(compact >> 24)
+	n := new(big.Int).SetUint64(uint64(compact & 0xffffff))
+	if size <= 3 {
+		n.Rsh(n, uint(8*(3-size)))
+	} else {
+		n.Lsh(n, uint(8*(size-3)))
+	}
+	if n.BitLen() > 256 {
+		return nil
+	}
+	return intToTarget(n)
+}
+
+// Target the block was mined at. Genesis isn't mined, the blocks after it
+// start at StartTarget
+func (blockChain Blockchain) GetBlockTarget(block *pb.Block) []byte {
+	if block.Header.Height <= 1 {
+		return GetTargetFromCompact(GetCompactTarget(blockChain.StartTarget))
+	}
+	return GetTargetFromCompact(block.Header.DifficultyTarget)
+}
+
+// Target due for the block after prev, which only moves once an interval finishes
+func (blockChain Blockchain) GetNextTarget(prev *pb.Block) []byte {
+	if blockChain.isRetargetHeight(prev.Header.Height) {
+		return GetTargetFromCompact(GetCompactTarget(blockChain.GetRetarget(prev)))
+	}
+	return blockChain.GetBlockTarget(prev)
+}
+
+// Walk back from block to the one at height, nil if it isn't in the chain
+func (blockChain Blockchain) GetAncestor(block *pb.Block, height uint64) *pb.Block {
+	for block != nil && block.Header.Height > height {
+		block = blockChain.GetPrevBlock(block)
+	}
+	return block
+}
+
+// Target for the blocks after last, which just finished an interval
+func (blockChain Blockchain) GetRetarget(last *pb.Block) []byte {
+	params := blockChain.Params
+	current := blockChain.GetBlockTarget(last)
+	first := blockChain.GetAncestor(last, last.Header.Height-params.RetargetInterval+1)
+	if first == nil || params.RetargetInterval < 2 {
+		return current
+	}
+	expected := (params.RetargetInterval - 1) * params.TargetSpacing
+	var actual uint64
+	if last.Header.TimeStamp > first.Header.TimeStamp {
+		actual = last.Header.TimeStamp - first.Header.TimeStamp
+	}
+	if actual < expected/MAX_RETARGET_FACTOR {
+		actual = expected / MAX_RETARGET_FACTOR
+	}
+	if actual > expected*MAX_RETARGET_FACTOR {
+		actual = expected * MAX_RETARGET_FACTOR
+	}
+	target := targetToInt(current)
+	target.Mul(target, new(big.Int).SetUint64(actual))
+	target.Div(target, new(big.Int).SetUint64(expected))
+	if limit := targetToInt(params.PowLimit); target.Cmp(limit) > 0 {
+		target = limit
+	}
+	return intToTarget(target)
+}
+
+// Whether the target changes after this block
+func (blockChain Blockchain) isRetargetHeight(height uint64) bool {
+	params := blockChain.Params
+	return params != nil && params.RetargetInterval != 0 && height%params.RetargetInterval == 0
+}
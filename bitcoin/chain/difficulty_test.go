@@ -0,0 +1,91 @@
+package chain
+
+import (
+	"bytes"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+// Chain on params with blocks spacing seconds apart, returning the target
+// after each block
+func buildChain(params *ChainParams, spacing []uint64) [][]byte {
+	chain := Blockchain{Blocks: make(map[string]*pb.Block), TxIndex: make(map[string]TxIndex), Params: params}
+	chain.SetTarget(params.PowLimit)
+	chain.AddGenesisBlock()
+	var targets [][]byte
+	for _, seconds := range spacing {
+		prev := chain.TipsOfChains[0]
+		block := &pb.Block{Header: &pb.BlockHeader{PrevBlockHash: GetBlockHash(prev),
+			Height: prev.Header.Height + 1, TimeStamp: prev.Header.TimeStamp + seconds,
+			DifficultyTarget: GetCompactTarget(chain.Target)}}
+		chain.AddBlock(block)
+		targets = append(targets, chain.Target)
+	}
+	return targets
+}
+
+func repeat(seconds uint64, n int) []uint64 {
+	var spacing []uint64
+	for i := 0; i < n; i++ {
+		spacing = append(spacing, seconds)
+	}
+	return spacing
+}
+
+func TestRetarget(t *testing.T) {
+	params := ChainParams{PowLimit: []byte{0x0f, 0xff}, RetargetInterval: 5, TargetSpacing: 10}
+	padded := intToTarget(targetToInt(params.PowLimit))
+	// Heights 2 to 4 keep the starting target, height 5 finishes the interval
+	targets := buildChain(&params, repeat(5, 3))
+	for _, target := range targets {
+		if !bytes.Equal(target, padded) {
+			t.Errorf("Target changed to %x before the interval ended", target)
+		}
+	}
+	// Twice as fast halves the target
+	targets = buildChain(&params, repeat(5, 4))
+	if want := []byte{0x07, 0xff, 0x80}; !bytes.Equal(targets[3][:3], want) {
+		t.Errorf("Target is %x should start with %x", targets[3], want)
+	}
+	// Much faster only moves by 4
+	targets = buildChain(&params, repeat(0, 4))
+	if want := []byte{0x03, 0xff, 0xc0}; !bytes.Equal(targets[3][:3], want) {
+		t.Errorf("Target is %x should start with %x", targets[3], want)
+	}
+	// Slower can't go past the limit
+	targets = buildChain(&params, repeat(100, 4))
+	if !bytes.Equal(targets[3], padded) {
+		t.Errorf("Target is %x should be the limit %x", targets[3], padded)
+	}
+	// Regtest never changes
+	targets = buildChain(&REGTEST, repeat(0, 20))
+	if !bytes.Equal(targets[19], GetTargetFromCompact(GetCompactTarget(REGTEST.PowLimit))) {
+		t.Errorf("Regtest target changed to %x", targets[19])
+	}
+}
+
+func TestCompactTarget(t *testing.T) {
+	cases := []struct {
+		target  []byte
+		compact uint32
+	}{
+		{[]byte{0x0f, 0xff}, 0x200fff00},
+		{[]byte{0x00, 0xff, 0xff, 0xff, 0xff}, 0x1fffffff},
+		{append(make([]byte, 30), 0x01, 0x02), 0x02010200},
+		{nil, 0},
+	}
+	for _, c := range cases {
+		compact := GetCompactTarget(c.target)
+		if compact != c.compact {
+			t.Errorf("Compact of %x is %08x should be %08x", c.target, compact, c.compact)
+		}
+		// Dropping the low bytes only makes it harder, and only once
+		target := GetTargetFromCompact(compact)
+		if bytes.Compare(target, intToTarget(targetToInt(c.target))) > 0 || GetCompactTarget(target) != compact {
+			t.Errorf("Target %x from %08x", target, compact)
+		}
+	}
+	if GetTargetFromCompact(0x21ffffff) != nil {
+		t.Error("Target longer than a hash should be nil")
+	}
+}
@@ -0,0 +1,31 @@
+// Fee estimates from approximate transaction sizes, used by the wallet
+// to pay fees and by the mempool to spot dust.
+package chain
+
+const (
+	// Approximate serialized sizes in bytes, used to estimate fees
+	TXI_SIZE         = 40 // txID + index
+	TXO_SIZE         = 72 // pubkey + value
+	TX_OVERHEAD_SIZE = 72 // signature + height
+)
+
+// Fee rates are in coin per 1000 bytes, always rounding up
+func FeeForSize(size uint64, feeRate uint64) uint64 {
+	return (size*feeRate + 999) / 1000
+}
+
+// Inputs are charged individually so that the fee of a transaction is
+// exactly the sum of the fees of its parts
+func TransactionFee(numInputs int, numOutputs int, feeRate uint64) uint64 {
+	return FeeForSize(TX_OVERHEAD_SIZE+uint64(numOutputs)*TXO_SIZE, feeRate) +
+		uint64(numInputs)*InputFee(feeRate)
+}
+
+func InputFee(feeRate uint64) uint64 {
+	return FeeForSize(TXI_SIZE, feeRate)
+}
+
+// An output is dust if it would cost at least as much to spend as it is worth
+func IsDust(value uint64, feeRate uint64) bool {
+	return value == 0 || value <= InputFee(feeRate)
+}
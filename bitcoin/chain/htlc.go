@@ -0,0 +1,61 @@
+// Hash time locked contracts for atomic swaps between two chains.
+// The initiator picks a secret and locks coin on one chain to the
+// participant under its hash, the participant locks coin on the other
+// chain to the initiator under the same hash with a shorter timeout.
+// Redeeming one reveals the secret which lets the other be redeemed,
+// if either side walks away both can refund after their timeout.
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+)
+
+const SWAP_SECRET_SIZE = 32
+
+// OP_IF OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <hash> OP_EQUALVERIFY <recipient>
+// OP_ELSE <lock time> OP_CHECKLOCKTIMEVERIFY OP_DROP <refunder>
+// OP_ENDIF OP_CHECKSIG
+// The size check stops a secret being valid on one chain but not the other.
+func HTLCScript(hash []byte, recipient []byte, refund []byte, lockTime uint64) []byte {
+	var b ScriptBuilder
+	b.AddOp(OP_IF).AddOp(OP_SIZE).AddInt(SWAP_SECRET_SIZE).AddOp(OP_EQUALVERIFY).
+		AddOp(OP_SHA256).AddData(hash).AddOp(OP_EQUALVERIFY).AddData(recipient)
+	b.AddOp(OP_ELSE).AddInt(int64(lockTime)).AddOp(OP_CHECKLOCKTIMEVERIFY).AddOp(OP_DROP).
+		AddData(refund)
+	return b.AddOp(OP_ENDIF).AddOp(OP_CHECKSIG).Script()
+}
+
+// Fills in the contract terms if the script is an HTLC
+func ParseHTLCScript(script []byte) (*pb.HTLC, bool) {
+	ops, err := ParseScript(script)
+	if err != nil || len(ops) != 15 {
+		return nil, false
+	}
+	lockTime, err := GetScriptOpInt(ops[9])
+	if err != nil || lockTime < 0 {
+		return nil, false
+	}
+	contract := &pb.HTLC{LockingScript: script, Hash: ops[5].Data, RecipientPubKey: ops[7].Data,
+		RefundPubKey: ops[12].Data, LockTime: uint64(lockTime)}
+	// Rebuilding it is the simplest way to check every opcode
+	if !bytes.Equal(HTLCScript(contract.Hash, contract.RecipientPubKey, contract.RefundPubKey, contract.LockTime), script) {
+		return nil, false
+	}
+	return contract, true
+}
+
+// A redeem's unlocking script is <sig> <secret> OP_1, a refund has no secret
+func ExtractPreimage(unlockingScript []byte, hash []byte) []byte {
+	ops, err := ParseScript(unlockingScript)
+	if err != nil {
+		return nil
+	}
+	for _, op := range ops {
+		if sum := sha256.Sum256(op.Data); op.Data != nil && bytes.Equal(sum[:], hash) {
+			return op.Data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Limits on what goes in a block. These are consensus rules, a block
+// breaking them is invalid. What the mempool will relay is policy and
+// lives in the mempool package.
+package chain
+
+import (
+	"errors"
+	"fmt"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/serialize"
+)
+
+const (
+	MAX_BLOCK_SIZE         = 1000000 // Serialized bytes
+	MAX_BLOCK_TRANSACTIONS = 10000
+	// Length prefix of each transaction in a block
+	BLOCK_TX_OVERHEAD = 4
+)
+
+func GetTransactionSize(transaction *pb.Transaction) uint64 {
+	return uint64(len(serialize.EncodeTransaction(transaction)))
+}
+
+func GetBlockSize(block *pb.Block) uint64 {
+	return uint64(len(serialize.EncodeBlock(block)))
+}
+
+func CheckBlockLimits(block *pb.Block) error {
+	if len(block.Transactions) > MAX_BLOCK_TRANSACTIONS {
+		return errors.New(fmt.Sprintf("Block has %d transactions, the limit is %d", len(block.Transactions), MAX_BLOCK_TRANSACTIONS))
+	}
+	if size := GetBlockSize(block); size > MAX_BLOCK_SIZE {
+		return errors.New(fmt.Sprintf("Block is %d bytes, the limit is %d", size, MAX_BLOCK_SIZE))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+func TestBlockLimits(t *testing.T) {
+	block := pb.Block{Header: &pb.BlockHeader{}}
+	for i := 0; i <= MAX_BLOCK_TRANSACTIONS; i++ {
+		block.Transactions = append(block.Transactions, &pb.Transaction{})
+	}
+	if err := CheckBlockLimits(&block); err == nil {
+		t.Error("Too many transactions should be invalid")
+	}
+	big := pb.Transaction{Vout: []*pb.TXO{&pb.TXO{LockingScript: make([]byte, MAX_BLOCK_SIZE)}}}
+	block.Transactions = []*pb.Transaction{&big}
+	if err := CheckBlockLimits(&block); err == nil {
+		t.Error("Block over the size limit should be invalid")
+	}
+	block.Transactions = []*pb.Transaction{&pb.Transaction{}}
+	if err := CheckBlockLimits(&block); err != nil {
+		t.Error(err)
+	}
+}
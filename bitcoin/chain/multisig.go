@@ -0,0 +1,34 @@
+// M of N multisig addresses. Coin sent to one is locked by a bare
+// OP_CHECKMULTISIG script, spending it goes through the partially signed
+// transaction flow: create on any node watching the address, sign on the
+// nodes holding the keys, combine and finalize once M have signed.
+package chain
+
+import ()
+
+func ParseMultisigScript(script []byte) (int, [][]byte, bool) {
+	ops, err := ParseScript(script)
+	if err != nil || len(ops) < 4 || ops[len(ops)-1].Opcode != OP_CHECKMULTISIG {
+		return 0, nil, false
+	}
+	isSmallInt := func(op ScriptOp) bool {
+		return op.Opcode >= OP_1 && op.Opcode <= OP_16
+	}
+	first, last := ops[0], ops[len(ops)-2]
+	if !isSmallInt(first) || !isSmallInt(last) {
+		return 0, nil, false
+	}
+	m := int(first.Opcode) - (OP_1 - 1)
+	n := int(last.Opcode) - (OP_1 - 1)
+	if n != len(ops)-3 || m > n {
+		return 0, nil, false
+	}
+	var pubKeys [][]byte
+	for _, op := range ops[1 : len(ops)-2] {
+		if op.Data == nil {
+			return 0, nil, false
+		}
+		pubKeys = append(pubKeys, op.Data)
+	}
+	return m, pubKeys, true
+}
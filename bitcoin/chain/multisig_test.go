@@ -0,0 +1,23 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestParseMultisigScript(t *testing.T) {
+	var pubKeys [][]byte
+	for i := 0; i < 3; i++ {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		pubKeys = append(pubKeys, GetPubKeyBytes(key))
+	}
+	m, parsed, ok := ParseMultisigScript(MultisigScript(2, pubKeys))
+	if !ok || m != 2 || len(parsed) != 3 {
+		t.Errorf("Parsed %v %d of %d", ok, m, len(parsed))
+	}
+	if _, _, ok = ParseMultisigScript(PayToPubKeyHashScript(pubKeys[0])); ok {
+		t.Error("Pay to pubkey hash is not multisig")
+	}
+}
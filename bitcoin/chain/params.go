@@ -0,0 +1,168 @@
+// Parameters of each network. Nodes only peer with nodes sharing their
+// genesis block, so coin on one network can't be spent on another and
+// addresses carry a prefix saying which network they are for.
+package chain
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+type ChainParams struct {
+	Name          string
+	Port          string // Default port to listen on and dial peers at
+	AddressPrefix string
+	GenesisTime   uint64 // Different for each network so each has its own genesis hash
+	// Easiest target allowed, also the target of the first blocks
+	PowLimit []byte
+	// Every RetargetInterval blocks the target moves so blocks come
+	// TargetSpacing seconds apart, 0 keeps the target fixed
+	RetargetInterval uint64
+	TargetSpacing    uint64
+	BlockReward      uint64
+	HalvingInterval  uint64
+	CoinbaseMaturity uint64
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var MAINNET = ChainParams{
+	Name:             "mainnet",
+	Port:             "8333",
+	GenesisTime:      1514764800,
+	PowLimit:         mustDecodeHex("00ffffffffffffffffff"),
+	RetargetInterval: 2016,
+	TargetSpacing:    10,
+	BlockReward:      BLOCK_REWARD,
+	HalvingInterval:  HALVING_INTERVAL,
+	CoinbaseMaturity: COINBASE_MATURITY,
+}
+
+// Same rules as mainnet but easier to mine
+var TESTNET = ChainParams{
+	Name:             "testnet",
+	Port:             "18333",
+	AddressPrefix:    "t",
+	GenesisTime:      1517443200,
+	PowLimit:         mustDecodeHex("0fffffffffffffffffff"),
+	RetargetInterval: 2016,
+	TargetSpacing:    10,
+	BlockReward:      BLOCK_REWARD,
+	HalvingInterval:  HALVING_INTERVAL,
+	CoinbaseMaturity: COINBASE_MATURITY,
+}
+
+// For tests, half of all hashes are below the target so blocks can be
+// made instantly whenever they are wanted
+var REGTEST = ChainParams{
+	Name:             "regtest",
+	Port:             "18444",
+	AddressPrefix:    "r",
+	GenesisTime:      1519862400,
+	PowLimit:         mustDecodeHex("7fffffffffffffffffff"),
+	BlockReward:      BLOCK_REWARD,
+	HalvingInterval:  150,
+	CoinbaseMaturity: COINBASE_MATURITY,
+}
+
+var NETWORKS = map[string]*ChainParams{
+	MAINNET.Name: &MAINNET,
+	TESTNET.Name: &TESTNET,
+	REGTEST.Name: &REGTEST,
+}
+
+// A copy, so it can be changed without affecting other users of the network
+func GetChainParams(name string) (*ChainParams, error) {
+	params, ok := NETWORKS[name]
+	if !ok {
+		var names []string
+		for name := range NETWORKS {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, errors.New(fmt.Sprintf("Unknown network %q, need one of %v", name, names))
+	}
+	copied := *params
+	return &copied, nil
+}
+
+// Block # 1, with no transactions so no coin
+func (params *ChainParams) GenesisBlock() *pb.Block {
+	return &pb.Block{Header: &pb.BlockHeader{
+		Height:        1,
+		PrevBlockHash: make([]byte, 32),
+		MerkleRoot:    make([]byte, 32),
+		TimeStamp:     params.GenesisTime}}
+}
+
+func (params *ChainParams) GenesisHash() []byte {
+	return GetBlockHash(params.GenesisBlock())
+}
+
+// Addresses are the prefix then the two 32 byte integers of the pubkey
+// in decimal
+func (params *ChainParams) GetAddress(pubKey []byte) string {
+	x := new(big.Int).SetBytes(pubKey[:32])
+	y := new(big.Int).SetBytes(pubKey[32:])
+	return strings.Join([]string{params.AddressPrefix, x.String(), y.String()}, "")
+}
+
+// Scripts, e.g. multisig, have no key to make an address from so they go
+// by the hex of the script. The prefix keeps them apart from key addresses,
+// whose digits are valid hex too
+const SCRIPT_ADDRESS_PREFIX = "script:"
+
+func GetScriptAddress(script []byte) string {
+	return SCRIPT_ADDRESS_PREFIX + hex.EncodeToString(script)
+}
+
+func IsScriptAddress(address string) bool {
+	return strings.HasPrefix(address, SCRIPT_ADDRESS_PREFIX)
+}
+
+func GetScriptFromAddress(address string) ([]byte, error) {
+	script, err := hex.DecodeString(strings.TrimPrefix(address, SCRIPT_ADDRESS_PREFIX))
+	if !IsScriptAddress(address) || err != nil || len(script) == 0 {
+		return nil, errors.New(fmt.Sprintf("Script address %s needs %s then the script in hex", address, SCRIPT_ADDRESS_PREFIX))
+	}
+	return script, nil
+}
+
+// The coordinates aren't padded so try each place X could end, only one
+// gives a point on the curve. 77 digits is the most common
+func (params *ChainParams) GetPubKeyFromAddress(address string) ([]byte, error) {
+	if !strings.HasPrefix(address, params.AddressPrefix) {
+		return nil, errors.New(fmt.Sprintf("Address %s is not for %s", address, params.Name))
+	}
+	digits := strings.TrimPrefix(address, params.AddressPrefix)
+	splits := []int{77}
+	for i := 1; i < len(digits) && i <= 78; i++ {
+		if i != 77 {
+			splits = append(splits, i)
+		}
+	}
+	for _, split := range splits {
+		if split >= len(digits) {
+			continue
+		}
+		x, okX := new(big.Int).SetString(digits[:split], 10)
+		y, okY := new(big.Int).SetString(digits[split:], 10)
+		if okX && okY && elliptic.P256().IsOnCurve(x, y) {
+			return GetPubKeyBytesFromPublicKey(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}), nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("Address %s is not for %s", address, params.Name))
+}
@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestNetworks(t *testing.T) {
+	hashes := make(map[string]string)
+	for name, params := range NETWORKS {
+		if other, ok := hashes[string(params.GenesisHash())]; ok {
+			t.Errorf("%s and %s have the same genesis block", name, other)
+		}
+		hashes[string(params.GenesisHash())] = name
+	}
+	params, err := GetChainParams("regtest")
+	if err != nil || params.Name != "regtest" {
+		t.Fatalf("Got %v %v for regtest", params, err)
+	}
+	// Changing a copy doesn't change the network
+	params.BlockReward = 1
+	if REGTEST.BlockReward == 1 {
+		t.Error("GetChainParams should return a copy")
+	}
+	if _, err = GetChainParams("simnet"); err == nil {
+		t.Error("Unknown network should be an error")
+	}
+}
+
+// A Y for x, which is only on the curve if x is
+func recoverY(x *big.Int) *big.Int {
+	// y^2 = x^3 - 3x + b
+	curve := elliptic.P256().Params()
+	y2 := new(big.Int).Exp(x, big.NewInt(3), curve.P)
+	y2.Sub(y2, new(big.Int).Mul(x, big.NewInt(3)))
+	y2.Add(y2, curve.B)
+	y2.Mod(y2, curve.P)
+	y := new(big.Int).ModSqrt(y2, curve.P)
+	if y == nil {
+		return big.NewInt(0)
+	}
+	return y
+}
+
+func TestAddresses(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pubKey := GetPubKeyBytes(key)
+	address := TESTNET.GetAddress(pubKey)
+	if address[0] != 't' {
+		t.Errorf("Testnet address %s should start with t", address)
+	}
+	decoded, err := TESTNET.GetPubKeyFromAddress(address)
+	if err != nil || !bytes.Equal(decoded, pubKey) {
+		t.Errorf("Address round trip gave %x %v", decoded, err)
+	}
+	// X doesn't have to be 77 digits
+	for _, digits := range []string{"9" + strings.Repeat("0", 75), "11" + strings.Repeat("0", 76)} {
+		x, _ := new(big.Int).SetString(digits, 10)
+		for !elliptic.P256().IsOnCurve(x, recoverY(x)) {
+			x.Add(x, big.NewInt(1))
+		}
+		short := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: recoverY(x)}
+		decoded, err = TESTNET.GetPubKeyFromAddress(TESTNET.GetAddress(GetPubKeyBytesFromPublicKey(short)))
+		if err != nil || !bytes.Equal(decoded, GetPubKeyBytesFromPublicKey(short)) {
+			t.Errorf("Address with a %d digit X gave %x %v", len(x.String()), decoded, err)
+		}
+	}
+	for _, params := range []*ChainParams{&MAINNET, &REGTEST} {
+		if _, err = params.GetPubKeyFromAddress(address); err == nil {
+			t.Errorf("Testnet address should not work on %s", params.Name)
+		}
+	}
+}
+
+func TestScriptAddresses(t *testing.T) {
+	script := DataScript([]byte("memo"))
+	address := GetScriptAddress(script)
+	decoded, err := GetScriptFromAddress(address)
+	if !IsScriptAddress(address) || err != nil || !bytes.Equal(decoded, script) {
+		t.Errorf("Script address %s gave %x %v", address, decoded, err)
+	}
+	// Mainnet addresses are all digits, which is valid hex
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	for _, address := range []string{MAINNET.GetAddress(GetPubKeyBytes(key)), SCRIPT_ADDRESS_PREFIX, SCRIPT_ADDRESS_PREFIX + "zz"} {
+		if _, err = GetScriptFromAddress(address); err == nil {
+			t.Errorf("%s should not be a script address", address)
+		}
+	}
+}
@@ -0,0 +1,680 @@
+// A small subset of bitcoin script. Outputs can be locked by a script
+// instead of a receiver pubkey, whoever spends them provides an unlocking
+// script which is run first, then the locking script runs on the same
+// stack and the spend is valid if it finishes with a true value on top.
+// Opcode values match bitcoin's so scripts look familiar in hex.
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	OP_0                   = 0x00
+	OP_PUSHDATA1           = 0x4c
+	OP_PUSHDATA2           = 0x4d
+	OP_1NEGATE             = 0x4f
+	OP_1                   = 0x51
+	OP_16                  = 0x60
+	OP_NOP                 = 0x61
+	OP_IF                  = 0x63
+	OP_NOTIF               = 0x64
+	OP_ELSE                = 0x67
+	OP_ENDIF               = 0x68
+	OP_VERIFY              = 0x69
+	OP_RETURN              = 0x6a
+	OP_DROP                = 0x75
+	OP_DUP                 = 0x76
+	OP_SWAP                = 0x7c
+	OP_SIZE                = 0x82
+	OP_EQUAL               = 0x87
+	OP_EQUALVERIFY         = 0x88
+	OP_SHA256              = 0xa8
+	OP_HASH160             = 0xa9
+	OP_CHECKSIG            = 0xac
+	OP_CHECKSIGVERIFY      = 0xad
+	OP_CHECKMULTISIG       = 0xae
+	OP_CHECKMULTISIGVERIFY = 0xaf
+	OP_CHECKLOCKTIMEVERIFY = 0xb1
+	OP_CHECKSEQUENCEVERIFY = 0xb2
+
+	MAX_SCRIPT_SIZE      = 10000
+	MAX_SCRIPT_ELEMENT   = 520
+	MAX_STACK_SIZE       = 1000
+	MAX_SCRIPT_OPS       = 201
+	MAX_MULTISIG_PUBKEYS = 20
+	// Lock times below this are block heights, above are unix timestamps (seconds)
+	LOCKTIME_THRESHOLD = 500000000
+)
+
+var opcodeNames = map[byte]string{
+	OP_0:                   "OP_0",
+	OP_1NEGATE:             "OP_1NEGATE",
+	OP_NOP:                 "OP_NOP",
+	OP_IF:                  "OP_IF",
+	OP_NOTIF:               "OP_NOTIF",
+	OP_ELSE:                "OP_ELSE",
+	OP_ENDIF:               "OP_ENDIF",
+	OP_VERIFY:              "OP_VERIFY",
+	OP_RETURN:              "OP_RETURN",
+	OP_DROP:                "OP_DROP",
+	OP_DUP:                 "OP_DUP",
+	OP_SWAP:                "OP_SWAP",
+	OP_SIZE:                "OP_SIZE",
+	OP_EQUAL:               "OP_EQUAL",
+	OP_EQUALVERIFY:         "OP_EQUALVERIFY",
+	OP_SHA256:              "OP_SHA256",
+	OP_HASH160:             "OP_HASH160",
+	OP_CHECKSIG:            "OP_CHECKSIG",
+	OP_CHECKSIGVERIFY:      "OP_CHECKSIGVERIFY",
+	OP_CHECKMULTISIG:       "OP_CHECKMULTISIG",
+	OP_CHECKMULTISIGVERIFY: "OP_CHECKMULTISIGVERIFY",
+	OP_CHECKLOCKTIMEVERIFY: "OP_CHECKLOCKTIMEVERIFY",
+	OP_CHECKSEQUENCEVERIFY: "OP_CHECKSEQUENCEVERIFY",
+}
+
+// What a script can see about the transaction spending the output.
+// Like bitcoin, time lock opcodes only compare against the transaction's
+// own lock time and the input's sequence, which block validation enforces,
+// so a script's result never depends on when it is run.
+type scriptContext struct {
+	// Hash every signature in the transaction signs
+	hash     []byte
+	lockTime uint64
+	// Relative lock of the input being checked
+	sequence uint64
+}
+
+// One parsed instruction, data is set for pushes
+type ScriptOp struct {
+	Opcode byte
+	Data   []byte
+}
+
+// Bitcoin uses RIPEMD160(SHA256(x)) but ripemd isn't in the standard
+// library, so we take the first 20 bytes of a double SHA256 instead
+func Hash160(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:20]
+}
+
+func ParseScript(script []byte) ([]ScriptOp, error) {
+	if len(script) > MAX_SCRIPT_SIZE {
+		return nil, errors.New("Script too large")
+	}
+	var ops []ScriptOp
+	for i := 0; i < len(script); {
+		opcode := script[i]
+		i++
+		var size int
+		switch {
+		case opcode > OP_0 && opcode < OP_PUSHDATA1:
+			size = int(opcode)
+		case opcode == OP_PUSHDATA1:
+			if i+1 > len(script) {
+				return nil, errors.New("Truncated OP_PUSHDATA1")
+			}
+			size = int(script[i])
+			i++
+		case opcode == OP_PUSHDATA2:
+			if i+2 > len(script) {
+				return nil, errors.New("Truncated OP_PUSHDATA2")
+			}
+			size = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		default:
+			ops = append(ops, ScriptOp{Opcode: opcode})
+			continue
+		}
+		if i+size > len(script) {
+			return nil, errors.New("Push past the end of the script")
+		}
+		ops = append(ops, ScriptOp{Opcode: opcode, Data: script[i : i+size]})
+		i += size
+	}
+	return ops, nil
+}
+
+// Helps build scripts using the smallest push for each piece of data
+type ScriptBuilder struct {
+	buf bytes.Buffer
+}
+
+func (b *ScriptBuilder) AddOp(opcode byte) *ScriptBuilder {
+	b.buf.WriteByte(opcode)
+	return b
+}
+
+func (b *ScriptBuilder) AddData(data []byte) *ScriptBuilder {
+	switch {
+	case len(data) < OP_PUSHDATA1:
+		b.buf.WriteByte(byte(len(data)))
+	case len(data) <= 0xff:
+		b.buf.WriteByte(OP_PUSHDATA1)
+		b.buf.WriteByte(byte(len(data)))
+	default:
+		b.buf.WriteByte(OP_PUSHDATA2)
+		binary.Write(&b.buf, binary.LittleEndian, uint16(len(data)))
+	}
+	b.buf.Write(data)
+	return b
+}
+
+func (b *ScriptBuilder) AddInt(n int64) *ScriptBuilder {
+	if n == 0 {
+		return b.AddOp(OP_0)
+	}
+	if n == -1 || (n >= 1 && n <= 16) {
+		return b.AddOp(byte(OP_1 - 1 + n))
+	}
+	return b.AddData(encodeScriptNum(n))
+}
+
+func (b *ScriptBuilder) Script() []byte {
+	return b.buf.Bytes()
+}
+
+// Numbers on the stack are little endian with the sign in the top bit
+func encodeScriptNum(n int64) []byte {
+	if n == 0 {
+		return nil
+	}
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+	var result []byte
+	for n > 0 {
+		result = append(result, byte(n&0xff))
+		n >>= 8
+	}
+	if result[len(result)-1]&0x80 != 0 {
+		if negative {
+			result = append(result, 0x80)
+		} else {
+			result = append(result, 0)
+		}
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+	return result
+}
+
+func decodeScriptNum(data []byte, maxSize int) (int64, error) {
+	if len(data) > maxSize {
+		return 0, errors.New("Script number too large")
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	var n int64
+	for i, b := range data {
+		n |= int64(b) << uint(8*i)
+	}
+	if data[len(data)-1]&0x80 != 0 {
+		// Clear the sign bit and negate
+		n &^= int64(0x80) << uint(8*(len(data)-1))
+		n = -n
+	}
+	return n, nil
+}
+
+// Small numbers are pushed with their own opcodes
+func GetScriptOpInt(op ScriptOp) (int64, error) {
+	if op.Opcode == OP_1NEGATE || (op.Opcode >= OP_1 && op.Opcode <= OP_16) {
+		return int64(op.Opcode) - (OP_1 - 1), nil
+	}
+	return decodeScriptNum(op.Data, 5)
+}
+
+// Anything other than zero or negative zero is true
+func castToBool(data []byte) bool {
+	for i, b := range data {
+		if b != 0 {
+			return !(i == len(data)-1 && b == 0x80)
+		}
+	}
+	return false
+}
+
+type scriptStack [][]byte
+
+func (s *scriptStack) push(data []byte) {
+	*s = append(*s, data)
+}
+
+func (s *scriptStack) pop() ([]byte, error) {
+	if len(*s) == 0 {
+		return nil, errors.New("Stack empty")
+	}
+	top := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return top, nil
+}
+
+func (s *scriptStack) peek() ([]byte, error) {
+	if len(*s) == 0 {
+		return nil, errors.New("Stack empty")
+	}
+	return (*s)[len(*s)-1], nil
+}
+
+func (s *scriptStack) popInt(maxSize int) (int64, error) {
+	data, err := s.pop()
+	if err != nil {
+		return 0, err
+	}
+	return decodeScriptNum(data, maxSize)
+}
+
+func boolBytes(value bool) []byte {
+	if value {
+		return []byte{1}
+	}
+	return nil
+}
+
+// Runs a script on the stack, returns an error as soon as it fails
+func evalScript(script []byte, stack *scriptStack, ctx *scriptContext) error {
+	ops, err := ParseScript(script)
+	if err != nil {
+		return err
+	}
+	// Whether each enclosing OP_IF branch is being executed
+	var conditions []bool
+	numOps := 0
+	for _, op := range ops {
+		executing := true
+		for _, condition := range conditions {
+			executing = executing && condition
+		}
+		if op.Opcode > OP_16 {
+			numOps++
+			if numOps > MAX_SCRIPT_OPS {
+				return errors.New("Too many script operations")
+			}
+		}
+		if len(op.Data) > MAX_SCRIPT_ELEMENT {
+			return errors.New("Push larger than the maximum element size")
+		}
+		// Branching has to be tracked even in branches we skip
+		switch op.Opcode {
+		case OP_IF, OP_NOTIF:
+			branch := false
+			if executing {
+				top, err := stack.pop()
+				if err != nil {
+					return err
+				}
+				branch = castToBool(top) == (op.Opcode == OP_IF)
+			}
+			conditions = append(conditions, branch)
+			continue
+		case OP_ELSE:
+			if len(conditions) == 0 {
+				return errors.New("OP_ELSE without OP_IF")
+			}
+			conditions[len(conditions)-1] = !conditions[len(conditions)-1]
+			continue
+		case OP_ENDIF:
+			if len(conditions) == 0 {
+				return errors.New("OP_ENDIF without OP_IF")
+			}
+			conditions = conditions[:len(conditions)-1]
+			continue
+		}
+		if !executing {
+			continue
+		}
+		if err := execOp(op, stack, ctx); err != nil {
+			return err
+		}
+		if len(*stack) > MAX_STACK_SIZE {
+			return errors.New("Stack too large")
+		}
+	}
+	if len(conditions) != 0 {
+		return errors.New("OP_IF without OP_ENDIF")
+	}
+	return nil
+}
+
+func execOp(op ScriptOp, stack *scriptStack, ctx *scriptContext) error {
+	switch {
+	case op.Opcode == OP_0:
+		stack.push(nil)
+		return nil
+	case op.Opcode < OP_PUSHDATA1 || op.Opcode == OP_PUSHDATA1 || op.Opcode == OP_PUSHDATA2:
+		stack.push(op.Data)
+		return nil
+	case op.Opcode == OP_1NEGATE || (op.Opcode >= OP_1 && op.Opcode <= OP_16):
+		stack.push(encodeScriptNum(int64(op.Opcode) - (OP_1 - 1)))
+		return nil
+	}
+	switch op.Opcode {
+	case OP_NOP:
+	case OP_VERIFY:
+		return verifyTop(stack, "OP_VERIFY")
+	case OP_RETURN:
+		return errors.New("OP_RETURN output is unspendable")
+	case OP_DROP:
+		_, err := stack.pop()
+		return err
+	case OP_DUP:
+		top, err := stack.peek()
+		if err != nil {
+			return err
+		}
+		stack.push(top)
+	case OP_SWAP:
+		a, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		b, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		stack.push(a)
+		stack.push(b)
+	case OP_SIZE:
+		top, err := stack.peek()
+		if err != nil {
+			return err
+		}
+		stack.push(encodeScriptNum(int64(len(top))))
+	case OP_EQUAL, OP_EQUALVERIFY:
+		a, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		b, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		stack.push(boolBytes(bytes.Equal(a, b)))
+		if op.Opcode == OP_EQUALVERIFY {
+			return verifyTop(stack, "OP_EQUALVERIFY")
+		}
+	case OP_SHA256:
+		top, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(top)
+		stack.push(sum[:])
+	case OP_HASH160:
+		top, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		stack.push(Hash160(top))
+	case OP_CHECKSIG, OP_CHECKSIGVERIFY:
+		pubKey, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		signature, err := stack.pop()
+		if err != nil {
+			return err
+		}
+		stack.push(boolBytes(VerifySignature(pubKey, ctx.hash, signature)))
+		if op.Opcode == OP_CHECKSIGVERIFY {
+			return verifyTop(stack, "OP_CHECKSIGVERIFY")
+		}
+	case OP_CHECKMULTISIG, OP_CHECKMULTISIGVERIFY:
+		valid, err := checkMultisig(stack, ctx)
+		if err != nil {
+			return err
+		}
+		stack.push(boolBytes(valid))
+		if op.Opcode == OP_CHECKMULTISIGVERIFY {
+			return verifyTop(stack, "OP_CHECKMULTISIGVERIFY")
+		}
+	case OP_CHECKLOCKTIMEVERIFY:
+		// Leaves the lock time on the stack like bitcoin, scripts follow it with OP_DROP
+		top, err := stack.peek()
+		if err != nil {
+			return err
+		}
+		lockTime, err := decodeScriptNum(top, 5)
+		if err != nil {
+			return err
+		}
+		return checkLockTime(lockTime, ctx)
+	case OP_CHECKSEQUENCEVERIFY:
+		top, err := stack.peek()
+		if err != nil {
+			return err
+		}
+		sequence, err := decodeScriptNum(top, 5)
+		if err != nil {
+			return err
+		}
+		if sequence < 0 || uint64(sequence) > ctx.sequence {
+			return errors.New(fmt.Sprintf("Input must wait %d blocks, sequence is %d", sequence, ctx.sequence))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Unknown opcode 0x%x", op.Opcode))
+	}
+	return nil
+}
+
+func verifyTop(stack *scriptStack, name string) error {
+	top, err := stack.pop()
+	if err != nil {
+		return err
+	}
+	if !castToBool(top) {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+// The transaction's lock time has to be at least the script's,
+// heights and times can't be compared with each other
+func checkLockTime(lockTime int64, ctx *scriptContext) error {
+	if lockTime < 0 {
+		return errors.New("Negative lock time")
+	}
+	if (lockTime < LOCKTIME_THRESHOLD) != (ctx.lockTime < LOCKTIME_THRESHOLD) {
+		return errors.New("Lock time is a height and the other a time")
+	}
+	if uint64(lockTime) > ctx.lockTime {
+		return errors.New(fmt.Sprintf("Locked until %d, transaction lock time is %d", lockTime, ctx.lockTime))
+	}
+	return nil
+}
+
+// Stack is <sig 1> ... <sig m> <m> <pubkey 1> ... <pubkey n> <n>.
+// Signatures have to be in the same order as their pubkeys. Unlike
+// bitcoin there is no extra dummy element to pop.
+func checkMultisig(stack *scriptStack, ctx *scriptContext) (bool, error) {
+	n, err := stack.popInt(4)
+	if err != nil {
+		return false, err
+	}
+	if n < 0 || n > MAX_MULTISIG_PUBKEYS {
+		return false, errors.New("Invalid number of multisig pubkeys")
+	}
+	pubKeys := make([][]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		if pubKeys[i], err = stack.pop(); err != nil {
+			return false, err
+		}
+	}
+	m, err := stack.popInt(4)
+	if err != nil {
+		return false, err
+	}
+	if m < 0 || m > n {
+		return false, errors.New("Invalid number of multisig signatures")
+	}
+	signatures := make([][]byte, m)
+	for i := m - 1; i >= 0; i-- {
+		if signatures[i], err = stack.pop(); err != nil {
+			return false, err
+		}
+	}
+	// Each pubkey can match at most one signature, walking both in order
+	key := 0
+	for _, signature := range signatures {
+		for key < len(pubKeys) && !VerifySignature(pubKeys[key], ctx.hash, signature) {
+			key++
+		}
+		if key == len(pubKeys) {
+			return false, nil
+		}
+		key++
+	}
+	return true, nil
+}
+
+// Unlocking scripts may only push data, otherwise they could
+// change what the locking script does
+func isPushOnly(script []byte) bool {
+	ops, err := ParseScript(script)
+	if err != nil {
+		return false
+	}
+	for _, op := range ops {
+		if op.Opcode > OP_16 {
+			return false
+		}
+	}
+	return true
+}
+
+func verifyScript(unlockingScript []byte, lockingScript []byte, ctx *scriptContext) error {
+	if !isPushOnly(unlockingScript) {
+		return errors.New("Unlocking script must only push data")
+	}
+	var stack scriptStack
+	if err := evalScript(unlockingScript, &stack, ctx); err != nil {
+		return err
+	}
+	if err := evalScript(lockingScript, &stack, ctx); err != nil {
+		return err
+	}
+	top, err := stack.peek()
+	if err != nil {
+		return err
+	}
+	if !castToBool(top) {
+		return errors.New("Script finished with false on the stack")
+	}
+	return nil
+}
+
+// Standard scripts
+
+func PayToPubKeyHashScript(pubKey []byte) []byte {
+	var b ScriptBuilder
+	return b.AddOp(OP_DUP).AddOp(OP_HASH160).AddData(Hash160(pubKey)).
+		AddOp(OP_EQUALVERIFY).AddOp(OP_CHECKSIG).Script()
+}
+
+func PayToPubKeyHashUnlockingScript(signature []byte, pubKey []byte) []byte {
+	var b ScriptBuilder
+	return b.AddData(signature).AddData(pubKey).Script()
+}
+
+func MultisigScript(m int, pubKeys [][]byte) []byte {
+	var b ScriptBuilder
+	b.AddInt(int64(m))
+	for _, pubKey := range pubKeys {
+		b.AddData(pubKey)
+	}
+	return b.AddInt(int64(len(pubKeys))).AddOp(OP_CHECKMULTISIG).Script()
+}
+
+// Provably unspendable, carries data in the chain
+func DataScript(data []byte) []byte {
+	var b ScriptBuilder
+	return b.AddOp(OP_RETURN).AddData(data).Script()
+}
+
+// Spendable by pubKey once the output has waited blocks confirmations
+func RelativeLockScript(blocks int64, pubKey []byte) []byte {
+	var b ScriptBuilder
+	return b.AddInt(blocks).AddOp(OP_CHECKSEQUENCEVERIFY).AddOp(OP_DROP).
+		AddData(pubKey).AddOp(OP_CHECKSIG).Script()
+}
+
+func IsDataScript(script []byte) bool {
+	return len(script) > 0 && script[0] == OP_RETURN
+}
+
+// Spendable by pubKey revealing the preimage of hash
+func HashLockScript(hash []byte, pubKey []byte) []byte {
+	var b ScriptBuilder
+	return b.AddOp(OP_SHA256).AddData(hash).AddOp(OP_EQUALVERIFY).
+		AddData(pubKey).AddOp(OP_CHECKSIG).Script()
+}
+
+// Spendable by pubKey with a transaction whose lock time is at least
+// lockTime (height or unix time)
+func TimeLockScript(lockTime int64, pubKey []byte) []byte {
+	var b ScriptBuilder
+	return b.AddInt(lockTime).AddOp(OP_CHECKLOCKTIMEVERIFY).AddOp(OP_DROP).
+		AddData(pubKey).AddOp(OP_CHECKSIG).Script()
+}
+
+// Human readable form e.g. OP_DUP OP_HASH160 <hex> OP_EQUALVERIFY OP_CHECKSIG
+func GetScriptString(script []byte) string {
+	ops, err := ParseScript(script)
+	if err != nil {
+		return "invalid script " + hex.EncodeToString(script)
+	}
+	var parts []string
+	for _, op := range ops {
+		switch {
+		case op.Data != nil || (op.Opcode > OP_0 && op.Opcode <= OP_PUSHDATA2):
+			parts = append(parts, hex.EncodeToString(op.Data))
+		case op.Opcode >= OP_1 && op.Opcode <= OP_16:
+			parts = append(parts, "OP_"+strconv.Itoa(int(op.Opcode)-(OP_1-1)))
+		case opcodeNames[op.Opcode] != "":
+			parts = append(parts, opcodeNames[op.Opcode])
+		default:
+			parts = append(parts, fmt.Sprintf("0x%x", op.Opcode))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Inverse of getScriptString, anything which isn't an opcode is hex data
+func CompileScript(asm string) ([]byte, error) {
+	names := make(map[string]byte)
+	for opcode, name := range opcodeNames {
+		names[name] = opcode
+	}
+	var b ScriptBuilder
+	for _, token := range strings.Fields(asm) {
+		if opcode, ok := names[token]; ok {
+			b.AddOp(opcode)
+			continue
+		}
+		if strings.HasPrefix(token, "OP_") {
+			n, err := strconv.Atoi(token[3:])
+			if err != nil || n < 1 || n > 16 {
+				return nil, errors.New("Unknown opcode " + token)
+			}
+			b.AddInt(int64(n))
+			continue
+		}
+		data, err := hex.DecodeString(token)
+		if err != nil {
+			return nil, errors.New("Expected an opcode or hex data, got " + token)
+		}
+		b.AddData(data)
+	}
+	return b.Script(), nil
+}
@@ -0,0 +1,147 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func signHash(key *ecdsa.PrivateKey, hash []byte) []byte {
+	r, s, _ := ecdsa.Sign(rand.Reader, key, hash)
+	return GetSignatureBytes(r, s)
+}
+
+func TestScriptNum(t *testing.T) {
+	for _, n := range []int64{0, 1, -1, 127, 128, -128, 255, 256, 500000000, -70000} {
+		decoded, err := decodeScriptNum(encodeScriptNum(n), 5)
+		if err != nil || decoded != n {
+			t.Errorf("Encoding %d decoded to %d %v", n, decoded, err)
+		}
+	}
+	if castToBool([]byte{0, 0x80}) || !castToBool([]byte{0, 1}) {
+		t.Error("Negative zero should be false and anything else true")
+	}
+}
+
+func TestPayToPubKeyHash(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ctx := &scriptContext{hash: make([]byte, 32)}
+	locking := PayToPubKeyHashScript(GetPubKeyBytes(key))
+	if GetScriptString(locking) != strings.Join([]string{"OP_DUP OP_HASH160",
+		hex.EncodeToString(Hash160(GetPubKeyBytes(key))), "OP_EQUALVERIFY OP_CHECKSIG"}, " ") {
+		t.Errorf("Unexpected script %s", GetScriptString(locking))
+	}
+	unlocking := PayToPubKeyHashUnlockingScript(signHash(key, ctx.hash), GetPubKeyBytes(key))
+	if err := verifyScript(unlocking, locking, ctx); err != nil {
+		t.Error(err)
+	}
+	// Right signature, wrong pubkey
+	unlocking = PayToPubKeyHashUnlockingScript(signHash(other, ctx.hash), GetPubKeyBytes(other))
+	if err := verifyScript(unlocking, locking, ctx); err == nil {
+		t.Error("Should not unlock with another key")
+	}
+	// Unlocking scripts can't run opcodes
+	var b ScriptBuilder
+	if err := verifyScript(b.AddInt(1).AddOp(OP_DUP).Script(), []byte{OP_EQUAL}, ctx); err == nil {
+		t.Error("Unlocking script should be push only")
+	}
+}
+
+func TestMultisigScript(t *testing.T) {
+	var keys []*ecdsa.PrivateKey
+	var pubKeys [][]byte
+	for i := 0; i < 3; i++ {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		keys = append(keys, key)
+		pubKeys = append(pubKeys, GetPubKeyBytes(key))
+	}
+	ctx := &scriptContext{hash: make([]byte, 32)}
+	locking := MultisigScript(2, pubKeys)
+	cases := []struct {
+		signers []int
+		valid   bool
+	}{
+		{[]int{0, 1}, true},
+		{[]int{0, 2}, true},
+		{[]int{1, 2}, true},
+		// Out of order, too few and the same key twice
+		{[]int{2, 0}, false},
+		{[]int{1}, false},
+		{[]int{1, 1}, false},
+	}
+	for _, c := range cases {
+		var b ScriptBuilder
+		for _, signer := range c.signers {
+			b.AddData(signHash(keys[signer], ctx.hash))
+		}
+		err := verifyScript(b.Script(), locking, ctx)
+		if (err == nil) != c.valid {
+			t.Errorf("Signers %v valid %v got %v", c.signers, c.valid, err)
+		}
+	}
+}
+
+func TestDataAndLockScripts(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ctx := &scriptContext{hash: make([]byte, 32), lockTime: 10, sequence: 3}
+	if err := verifyScript(nil, DataScript([]byte("hello")), ctx); err == nil {
+		t.Error("Data outputs should never be spendable")
+	}
+	var b ScriptBuilder
+	preimage := []byte("secret")
+	hash := sha256.Sum256(preimage)
+	locking := HashLockScript(hash[:], GetPubKeyBytes(key))
+	unlocking := b.AddData(signHash(key, ctx.hash)).AddData(preimage).Script()
+	if err := verifyScript(unlocking, locking, ctx); err != nil {
+		t.Error(err)
+	}
+	b = ScriptBuilder{}
+	unlocking = b.AddData(signHash(key, ctx.hash)).AddData([]byte("guess")).Script()
+	if err := verifyScript(unlocking, locking, ctx); err == nil {
+		t.Error("Should need the preimage")
+	}
+	// Script lock times either side of the transaction's, and a time
+	// can't be compared with a height
+	for _, c := range []struct {
+		lockTime int64
+		valid    bool
+	}{{9, true}, {10, true}, {11, false}, {1500000000, false}} {
+		b = ScriptBuilder{}
+		err := verifyScript(b.AddData(signHash(key, ctx.hash)).Script(), TimeLockScript(c.lockTime, GetPubKeyBytes(key)), ctx)
+		if (err == nil) != c.valid {
+			t.Errorf("Lock time %d valid %v got %v", c.lockTime, c.valid, err)
+		}
+	}
+	for _, c := range []struct {
+		blocks int64
+		valid  bool
+	}{{2, true}, {3, true}, {4, false}} {
+		b = ScriptBuilder{}
+		err := verifyScript(b.AddData(signHash(key, ctx.hash)).Script(), RelativeLockScript(c.blocks, GetPubKeyBytes(key)), ctx)
+		if (err == nil) != c.valid {
+			t.Errorf("Relative lock %d valid %v got %v", c.blocks, c.valid, err)
+		}
+	}
+}
+
+func TestScriptBranches(t *testing.T) {
+	ctx := &scriptContext{}
+	locking, err := CompileScript("OP_IF OP_2 OP_ELSE OP_3 OP_ENDIF OP_3 OP_EQUAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyScript([]byte{OP_0}, locking, ctx); err != nil {
+		t.Error(err)
+	}
+	if err := verifyScript([]byte{OP_1}, locking, ctx); err == nil {
+		t.Error("True branch leaves 2 which is not 3")
+	}
+	if err := verifyScript(nil, []byte{OP_IF}, ctx); err == nil {
+		t.Error("Unbalanced OP_IF should fail")
+	}
+}
@@ -0,0 +1,55 @@
+// New coin comes from the coinbase of each block. The subsidy halves
+// every halvingInterval blocks until it reaches zero, which caps the
+// total supply, and coinbase outputs can't be spent until they have
+// coinbaseMaturity confirmations so a reorg can't make spends of them vanish.
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+)
+
+const (
+	BLOCK_REWARD      = 10 // Subsidy of the first blocks, halves from there
+	HALVING_INTERVAL  = 210000
+	COINBASE_MATURITY = 10
+)
+
+// Coin created by the block at this height
+func (blockChain Blockchain) GetBlockSubsidy(height uint64) uint64 {
+	halvings := height / blockChain.HalvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+	return blockChain.BlockReward >> halvings
+}
+
+// Every coin there will ever be, the sum of the subsidy of every block
+func (blockChain Blockchain) GetMaxSupply() uint64 {
+	var supply uint64
+	for subsidy := blockChain.BlockReward; subsidy > 0; subsidy >>= 1 {
+		supply += subsidy * blockChain.HalvingInterval
+	}
+	// No block 0 or genesis coinbase
+	return supply - blockChain.GetBlockSubsidy(0) - blockChain.GetBlockSubsidy(1)
+}
+
+func IsCoinbase(transaction *pb.Transaction) bool {
+	return len(transaction.Vin) == 0
+}
+
+// Whether a coinbase output confirmed at this height can go in a block at spendHeight
+func (blockChain Blockchain) IsMature(confirmed uint64, spendHeight uint64) bool {
+	return spendHeight >= confirmed+blockChain.CoinbaseMaturity
+}
+
+// UTXOs which could be spent in the next block
+func (blockChain Blockchain) GetSpendableUTXOs(utxos []*UTXO) []*UTXO {
+	var spendable []*UTXO
+	for _, utxo := range utxos {
+		confirmed := blockChain.GetConfirmationHeight(GetTransactionHash(utxo.Transaction))
+		if !IsCoinbase(utxo.Transaction) || blockChain.IsMature(confirmed, uint64(blockChain.NextBlockNum)) {
+			spendable = append(spendable, utxo)
+		}
+	}
+	return spendable
+}
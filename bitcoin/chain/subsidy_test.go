@@ -0,0 +1,22 @@
+package chain
+
+import (
+	"testing"
+)
+
+func TestBlockSubsidy(t *testing.T) {
+	chain := Blockchain{BlockReward: 10, HalvingInterval: 5}
+	for height, subsidy := range map[uint64]uint64{2: 10, 4: 10, 5: 5, 9: 5, 10: 2, 15: 1, 19: 1, 20: 0, 1000: 0} {
+		if got := chain.GetBlockSubsidy(height); got != subsidy {
+			t.Errorf("Subsidy at height %d is %d should be %d", height, got, subsidy)
+		}
+	}
+	// Genesis has no coinbase, the first block mined is height 2
+	var total uint64
+	for height := uint64(2); height < 1000; height++ {
+		total += chain.GetBlockSubsidy(height)
+	}
+	if total != 70 || chain.GetMaxSupply() != total {
+		t.Errorf("Supply is %d max supply %d should both be 70", total, chain.GetMaxSupply())
+	}
+}
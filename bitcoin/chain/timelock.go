@@ -0,0 +1,58 @@
+// Lock times, absolute for a whole transaction and relative for each
+// input. Both are consensus rules, checked against the height of the
+// block a transaction goes in (the next block for the mempool) and the
+// median time past of the blocks before it, like bitcoin's BIP 113.
+package chain
+
+import (
+	"errors"
+	"fmt"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+)
+
+func IsFinalTransaction(transaction *pb.Transaction, height uint64, medianTime uint64) bool {
+	if transaction.LockTime == 0 {
+		return true
+	}
+	if transaction.LockTime < LOCKTIME_THRESHOLD {
+		return transaction.LockTime < height
+	}
+	return transaction.LockTime < medianTime
+}
+
+// Height of the block a transaction confirmed in, 0 if it isn't in the chain
+func (blockChain Blockchain) GetConfirmationHeight(txID []byte) uint64 {
+	idx, ok := blockChain.TxIndex[string(txID)]
+	if !ok {
+		return 0
+	}
+	return blockChain.Blocks[idx.BlockHash].Header.Height
+}
+
+// An input with sequence n can only go in a block at least n blocks
+// after the one its UTXO confirmed in
+func (blockChain Blockchain) CheckSequenceLocks(transaction *pb.Transaction, height uint64) error {
+	for i, txi := range transaction.Vin {
+		if txi.Sequence == 0 {
+			continue
+		}
+		confirmed := blockChain.GetConfirmationHeight(txi.TxID)
+		if confirmed == 0 || height < confirmed+txi.Sequence {
+			return errors.New(fmt.Sprintf("Input %d locked until height %d", i, confirmed+txi.Sequence))
+		}
+	}
+	return nil
+}
+
+func (blockChain Blockchain) CheckTimeLocks(transaction *pb.Transaction, height uint64, medianTime uint64) error {
+	if !IsFinalTransaction(transaction, height, medianTime) {
+		return errors.New(fmt.Sprintf("Transaction is locked until after %d", transaction.LockTime))
+	}
+	return blockChain.CheckSequenceLocks(transaction, height)
+}
+
+// The mempool only takes transactions which could go in the next block
+func (blockChain Blockchain) CheckMempoolTimeLocks(transaction *pb.Transaction) error {
+	return blockChain.CheckTimeLocks(transaction, uint64(blockChain.NextBlockNum),
+		blockChain.GetMedianTimePast(blockChain.TipsOfChains[0]))
+}
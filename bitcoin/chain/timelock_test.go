@@ -0,0 +1,31 @@
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+func TestIsFinalTransaction(t *testing.T) {
+	cases := []struct {
+		lockTime  uint64
+		height    uint64
+		blockTime uint64
+		final     bool
+	}{
+		{0, 1, 0, true},
+		// Lock time is the last height it can't be mined at
+		{10, 10, 0, false},
+		{10, 11, 0, true},
+		{LOCKTIME_THRESHOLD - 1, LOCKTIME_THRESHOLD - 1, 0, false},
+		{LOCKTIME_THRESHOLD - 1, LOCKTIME_THRESHOLD, 0, true},
+		// Times compare against the block time, not the height
+		{1500000000, 2000000000, 1500000000, false},
+		{1500000000, 1, 1500000001, true},
+	}
+	for _, c := range cases {
+		trans := pb.Transaction{LockTime: c.lockTime}
+		if IsFinalTransaction(&trans, c.height, c.blockTime) != c.final {
+			t.Errorf("Lock time %d at height %d time %d should be final %v", c.lockTime, c.height, c.blockTime, c.final)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Block timestamp rules. Timestamps are seconds since the epoch and a
+// block's has to be later than the median of the previous blocks and
+// not too far past network adjusted time. The median can't be pushed
+// around by one miner so it is also what time based lock times use.
+package chain
+
+import (
+	"errors"
+	"fmt"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"sort"
+	"time"
+)
+
+const (
+	MEDIAN_TIME_SPAN      = 11
+	MAX_FUTURE_BLOCK_TIME = 2 * 60 * 60 // Seconds
+)
+
+// Where the current time comes from, the node uses network adjusted time
+type Clock interface {
+	Now() uint64
+}
+
+// Falls back to our own clock if there is no network time
+func (blockChain Blockchain) now() uint64 {
+	if blockChain.Clock == nil {
+		return uint64(time.Now().Unix())
+	}
+	return blockChain.Clock.Now()
+}
+
+// Median timestamp of this block and the ones before it
+func (blockChain Blockchain) GetMedianTimePast(block *pb.Block) uint64 {
+	var timestamps []uint64
+	for block != nil && len(timestamps) < MEDIAN_TIME_SPAN {
+		timestamps = append(timestamps, block.Header.TimeStamp)
+		block = blockChain.GetPrevBlock(block)
+	}
+	if len(timestamps) == 0 {
+		return 0
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	return timestamps[len(timestamps)/2]
+}
+
+// Median time past of the block this one builds on
+func (blockChain Blockchain) GetPrevMedianTimePast(block *pb.Block) uint64 {
+	return blockChain.GetMedianTimePast(blockChain.Blocks[string(block.Header.PrevBlockHash)])
+}
+
+func (blockChain Blockchain) CheckBlockTime(block *pb.Block) error {
+	if median := blockChain.GetPrevMedianTimePast(block); block.Header.TimeStamp <= median {
+		return errors.New(fmt.Sprintf("Block time %d is not after the median time past %d", block.Header.TimeStamp, median))
+	}
+	if limit := blockChain.now() + blockChain.MaxFutureDrift; block.Header.TimeStamp > limit {
+		return errors.New(fmt.Sprintf("Block time %d is more than %d seconds in the future", block.Header.TimeStamp, blockChain.MaxFutureDrift))
+	}
+	return nil
+}
+
+// Time for a block we mine, later than the median even if our clock is behind
+func (blockChain Blockchain) GetNextBlockTime(prev *pb.Block) uint64 {
+	now := blockChain.now()
+	if median := blockChain.GetMedianTimePast(prev); now <= median {
+		return median + 1
+	}
+	return now
+}
@@ -0,0 +1,26 @@
+package chain
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+func TestMedianTimePast(t *testing.T) {
+	chain := Blockchain{Blocks: make(map[string]*pb.Block)}
+	var prev *pb.Block
+	// Out of order times, only the last 11 count
+	for _, timeStamp := range []uint64{100, 200, 13, 5, 7, 11, 3, 1, 9, 2, 4, 6, 8} {
+		block := &pb.Block{Header: &pb.BlockHeader{TimeStamp: timeStamp, PrevBlockHash: make([]byte, 32)}}
+		if prev != nil {
+			block.Header.PrevBlockHash = GetBlockHash(prev)
+		}
+		chain.Blocks[string(GetBlockHash(block))] = block
+		prev = block
+	}
+	if median := chain.GetMedianTimePast(prev); median != 6 {
+		t.Errorf("Median time past is %d should be 6", median)
+	}
+	if next := chain.GetNextBlockTime(prev); next <= 6 {
+		t.Errorf("Next block time %d should be after the median", next)
+	}
+}
@@ -0,0 +1,204 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/serialize"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+func GetPubKeyBytes(key *ecdsa.PrivateKey) []byte {
+	return GetPubKeyBytesFromPublicKey(&key.PublicKey)
+}
+
+// Each coordinate padded to 32 bytes, GetPublicKeyFromBytes splits them
+// in the middle
+func GetPubKeyBytesFromPublicKey(key *ecdsa.PublicKey) []byte {
+	pubKey := make([]byte, 64)
+	key.X.FillBytes(pubKey[:32])
+	key.Y.FillBytes(pubKey[32:])
+	return pubKey
+}
+
+func GetPublicKeyFromBytes(pubKey []byte) *ecdsa.PublicKey {
+	key := ecdsa.PublicKey{Curve: elliptic.P256()}
+	key.X = new(big.Int).SetBytes(pubKey[:32])
+	key.Y = new(big.Int).SetBytes(pubKey[32:])
+	return &key
+}
+
+// r and s are each padded to 32 bytes, big.Int drops leading zeroes
+// which would otherwise give a short signature every so often
+func GetSignatureBytes(r *big.Int, s *big.Int) []byte {
+	signature := make([]byte, 64)
+	rBytes := r.Bytes()
+	sBytes := s.Bytes()
+	copy(signature[32-len(rBytes):32], rBytes)
+	copy(signature[64-len(sBytes):], sBytes)
+	return signature
+}
+
+func VerifySignature(pubKey []byte, hash []byte, signature []byte) bool {
+	if len(signature) != 64 || len(pubKey) < 32 {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	return ecdsa.Verify(GetPublicKeyFromBytes(pubKey), hash, r, s)
+}
+
+// Whether the transaction could go in the next block
+func (blockChain Blockchain) VerifyTransaction(transaction *pb.Transaction) bool {
+	return blockChain.VerifyTransactionAt(transaction, uint64(blockChain.NextBlockNum),
+		blockChain.GetMedianTimePast(blockChain.TipsOfChains[0]))
+}
+
+// Check the transaction can go in a block at this height, with this median time past
+// 0. Its lock time and the relative locks of its inputs have passed
+// Then for every input
+//  1. The referenced UTXO exists and is not already spent
+//  2. It is unlocked: if it has a locking script the input's unlocking script
+//     satisfies it, otherwise the transaction is signed by the receiver's key
+//  3. Vin >= Vout (value wise), the difference is the fee paid to the miner
+func (blockChain Blockchain) VerifyTransactionAt(transaction *pb.Transaction, height uint64, medianTime uint64) bool {
+	if len(transaction.Vin) == 0 {
+		// Coinbase transaction, the amount depends on the fees
+		// of the rest of the block so it is checked in blockIsValid.
+		// In theory someone could put an address other than their
+		// own pubkey but that wouldn't make a lot of sense
+		if len(transaction.Vout) != 1 {
+			// should only be one output to the miner
+			return false
+		}
+		return true
+	}
+	ctx := &scriptContext{hash: GetTransactionHash(transaction), lockTime: transaction.LockTime}
+	// Debug as the miner checks every mempool transaction for each block
+	invalid := func(reason string, args ...interface{}) bool {
+		logging.Chain.Debug("Invalid transaction", append([]interface{}{logging.Tx(ctx.hash), "reason", reason}, args...)...)
+		return false
+	}
+	if err := blockChain.CheckTimeLocks(transaction, height, medianTime); err != nil {
+		return invalid(err.Error())
+	}
+	totalVinValue := uint64(0)
+	for i, txi := range transaction.Vin {
+		trans := blockChain.GetTransaction(txi.TxID)
+		if trans == nil || txi.Index >= uint64(len(trans.Vout)) {
+			return invalid("input references an unknown output", "input", i)
+		}
+		for _, other := range transaction.Vin[:i] {
+			if bytes.Equal(other.TxID, txi.TxID) && other.Index == txi.Index {
+				return invalid("input spends the same output twice", "input", i)
+			}
+		}
+		if blockChain.IsSpent(txi) {
+			return invalid("input references a spent output", "input", i)
+		}
+		if IsCoinbase(trans) && !blockChain.IsMature(blockChain.GetConfirmationHeight(txi.TxID), height) {
+			return invalid("input spends an immature coinbase", "input", i, "maturity", blockChain.CoinbaseMaturity)
+		}
+		txo := trans.Vout[txi.Index]
+		if len(txo.LockingScript) != 0 {
+			ctx.sequence = txi.Sequence
+			if err := verifyScript(txi.UnlockingScript, txo.LockingScript, ctx); err != nil {
+				return invalid("input script failed", "input", i, "err", err)
+			}
+		} else if !VerifySignature(txo.ReceiverPubKey, ctx.hash, transaction.Signature) {
+			return invalid("input not signed by its receiver", "input", i)
+		}
+		totalVinValue += txo.Value
+	}
+	// Check whether vout value matches
+	totalVoutValue := uint64(0)
+	for _, txo := range transaction.Vout {
+		totalVoutValue += txo.Value
+	}
+	if totalVoutValue > totalVinValue {
+		return invalid("outputs are worth more than the inputs", "vin", totalVinValue, "vout", totalVoutValue)
+	}
+	return true
+}
+
+// Fee paid to the miner, the value of the inputs not claimed by the outputs.
+// Assumes the transaction has already been verified
+func (blockChain Blockchain) GetTransactionFee(transaction *pb.Transaction) uint64 {
+	var fee uint64
+	for _, txi := range transaction.Vin {
+		fee += blockChain.GetTransaction(txi.TxID).Vout[txi.Index].Value
+	}
+	for _, txo := range transaction.Vout {
+		fee -= txo.Value
+	}
+	return fee
+}
+
+func GetTXIString(tx *pb.TXI) string {
+	var buf bytes.Buffer
+	buf.WriteString("\n  TxID:")
+	buf.WriteString(hex.EncodeToString(tx.TxID[:]))
+	buf.WriteString("\n  Index:")
+	buf.WriteString(strconv.Itoa(int(tx.Index)))
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func GetTXOString(tx *pb.TXO) string {
+	var buf bytes.Buffer
+	if len(tx.LockingScript) != 0 {
+		buf.WriteString("\n  Script:")
+		buf.WriteString(GetScriptString(tx.LockingScript))
+		buf.WriteString("\n  Amount:")
+		buf.WriteString(strconv.Itoa(int(tx.Value)))
+		buf.WriteString("\n")
+		return buf.String()
+	}
+	buf.WriteString("\n  Receiver:")
+	pubKey := ecdsa.PublicKey{Curve: elliptic.P256()}
+	pubKey.X = new(big.Int)
+	pubKey.Y = new(big.Int)
+	pubKey.X.SetBytes(tx.ReceiverPubKey[:32])
+	pubKey.Y.SetBytes(tx.ReceiverPubKey[32:])
+	buf.WriteString(strings.Join([]string{pubKey.X.String(), pubKey.Y.String()}, ""))
+	buf.WriteString("\n  Amount:")
+	buf.WriteString(strconv.Itoa(int(tx.Value)))
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func GetTransactionString(transaction *pb.Transaction) string {
+	var buf bytes.Buffer
+	buf.WriteString("\nTransaction Hash: ")
+	buf.WriteString(hex.EncodeToString(GetTransactionHash(transaction)[:]))
+	buf.WriteString("\nVin: ")
+	if len(transaction.Vin) == 0 {
+		buf.WriteString("Miner reward")
+	}
+	for _, inputUTXO := range transaction.Vin {
+		buf.WriteString("\n TX: ")
+		buf.WriteString(GetTXIString(inputUTXO))
+	}
+	buf.WriteString("\nVout: ")
+	for _, outputTX := range transaction.Vout {
+		buf.WriteString("\n TX: ")
+		buf.WriteString(GetTXOString(outputTX))
+	}
+	buf.WriteString("\nHeight: ")
+	buf.WriteString(strconv.Itoa(int(transaction.Height)))
+	return buf.String()
+}
+
+// Super important: Height is hashed to make coinbase transactions unique
+func GetTransactionHash(transaction *pb.Transaction) []byte {
+	return serialize.TransactionHash(transaction)
+}
+
+func (utxo *UTXO) Value() uint64 {
+	return utxo.Transaction.Vout[utxo.Index].Value
+}
@@ -1,324 +1,1120 @@
 package main
 
 import (
-	pb "../protos"
 	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
-	"encoding/binary"
+	"encoding/base64"
+	"encoding/csv"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"io"
-	"math/big"
+	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 )
 
-// TODO: use interactive cli library
-// so we can reuse the connection
+// Node to talk to, set with -rpc before the subcommand or use in the shell
+var rpcAddress = "localhost:8333"
 
-func connect() *grpc.ClientConn {
-	conn, err := grpc.Dial("localhost:8333", grpc.WithInsecure())
-	if err != nil {
-		fmt.Printf("Failed to connect to gRPC server: %v", err)
-	}
-	return conn
-}
+// Network addresses are for, set with -network
+var network = &chain.MAINNET
 
-func getBlockHash(block *pb.Block) []byte {
-	// TODO: split into getting the headers hash
-	// and the transactions hash
-	toHash := make([]byte, 0)
-	// PrevBlockHash can be nil if it is the genesis block
-	toHash = append(toHash, block.Header.PrevBlockHash...)
-	toHash = append(toHash, block.Header.MerkleRoot...)
-	value := make([]byte, 8)
-	binary.LittleEndian.PutUint64(value, block.Header.TimeStamp)
-	toHash = append(toHash, value...)
-	binary.LittleEndian.PutUint64(value, block.Header.Height)
-	toHash = append(toHash, value...)
-	value = make([]byte, 4)
-	binary.LittleEndian.PutUint32(value, block.Header.DifficultyTarget)
-	toHash = append(toHash, value...)
-	binary.LittleEndian.PutUint32(value, block.Header.Nonce)
-	toHash = append(toHash, value...)
-	for _, trans := range block.Transactions {
-		toHash = append(toHash, getTransactionHash(trans)...)
-	}
-	sum := sha256.Sum256(toHash)
-	return sum[:]
-}
+// Connections stay open until we exit, so the shell only dials each node once
+var connections = make(map[string]*grpc.ClientConn)
 
-func getBlockString(block *pb.Block) string {
-	var buf bytes.Buffer
-	buf.WriteString("\nBlock Hash: ")
-	buf.WriteString(hex.EncodeToString(getBlockHash(block)))
-	buf.WriteString("\nBlock Header: ")
-	buf.WriteString("\n  prevBlockHash: ")
-	buf.WriteString(hex.EncodeToString(block.Header.PrevBlockHash[:]))
-	buf.WriteString("\n  timestamp: ")
-	buf.WriteString(time.Unix(0, int64(block.Header.TimeStamp)).String())
-	buf.WriteString("\n  nonce: ")
-	buf.WriteString(strconv.Itoa(int(block.Header.Nonce)))
-	buf.WriteString("\n  height: ")
-	buf.WriteString(strconv.Itoa(int(block.Header.Height)))
-	buf.WriteString("\nTransactions:\n\n")
-	for i := range block.Transactions {
-		buf.WriteString("\n")
-		buf.WriteString(getTransactionString(block.Transactions[i]))
-		buf.WriteString("\n")
-	}
-	return buf.String()
+func connect() (*grpc.ClientConn, error) {
+	return connectTo(rpcAddress)
 }
 
-func getTransactionHash(transaction *pb.Transaction) []byte {
-	buf := new(bytes.Buffer)
-	for _, inputUTXO := range transaction.Vin {
-		buf.Write(inputUTXO.TxID)
-		binary.Write(buf, binary.LittleEndian, inputUTXO.Index)
+func connectTo(address string) (*grpc.ClientConn, error) {
+	if conn, ok := connections[address]; ok {
+		return conn, nil
 	}
-	for _, outputTX := range transaction.Vout {
-		buf.Write(outputTX.ReceiverPubKey)
-		binary.Write(buf, binary.LittleEndian, outputTX.Value)
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to gRPC server: %v", err)
 	}
-	// Height needed to make coinbase transactions unique
-	sum := sha256.Sum256(buf.Bytes())
-	return sum[:]
+	connections[address] = conn
+	return conn, nil
 }
 
-func getTXIString(tx *pb.TXI) string {
-	var buf bytes.Buffer
-	buf.WriteString("\n  TxID:")
-	buf.WriteString(hex.EncodeToString(tx.TxID[:]))
-	buf.WriteString("\n  Index:")
-	buf.WriteString(strconv.Itoa(int(tx.Index)))
-	return buf.String()
-}
-
-func getTXOString(tx *pb.TXO) string {
-	var buf bytes.Buffer
-	buf.WriteString("\n  Receiver:")
-	pubKey := ecdsa.PublicKey{Curve: elliptic.P256()}
-	pubKey.X = new(big.Int)
-	pubKey.Y = new(big.Int)
-	pubKey.X.SetBytes(tx.ReceiverPubKey[:32])
-	pubKey.Y.SetBytes(tx.ReceiverPubKey[32:])
-	buf.WriteString(strings.Join([]string{pubKey.X.String(), pubKey.Y.String()}, ""))
-	buf.WriteString("\n  Amount:")
-	buf.WriteString(strconv.Itoa(int(tx.Value)))
-	return buf.String()
-}
-
-func getTransactionString(transaction *pb.Transaction) string {
-	var buf bytes.Buffer
-	buf.WriteString("\nTransaction Hash: ")
-	buf.WriteString(hex.EncodeToString(getTransactionHash(transaction)[:]))
-	buf.WriteString("\nVin: ")
-	if len(transaction.Vin) == 0 {
-		buf.WriteString("Miner reward")
+func closeConnections() {
+	for address, conn := range connections {
+		conn.Close()
+		delete(connections, address)
 	}
-	for _, inputUTXO := range transaction.Vin {
-		buf.WriteString("\n TX: ")
-		buf.WriteString(getTXIString(inputUTXO))
-	}
-	buf.WriteString("\nVout: ")
-	for _, outputTX := range transaction.Vout {
-		buf.WriteString("\n TX: ")
-		buf.WriteString(getTXOString(outputTX))
-	}
-	return buf.String()
 }
 
-func getTransactions() {
-	conn := connect()
+func getTransactions() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewStateClient(conn)
 	stream, err := c.GetTransactions(context.Background(), &pb.Empty{})
 	if err != nil {
-		fmt.Printf("Unable to get state: %v", err)
+		return fmt.Errorf("Unable to get state: %v", err)
 	}
+	txs := transactionList{}
 	for {
-		feature, err := stream.Recv()
+		tx, err := stream.Recv()
 		if err == io.EOF {
-			break
+			return show(txs)
 		}
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		fmt.Println(getTransactionString(feature))
-		//         fmt.Println(feature)
+		txs = append(txs, newTransaction(tx))
 	}
-	conn.Close()
 }
 
-func getBlocks() {
-	conn := connect()
-	defer conn.Close()
+func getBlocks() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewStateClient(conn)
 	stream, err := c.GetBlocks(context.Background(), &pb.Empty{})
 	if err != nil {
-		fmt.Printf("Unable to get state: %v", err)
+		return fmt.Errorf("Unable to get state: %v", err)
 	}
+	blocks := blockList{}
 	for {
 		block, err := stream.Recv()
 		if err == io.EOF {
-			break
+			return show(blocks)
 		}
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
-		fmt.Println(getBlockString(block))
+		blocks = append(blocks, newBlock(block))
+	}
+}
+
+func getPeers() error {
+	conn, err := connect()
+	if err != nil {
+		return err
 	}
+	c := pb.NewPeeringClient(conn)
+	reply, err := c.GetPeers(context.Background(), &pb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error getting peers %v", err)
+	}
+	peers := peerList{}
+	for _, peer := range reply.Peers {
+		peers = append(peers, peerJSON{peer})
+	}
+	return show(peers)
+}
+
+// Address string is the network's prefix then two 32 byte integers concatenated
+func getPubKeyFromAddress(address string) ([]byte, error) {
+	return network.GetPubKeyFromAddress(address)
+}
+
+type recipient struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+	where   string // e.g. line 3, for errors
+}
+
+// Read the recipients of a batch payment, either a .json file
+// with a list of {"address": ..., "amount": ...} or a csv file
+// with one address,amount per line
+func readRecipients(path string) ([]*pb.TXO, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var recipients []recipient
+	if strings.HasSuffix(path, ".json") {
+		if err := json.NewDecoder(file).Decode(&recipients); err != nil {
+			return nil, err
+		}
+		for i := range recipients {
+			recipients[i].where = fmt.Sprintf("recipient %d", i+1)
+		}
+	} else {
+		records, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			if len(record) != 2 {
+				return nil, fmt.Errorf("Line %d should be address,amount", i+1)
+			}
+			amount, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 64)
+			if err != nil {
+				if i == 0 {
+					// Header line
+					continue
+				}
+				return nil, fmt.Errorf("Line %d has invalid amount %v", i+1, record[1])
+			}
+			recipients = append(recipients, recipient{Address: strings.TrimSpace(record[0]), Amount: amount,
+				where: fmt.Sprintf("line %d", i+1)})
+		}
+	}
+	var outputs []*pb.TXO
+	for _, r := range recipients {
+		pubKey, err := getPubKeyFromAddress(r.Address)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid address %q at %s of %s: %v", r.Address, r.where, path, err)
+		}
+		outputs = append(outputs, &pb.TXO{ReceiverPubKey: pubKey, Value: r.Amount})
+	}
+	return outputs, nil
+}
+
+// Flags describing a payment, shared by send and psbt create
+type paymentFlags struct {
+	amount     *int
+	dest       *string
+	feeRate    *int
+	strategy   *string
+	file       *string
+	change     *string
+	fee        *int
+	from       *string
+	fromScript *string
+	inputs     *string
+	script     *string
+	lockTime   *int
+}
+
+func addPaymentFlags(fs *flag.FlagSet) *paymentFlags {
+	var p paymentFlags
+	p.amount = fs.Int("amount", 0, "how much to send")
+	p.dest = fs.String("dest", "", "where to send")
+	p.feeRate = fs.Int("feerate", 0, "fee in coin per 1000 bytes")
+	p.strategy = fs.String("strategy", "", "coin selection: bnb, largest, smallest or random")
+	p.file = fs.String("file", "", "csv or json file of recipients to pay in one transaction")
+	p.change = fs.String("change", "", "address to send change to, defaults to the sender")
+	p.fee = fs.Int("fee", 0, "absolute fee, overrides feerate")
+	p.from = fs.String("from", "", "address whose coin to spend, defaults to the node's wallet")
+	p.fromScript = fs.String("fromscript", "", "multisig address (or hex locking script) whose coin to spend")
+	p.inputs = fs.String("inputs", "", "spend exactly these txid:index[:sequence] UTXOs, comma separated")
+	p.script = fs.String("script", "", "pay -amount to this hex locking script instead of -dest")
+	p.lockTime = fs.Int("locktime", 0, "can't be mined until after this height, or unix time if at least 500000000")
+	return &p
+}
+
+func (p *paymentFlags) request() (*pb.TransactionRequest, error) {
+	trans := pb.TransactionRequest{FeeRate: uint64(*p.feeRate), Strategy: *p.strategy, Fee: uint64(*p.fee),
+		LockTime: uint64(*p.lockTime)}
+	var err error
+	if *p.file != "" {
+		outputs, err := readRecipients(*p.file)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading recipients %v", err)
+		}
+		info(fmt.Sprintf("send to %d recipients from %v", len(outputs), *p.file))
+		trans.Outputs = outputs
+	}
+	if *p.dest != "" {
+		info(fmt.Sprintf("send %v to %v", *p.amount, *p.dest))
+		if trans.ReceiverPubKey, err = getPubKeyFromAddress(*p.dest); err != nil {
+			return nil, err
+		}
+		trans.Value = uint64(*p.amount)
+	}
+	if *p.script != "" {
+		script, err := hex.DecodeString(*p.script)
+		if err != nil {
+			return nil, fmt.Errorf("Locking script should be hex %v", err)
+		}
+		info(fmt.Sprintf("send %v to script %v", *p.amount, *p.script))
+		trans.Outputs = append(trans.Outputs, &pb.TXO{LockingScript: script, Value: uint64(*p.amount)})
+	}
+	if *p.change != "" {
+		if trans.ChangePubKey, err = getPubKeyFromAddress(*p.change); err != nil {
+			return nil, err
+		}
+	}
+	if *p.from != "" {
+		if trans.SenderPubKey, err = getPubKeyFromAddress(*p.from); err != nil {
+			return nil, err
+		}
+	}
+	if *p.fromScript != "" {
+		// The multisig address, or just the hex of any script
+		script, err := hex.DecodeString(strings.TrimPrefix(*p.fromScript, chain.SCRIPT_ADDRESS_PREFIX))
+		if err != nil {
+			return nil, fmt.Errorf("Script address should be hex %v", err)
+		}
+		trans.SenderScript = script
+	}
+	if *p.inputs != "" {
+		for _, input := range strings.Split(*p.inputs, ",") {
+			parts := strings.Split(input, ":")
+			txID, err := hex.DecodeString(parts[0])
+			if err != nil || len(parts) < 2 || len(parts) > 3 {
+				return nil, fmt.Errorf("Inputs should be txid:index[:sequence], got %v", input)
+			}
+			index, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid input index %v", parts[1])
+			}
+			// Optional relative lock in blocks
+			var sequence uint64
+			if len(parts) == 3 {
+				if sequence, err = strconv.ParseUint(parts[2], 10, 64); err != nil {
+					return nil, fmt.Errorf("Invalid input sequence %v", parts[2])
+				}
+			}
+			trans.Inputs = append(trans.Inputs, &pb.TXI{TxID: txID, Index: index, Sequence: sequence})
+		}
+	}
+	return &trans, nil
 }
 
 // Destination should be the pubkey of someone else
 // The daemon performs the wallet functionality i.e.
 // determines which utxo you reference.
-func send(amount int, destination string) {
-	conn := connect()
+func send(payment *paymentFlags) error {
+	trans, err := payment.request()
+	if err != nil {
+		return err
+	}
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewTransactionsClient(conn)
-	fmt.Println(strconv.Itoa(amount))
-	var trans pb.TransactionRequest
-	trans.Value = uint64(amount)
-	// Destination string is two 32 byte integers concatenated
-	x := new(big.Int)
-	y := new(big.Int)
-	x.SetString(destination[:77], 10)
-	y.SetString(destination[77:], 10)
-	var recv []byte
-	recv = append(recv, x.Bytes()...)
-	recv = append(recv, y.Bytes()...)
-	trans.ReceiverPubKey = recv
-	_, err := c.SendTransaction(context.Background(), &trans)
-	if err != nil {
-		fmt.Println("Error sending transaction", err)
-	}
-	conn.Close()
-}
-
-func newAccount(name string) {
+	sent, err := c.SendTransaction(context.Background(), trans)
+	if err != nil {
+		return fmt.Errorf("Error sending transaction %v", err)
+	}
+	return show(newSent(sent, true))
+}
+
+// Generate a key without a node, for keys which should never
+// be on a networked machine. Prints the address to receive coin at.
+func generateKey(path string) error {
+	if path == "" {
+		return errors.New("Need a file to write the key to")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("Error generating key %v", err)
+	}
+	if err := wallet.WriteKey(path, key); err != nil {
+		return fmt.Errorf("Error writing key %v", err)
+	}
+	return show(addressJSON{getAddressFromPubKey(chain.GetPubKeyBytes(key))})
+}
+
+// Partial transactions are stored as base64 encoded protobuf
+func readPartialTransaction(path string) (*pb.PartialTransaction, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return nil, err
+	}
+	var psbt pb.PartialTransaction
+	if err := proto.Unmarshal(raw, &psbt); err != nil {
+		return nil, err
+	}
+	return &psbt, nil
+}
+
+func writePartialTransaction(path string, psbt *pb.PartialTransaction) error {
+	raw, err := proto.Marshal(psbt)
+	if err != nil {
+		return fmt.Errorf("Error encoding partial transaction %v", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	if path != "" {
+		if err := ioutil.WriteFile(path, []byte(encoded+"\n"), 0644); err != nil {
+			return fmt.Errorf("Error writing partial transaction %v", err)
+		}
+	}
+	return show(encodedJSON{encoded, path})
+}
+
+func getPartialTransactionString(psbt *pb.PartialTransaction) string {
+	var buf bytes.Buffer
+	buf.WriteString(chain.GetTransactionString(psbt.Transaction))
+	buf.WriteString("\nSpending: ")
+	for _, txo := range psbt.Spending {
+		buf.WriteString("\n TX: ")
+		buf.WriteString(chain.GetTXOString(txo))
+	}
+	buf.WriteString("\nSigned by: ")
+	for _, signature := range psbt.Signatures {
+		buf.WriteString("\n  ")
+		buf.WriteString(hex.EncodeToString(signature.PubKey))
+	}
+	return buf.String()
+}
+
+func partialTransaction(action string, in string, out string, keyFile string, payment *paymentFlags) error {
+	var inputs []*pb.PartialTransaction
+	if in != "" {
+		for _, path := range strings.Split(in, ",") {
+			psbt, err := readPartialTransaction(path)
+			if err != nil {
+				return fmt.Errorf("Error reading partial transaction %v", err)
+			}
+			inputs = append(inputs, psbt)
+		}
+	}
+	if action != "create" && len(inputs) == 0 {
+		return fmt.Errorf("Need a partial transaction to %v", action)
+	}
+	if action == "show" {
+		return show(newPartialTransaction(inputs[0]))
+	}
+	if action == "sign" && keyFile != "" {
+		// Offline signing, no node involved
+		key, err := wallet.ReadKey(keyFile)
+		if err != nil {
+			return fmt.Errorf("Error reading key %v", err)
+		}
+		info("Signing", getPartialTransactionString(inputs[0]))
+		if err := wallet.AddPartialSignature(inputs[0], key); err != nil {
+			return fmt.Errorf("Error signing %v", err)
+		}
+		return writePartialTransaction(out, inputs[0])
+	}
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewTransactionsClient(conn)
+	var psbt *pb.PartialTransaction
+	switch action {
+	case "create":
+		var req *pb.TransactionRequest
+		if req, err = payment.request(); err != nil {
+			return err
+		}
+		psbt, err = c.CreateTransaction(context.Background(), req)
+	case "sign":
+		psbt, err = c.SignTransaction(context.Background(), inputs[0])
+	case "combine":
+		psbt, err = c.CombineTransactions(context.Background(), &pb.PartialTransactions{Transactions: inputs})
+	case "finalize":
+		sent, err := c.FinalizeTransaction(context.Background(), inputs[0])
+		if err != nil {
+			return fmt.Errorf("Error finalizing transaction %v", err)
+		}
+		return show(newSent(sent, false))
+	default:
+		return errors.New("Unknown psbt action")
+	}
+	if err != nil {
+		return fmt.Errorf("Error %v %v", action, err)
+	}
+	return writePartialTransaction(out, psbt)
+}
+
+func newAccount(name string) error {
 	// Need to make a new key pair associated with this account
-	conn := connect()
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewWalletClient(conn)
 	addr, err := c.NewAccount(context.Background(), &pb.Account{Name: name})
 	if err != nil {
-		fmt.Println("Error sending transaction", err)
+		return fmt.Errorf("Error creating account %v", err)
 	}
-	fmt.Println(addr.Address)
-	conn.Close()
+	return show(addressJSON{addr.Address})
 }
 
-func startMining() {
-	conn := connect()
+func startMining() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewMinerClient(conn)
-	_, err := c.StartMining(context.Background(), &pb.Empty{})
+	if _, err := c.StartMining(context.Background(), &pb.Empty{}); err != nil {
+		return fmt.Errorf("Error starting mining %v", err)
+	}
+	return show(doneJSON{})
+}
+
+func stopMining() error {
+	conn, err := connect()
 	if err != nil {
-		fmt.Println("Error sending transaction", err)
+		return err
+	}
+	c := pb.NewMinerClient(conn)
+	if _, err := c.StopMining(context.Background(), &pb.Empty{}); err != nil {
+		return fmt.Errorf("Error stopping mining %v", err)
 	}
-	conn.Close()
+	return show(doneJSON{})
 }
 
-func stopMining() {
-	conn := connect()
+func miningStatus() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewMinerClient(conn)
-	_, err := c.StopMining(context.Background(), &pb.Empty{})
+	status, err := c.GetMiningStatus(context.Background(), &pb.Empty{})
 	if err != nil {
-		fmt.Println("Error sending transaction", err)
+		return fmt.Errorf("Error getting mining status %v", err)
 	}
-	conn.Close()
+	return show(miningStatusJSON{status.State, status.BlocksMined, status.StartTime, status.Height,
+		hex.EncodeToString(status.Tip)})
 }
 
-func getBalance() {
-	// Need to make a new key pair associated with this account
-	conn := connect()
+// Mine exactly n blocks to address (or the node's wallet if empty) and
+// print their hashes
+func generateBlocks(n int, address string) error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewMinerClient(conn)
+	reply, err := c.GenerateBlocks(context.Background(), &pb.GenerateRequest{Blocks: uint32(n), Address: address})
+	// Some may have been mined before an error
+	if reply != nil {
+		generated := generatedJSON{Hashes: []string{}}
+		for _, hash := range reply.Hashes {
+			generated.Hashes = append(generated.Hashes, hex.EncodeToString(hash))
+		}
+		if err := show(generated); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Error generating blocks %v", err)
+	}
+	return nil
+}
+
+func setFaults(faults *pb.LinkFaults) error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewAdminClient(conn)
+	if _, err := c.SetFaults(context.Background(), faults); err != nil {
+		return fmt.Errorf("Error setting faults %v", err)
+	}
+	return show(doneJSON{})
+}
+
+func clearFaults() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewAdminClient(conn)
+	if _, err := c.ClearFaults(context.Background(), &pb.Empty{}); err != nil {
+		return fmt.Errorf("Error clearing faults %v", err)
+	}
+	return show(doneJSON{})
+}
+
+func listFaults() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewAdminClient(conn)
+	reply, err := c.GetFaults(context.Background(), &pb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error getting faults %v", err)
+	}
+	links := faultsList{}
+	for _, faults := range reply.Links {
+		links = append(links, faultsJSON{faults.Peer, faults.Latency, faults.Jitter, faults.Loss, faults.Reorder, faults.Down})
+	}
+	return show(links)
+}
+
+func setLogLevel(subsystem string, level string) error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewAdminClient(conn)
+	reply, err := c.SetLogLevel(context.Background(), &pb.LogLevel{Subsystem: subsystem, Level: level})
+	if err != nil {
+		return fmt.Errorf("Error setting log level %v", err)
+	}
+	return show(newLogLevels(reply))
+}
+
+func listLogLevels() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewAdminClient(conn)
+	reply, err := c.GetLogLevels(context.Background(), &pb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error getting log levels %v", err)
+	}
+	return show(newLogLevels(reply))
+}
+
+func getBalance() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewWalletClient(conn)
 	balance, err := c.GetBalance(context.Background(), &pb.Empty{})
 	if err != nil {
-		fmt.Println("Error sending transaction", err)
+		return fmt.Errorf("Error getting balance %v", err)
 	}
-	fmt.Printf("%d\n", balance.Balance)
-	conn.Close()
+	return show(balanceJSON{balance.Balance, balance.Spendable})
 }
 
-func getAddress() {
-	// Need to make a new key pair associated with this account
-	conn := connect()
+func getHistory(offset int, limit int) error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewWalletClient(conn)
+	history, err := c.GetHistory(context.Background(), &pb.Page{Offset: uint64(offset), Limit: uint32(limit)})
+	if err != nil {
+		return fmt.Errorf("Error getting history %v", err)
+	}
+	return show(newHistory(history))
+}
+
+// Balance, history or UTXOs of any address, or multisig script in hex
+func lookupAddress(get string, address string, offset int, limit int) error {
+	if address == "" {
+		return errors.New("Need an -address")
+	}
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewStateClient(conn)
+	query := &pb.AddressQuery{Address: address, Page: &pb.Page{Offset: uint64(offset), Limit: uint32(limit)}}
+	switch get {
+	case "balance":
+		balance, err := c.GetAddressBalance(context.Background(), query)
+		if err != nil {
+			return fmt.Errorf("Error getting balance %v", err)
+		}
+		return show(addressBalanceJSON{balance.Balance, balance.Utxos, balance.Received, balance.Sent, balance.Transactions})
+	case "history":
+		history, err := c.GetAddressHistory(context.Background(), query)
+		if err != nil {
+			return fmt.Errorf("Error getting history %v", err)
+		}
+		return show(newHistory(history))
+	case "utxos":
+		page, err := c.GetAddressUTXOs(context.Background(), query)
+		if err != nil {
+			return fmt.Errorf("Error getting UTXOs %v", err)
+		}
+		utxos := utxoList{}
+		for _, utxo := range page.Utxos {
+			utxos = append(utxos, utxoJSON{hex.EncodeToString(utxo.TxID), utxo.Index, utxo.Output.Value, utxo.Height, utxo.Coinbase})
+		}
+		return show(utxos)
+	}
+	return errors.New("Unknown get op")
+}
+
+func getMultisigString(address *pb.MultisigAddress) string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%d of %d multisig, balance %d\n", address.Required, len(address.PubKeys), address.Balance))
+	buf.WriteString(address.Address)
+	for _, pubKey := range address.PubKeys {
+		buf.WriteString("\n  ")
+		buf.WriteString(getAddressFromPubKey(pubKey))
+	}
+	return buf.String()
+}
+
+func getAddressFromPubKey(pubKey []byte) string {
+	return network.GetAddress(pubKey)
+}
+
+// Pass the same keys to every node holding one of them so they all
+// watch the address and can sign for it
+func createMultisig(required int, addresses string) error {
+	var req pb.MultisigRequest
+	req.Required = uint32(required)
+	for _, address := range strings.Split(addresses, ",") {
+		pubKey, err := getPubKeyFromAddress(address)
+		if err != nil {
+			return err
+		}
+		req.PubKeys = append(req.PubKeys, pubKey)
+	}
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewWalletClient(conn)
+	address, err := c.CreateMultisig(context.Background(), &req)
+	if err != nil {
+		return fmt.Errorf("Error creating multisig address %v", err)
+	}
+	return show(multisigList{newMultisig(address)})
+}
+
+func listMultisig() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c := pb.NewWalletClient(conn)
+	stream, err := c.GetMultisigAddresses(context.Background(), &pb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error getting multisig addresses %v", err)
+	}
+	addresses := multisigList{}
+	for {
+		address, err := stream.Recv()
+		if err == io.EOF {
+			return show(addresses)
+		}
+		if err != nil {
+			return fmt.Errorf("Error getting multisig addresses %v", err)
+		}
+		addresses = append(addresses, newMultisig(address))
+	}
+}
+
+func parseOutpoint(outpoint string) (*pb.HTLCSpend, error) {
+	parts := strings.Split(outpoint, ":")
+	if len(parts) != 2 {
+		return nil, errors.New("Contract should be txid:index")
+	}
+	txID, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.HTLCSpend{TxID: txID, Index: index}, nil
+}
+
+func getHTLCString(contract *pb.HTLC) string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("Contract: %x:%d\n", contract.TxID, contract.Index))
+	buf.WriteString(fmt.Sprintf("Value: %d\n", contract.Value))
+	buf.WriteString(fmt.Sprintf("Secret hash: %x\n", contract.Hash))
+	buf.WriteString("Recipient: " + getAddressFromPubKey(contract.RecipientPubKey) + "\n")
+	buf.WriteString("Refund to: " + getAddressFromPubKey(contract.RefundPubKey) + "\n")
+	buf.WriteString(fmt.Sprintf("Refundable after height: %d", contract.LockTime))
+	if len(contract.SpentBy) != 0 {
+		buf.WriteString(fmt.Sprintf("\nSpent by: %x", contract.SpentBy))
+	}
+	if len(contract.Preimage) != 0 {
+		buf.WriteString(fmt.Sprintf("\nSecret: %x", contract.Preimage))
+	}
+	return buf.String()
+}
+
+// Atomic swap between two chains, each step talks to the node given by -rpc:
+// initiate on chain A (makes the secret), participate on chain B with the
+// initiator's hash and a shorter lock, initiator redeems on B revealing the
+// secret, participant finds it with -action=secret and redeems on A
+func swap(action string, rpc string, dest string, amount int, lockBlocks int, hash string, contract string, secret string, fee int) error {
+	conn, err := connectTo(rpc)
+	if err != nil {
+		return err
+	}
+	c := pb.NewSwapsClient(conn)
+	switch action {
+	case "initiate", "participate":
+		recipient, err := getPubKeyFromAddress(dest)
+		if err != nil {
+			return err
+		}
+		req := pb.HTLCRequest{RecipientPubKey: recipient, Value: uint64(amount), LockBlocks: uint64(lockBlocks)}
+		var preimage []byte
+		if action == "initiate" {
+			preimage = make([]byte, 32)
+			if _, err := rand.Read(preimage); err != nil {
+				return fmt.Errorf("Error making secret %v", err)
+			}
+			sum := sha256.Sum256(preimage)
+			req.Hash = sum[:]
+		} else if req.Hash, err = hex.DecodeString(hash); err != nil {
+			return fmt.Errorf("Hash should be hex %v", err)
+		}
+		created, err := c.CreateHTLC(context.Background(), &req)
+		if err != nil {
+			return fmt.Errorf("Error creating contract %v", err)
+		}
+		contract := newHTLC(created)
+		if preimage != nil {
+			contract.Secret = hex.EncodeToString(preimage)
+			contract.initiated = true
+		}
+		return show(contract)
+	case "redeem", "refund":
+		spend, err := parseOutpoint(contract)
+		if err != nil {
+			return err
+		}
+		spend.Fee = uint64(fee)
+		var sent *pb.TransactionSent
+		if action == "redeem" {
+			if spend.Preimage, err = hex.DecodeString(secret); err != nil {
+				return fmt.Errorf("Secret should be hex %v", err)
+			}
+			sent, err = c.RedeemHTLC(context.Background(), spend)
+		} else {
+			sent, err = c.RefundHTLC(context.Background(), spend)
+		}
+		if err != nil {
+			return fmt.Errorf("Error %v %v", action, err)
+		}
+		return show(newSent(sent, false))
+	case "audit", "secret":
+		spend, err := parseOutpoint(contract)
+		if err != nil {
+			return err
+		}
+		found, err := c.GetHTLC(context.Background(), spend)
+		if err != nil {
+			return fmt.Errorf("Error getting contract %v", err)
+		}
+		if action == "audit" {
+			return show(newHTLC(found))
+		}
+		return show(secretJSON{hex.EncodeToString(found.Preimage)})
+	}
+	return errors.New("Unknown swap action")
+}
+
+func getAddress() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
 	c := pb.NewWalletClient(conn)
 	address, err := c.GetAddress(context.Background(), &pb.Empty{})
 	if err != nil {
-		fmt.Println("Error sending transaction", err)
+		return fmt.Errorf("Error getting address %v", err)
+	}
+	return show(addressJSON{address.Address})
+}
+
+type command struct {
+	name    string
+	usage   string
+	choices map[string][]string // Values some flags take, for help and tab completion
+	// Adds the command's flags, returning what runs it once they are parsed
+	flags func(fs *flag.FlagSet) func() error
+}
+
+// Everything the client can do, on the command line or in the shell
+var commands = []command{
+	{"new", "create a wallet, do this first", nil, func(fs *flag.FlagSet) func() error {
+		name := fs.String("name", "", "name of account")
+		return func() error {
+			info("New account:", *name)
+			return newAccount(*name)
+		}
+	}},
+	{"wallet", "the node's address, balance or transaction history", map[string][]string{"get": {"address", "balance", "history"}}, func(fs *flag.FlagSet) func() error {
+		get := fs.String("get", "", "get balance, pubkey etc.")
+		offset := fs.Int("offset", 0, "newest history entries to skip")
+		limit := fs.Int("limit", 20, "most history entries to show")
+		return func() error {
+			switch *get {
+			case "balance":
+				return getBalance()
+			case "address":
+				return getAddress()
+			case "history":
+				return getHistory(*offset, *limit)
+			}
+			return errors.New("Unknown get op")
+		}
+	}},
+	{"address", "balance, history or unspent outputs of any address", map[string][]string{"get": {"balance", "history", "utxos"}}, func(fs *flag.FlagSet) func() error {
+		get := fs.String("get", "", "balance, history or utxos")
+		address := fs.String("address", "", "address, or hex locking script of a multisig address")
+		offset := fs.Int("offset", 0, "newest entries to skip")
+		limit := fs.Int("limit", 20, "most entries to show")
+		return func() error {
+			return lookupAddress(*get, *address, *offset, *limit)
+		}
+	}},
+	{"state", "the blockchain or mempool", map[string][]string{"get": {"blocks", "transactions"}}, func(fs *flag.FlagSet) func() error {
+		get := fs.String("get", "", "what you want to get")
+		return func() error {
+			info("get state of", *get)
+			switch *get {
+			case "transactions":
+				return getTransactions()
+			case "blocks":
+				return getBlocks()
+			}
+			return errors.New("Unknown get op")
+		}
+	}},
+	{"peers", "who the node is connected to", nil, func(fs *flag.FlagSet) func() error {
+		return getPeers
+	}},
+	{"send", "pay an address, a script or many recipients", map[string][]string{"strategy": {"bnb", "largest", "smallest", "random"}}, func(fs *flag.FlagSet) func() error {
+		payment := addPaymentFlags(fs)
+		return func() error {
+			return send(payment)
+		}
+	}},
+	{"mine", "start, stop or check on the miner", map[string][]string{"action": {"start", "stop", "status"}}, func(fs *flag.FlagSet) func() error {
+		action := fs.String("action", "", "start/stop mining or status")
+		return func() error {
+			switch *action {
+			case "start":
+				return startMining()
+			case "stop":
+				return stopMining()
+			case "status":
+				return miningStatus()
+			}
+			return errors.New("Unknown mine action")
+		}
+	}},
+	{"generate", "mine exactly n blocks right now", nil, func(fs *flag.FlagSet) func() error {
+		blocks := fs.Int("blocks", 1, "how many blocks to mine")
+		address := fs.String("address", "", "address to pay, defaults to the node's wallet")
+		return func() error {
+			return generateBlocks(*blocks, *address)
+		}
+	}},
+	{"keygen", "make a key without a node", nil, func(fs *flag.FlagSet) func() error {
+		out := fs.String("out", "", "file to write the new private key to")
+		return func() error {
+			return generateKey(*out)
+		}
+	}},
+	{"psbt", "create, sign, combine and finalize partial transactions", map[string][]string{"action": {"create", "show", "sign", "combine", "finalize"}}, func(fs *flag.FlagSet) func() error {
+		action := fs.String("action", "", "create, show, sign, combine or finalize")
+		in := fs.String("in", "", "partial transaction file(s), comma separated for combine")
+		out := fs.String("out", "", "file to write the partial transaction to")
+		key := fs.String("key", "", "sign offline with this key file instead of the node's key")
+		payment := addPaymentFlags(fs)
+		return func() error {
+			return partialTransaction(*action, *in, *out, *key, payment)
+		}
+	}},
+	{"multisig", "create or list M of N addresses", map[string][]string{"action": {"create", "list"}}, func(fs *flag.FlagSet) func() error {
+		action := fs.String("action", "", "create or list")
+		required := fs.Int("m", 0, "signatures required")
+		keys := fs.String("keys", "", "addresses of the N keys, comma separated")
+		return func() error {
+			switch *action {
+			case "create":
+				return createMultisig(*required, *keys)
+			case "list":
+				return listMultisig()
+			}
+			return errors.New("Unknown multisig action")
+		}
+	}},
+	{"swap", "atomic swaps between chains", map[string][]string{"action": {"initiate", "participate", "redeem", "refund", "audit", "secret"}}, func(fs *flag.FlagSet) func() error {
+		action := fs.String("action", "", "initiate, participate, redeem, refund, audit or secret")
+		rpc := fs.String("rpc", rpcAddress, "node on the chain this step happens on")
+		dest := fs.String("dest", "", "address of the other party on this chain")
+		amount := fs.Int("amount", 0, "how much to lock in the contract")
+		lock := fs.Int("locktime", 0, "blocks until a refund is possible, defaults to 48 to initiate and 24 to participate")
+		hash := fs.String("hash", "", "secret hash from the initiator's contract")
+		contract := fs.String("contract", "", "contract output as txid:index")
+		secret := fs.String("secret", "", "secret to redeem with")
+		fee := fs.Int("fee", 0, "fee for redeeming or refunding")
+		return func() error {
+			// The initiator's lock has to be longer, so the participant still
+			// has time to use the revealed secret before a refund is possible
+			lockBlocks := *lock
+			if lockBlocks == 0 && *action == "initiate" {
+				lockBlocks = 48
+			} else if lockBlocks == 0 {
+				lockBlocks = 24
+			}
+			return swap(*action, *rpc, *dest, *amount, lockBlocks, *hash, *contract, *secret, *fee)
+		}
+	}},
+	{"faults", "slow down, drop or cut the node's messages to peers", map[string][]string{"action": {"set", "clear", "list"}}, func(fs *flag.FlagSet) func() error {
+		action := fs.String("action", "", "set, clear or list")
+		peer := fs.String("peer", "", "peer as in the node's peer list, every peer without its own faults if empty")
+		latency := fs.Uint64("latency", 0, "milliseconds added to every message")
+		jitter := fs.Uint64("jitter", 0, "random extra milliseconds up to this")
+		loss := fs.Float64("loss", 0, "chance of each message being dropped")
+		reorder := fs.Float64("reorder", 0, "chance of a relayed block or transaction being overtaken")
+		down := fs.Bool("down", false, "partition from the peer")
+		return func() error {
+			switch *action {
+			case "set":
+				return setFaults(&pb.LinkFaults{Peer: *peer, Latency: *latency, Jitter: *jitter,
+					Loss: *loss, Reorder: *reorder, Down: *down})
+			case "clear":
+				return clearFaults()
+			case "list":
+				return listFaults()
+			}
+			return errors.New("Unknown faults action")
+		}
+	}},
+	{"log", "show or change how much each of the node's subsystems logs", map[string][]string{"action": {"set", "list"},
+		"subsystem": {"chain", "mempool", "p2p", "miner", "wallet", "node"}, "level": {"debug", "info", "warn", "error"}}, func(fs *flag.FlagSet) func() error {
+		action := fs.String("action", "list", "set or list")
+		subsystem := fs.String("subsystem", "", "chain, mempool, p2p, miner, wallet or node, every subsystem if empty")
+		level := fs.String("level", "", "debug, info, warn or error")
+		return func() error {
+			switch *action {
+			case "set":
+				return setLogLevel(*subsystem, *level)
+			case "list":
+				return listLogLevels()
+			}
+			return errors.New("Unknown log action")
+		}
+	}},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// Every command takes -output, defaulting to the client's
+func (cmd *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.String("output", outputFormat, "text, json or table")
+	return fs
+}
+
+func (cmd *command) printHelp() {
+	fmt.Printf("%s: %s\n", cmd.name, cmd.usage)
+	fs := cmd.flagSet()
+	cmd.flags(fs)
+	fs.SetOutput(os.Stdout)
+	fs.PrintDefaults()
+	var names []string
+	for name := range cmd.choices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  -%s is one of %s\n", name, strings.Join(cmd.choices[name], ", "))
+	}
+}
+
+// Run a command given as its name then flags
+func runCommand(args []string) error {
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return fmt.Errorf("Unknown command %s, try help", args[0])
+	}
+	fs := cmd.flagSet()
+	run := cmd.flags(fs)
+	if err := fs.Parse(args[1:]); err == flag.ErrHelp {
+		cmd.printHelp()
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%v, see help %s", err, cmd.name)
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("Unexpected %s, flags look like -name=value", fs.Arg(0))
+	}
+	format := fs.Lookup("output").Value.String()
+	if err := checkOutputFormat(format); err != nil {
+		return err
+	}
+	defer func(previous string) { outputFormat = previous }(outputFormat)
+	outputFormat = format
+	return run()
+}
+
+func setNetwork(name string) error {
+	params, err := chain.GetChainParams(name)
+	if err != nil {
+		return err
 	}
-	fmt.Printf("%s\n", address.Address)
-	conn.Close()
+	network = params
+	return nil
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("State or send subcommand is required")
+	if env := os.Getenv("BITCOIN_RPC"); env != "" {
+		rpcAddress = env
+	}
+	flag.StringVar(&rpcAddress, "rpc", rpcAddress, "node to connect to as host:port, or set BITCOIN_RPC")
+	networkName := flag.String("network", os.Getenv("BITCOIN_NETWORK"), "mainnet, testnet or regtest, decides the address prefix and default port")
+	if env := os.Getenv("BITCOIN_OUTPUT"); env != "" {
+		outputFormat = env
+	}
+	flag.StringVar(&outputFormat, "output", outputFormat, "text, json or table for every command, or set BITCOIN_OUTPUT")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: client [flags] <command> [command flags], or client shell\n")
+		flag.PrintDefaults()
+		printCommands()
+	}
+	flag.Parse()
+	if err := checkOutputFormat(outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	stateCommand := flag.NewFlagSet("state", flag.ExitOnError)
-	sendCommand := flag.NewFlagSet("send", flag.ExitOnError)
-	newCommand := flag.NewFlagSet("new", flag.ExitOnError)
-	walletCommand := flag.NewFlagSet("wallet", flag.ExitOnError)
-	mineCommand := flag.NewFlagSet("mine", flag.ExitOnError)
-
-	getOp := stateCommand.String("get", "", "what you want to get")
-	sendAmount := sendCommand.Int("amount", 0, "how much to send")
-	sendDest := sendCommand.String("dest", "", "where to send")
-	newName := newCommand.String("name", "", "name of account")
-	walletGet := walletCommand.String("get", "", "get balance, pubkey etc.")
-	mineAction := mineCommand.String("action", "", "start/stop mining")
-
-	switch os.Args[1] {
-	case "state":
-		stateCommand.Parse(os.Args[2:])
-		fmt.Printf("get state of %v\n", *getOp)
-		switch *getOp {
-		case "transactions":
-			getTransactions()
-		case "blocks":
-			getBlocks()
-		default:
-			fmt.Println("Unknown get op")
-		}
-	case "send":
-		sendCommand.Parse(os.Args[2:])
-		fmt.Printf("send %v to %v\n", *sendAmount, *sendDest)
-		send(*sendAmount, *sendDest)
-	case "new":
-		// Create a new key pair
-		newCommand.Parse(os.Args[2:])
-		fmt.Println("New account:", *newName)
-		newAccount(*newName)
-	case "wallet":
-		walletCommand.Parse(os.Args[2:])
-		switch *walletGet {
-		case "balance":
-			getBalance()
-		case "address":
-			getAddress()
-		default:
-			fmt.Println("Unknown get op")
-		}
-	case "mine":
-		mineCommand.Parse(os.Args[2:])
-		switch *mineAction {
-		case "start":
-			startMining()
-		case "stop":
-			stopMining()
-		default:
-			fmt.Println("Unknown mine action")
+	if *networkName != "" {
+		if err := setNetwork(*networkName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
-	default:
-		flag.PrintDefaults()
+		if !isFlagSet("rpc") && os.Getenv("BITCOIN_RPC") == "" {
+			rpcAddress = "localhost:" + network.Port
+		}
+	}
+	args := flag.Args()
+	if len(args) < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	var err error
+	if args[0] == "shell" {
+		err = runShell(args[1:])
+	} else {
+		err = runCommand(args)
+	}
+	closeConnections()
+	// Off stdout so it can't be mistaken for output
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
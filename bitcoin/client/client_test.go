@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadRecipients(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	address := network.GetAddress(chain.GetPubKeyBytes(key))
+	write := func(name string, contents string) string {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	outputs, err := readRecipients(write("payouts.csv", "address,amount\n"+address+",5\n"+address+",7\n"))
+	if err != nil || len(outputs) != 2 || outputs[1].Value != 7 {
+		t.Fatalf("Read %v %v", outputs, err)
+	}
+	// Short or malformed addresses say where they are instead of panicking
+	for _, test := range []struct {
+		path  string
+		where string
+	}{
+		{write("payouts.csv", address+",5\n12,3\n"), "line 2"},
+		{write("payouts.csv", ",3\n"), "line 1"},
+		{write("payouts.json", `[{"address": "`+address+`", "amount": 1}, {"address": "x", "amount": 2}]`), "recipient 2"},
+	} {
+		if _, err := readRecipients(test.path); err == nil || !strings.Contains(err.Error(), test.where) {
+			t.Errorf("Expected an error at %s, got %v", test.where, err)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ctrl-C while typing, throws the line away
+var errInterrupted = errors.New("Interrupted")
+
+// Minimal readline for the shell: arrows and the usual emacs keys to
+// move around, up and down through history and tab to complete. Expects
+// the terminal in raw mode so it gets every key as it's pressed
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	history  []string
+	complete func(before string) []string // Candidates for the last word of what's before the cursor
+}
+
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(in), out: out}
+}
+
+// Editing state of the line being read
+type editLine struct {
+	e       *lineEditor
+	prompt  string
+	line    []rune
+	cursor  int
+	browsed int    // How far back in history we are, 0 for the line being typed
+	typed   string // What was being typed before going into history
+}
+
+func (l *editLine) redraw() {
+	fmt.Fprintf(l.e.out, "\r%s%s\x1b[K", l.prompt, string(l.line))
+	if back := len(l.line) - l.cursor; back > 0 {
+		fmt.Fprintf(l.e.out, "\x1b[%dD", back)
+	}
+}
+
+func (l *editLine) set(s string) {
+	l.line = []rune(s)
+	l.cursor = len(l.line)
+}
+
+func (l *editLine) insert(s string) {
+	r := []rune(s)
+	l.line = append(l.line[:l.cursor], append(r, l.line[l.cursor:]...)...)
+	l.cursor += len(r)
+}
+
+// Remove the runes from start up to the cursor
+func (l *editLine) deleteBack(start int) {
+	l.line = append(l.line[:start], l.line[l.cursor:]...)
+	l.cursor = start
+}
+
+func (l *editLine) browse(by int) {
+	to := l.browsed + by
+	if to < 0 || to > len(l.e.history) {
+		return
+	}
+	if l.browsed == 0 {
+		l.typed = string(l.line)
+	}
+	l.browsed = to
+	if to == 0 {
+		l.set(l.typed)
+	} else {
+		l.set(l.e.history[len(l.e.history)-to])
+	}
+}
+
+func (l *editLine) wordStart() int {
+	start := l.cursor
+	for start > 0 && l.line[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && l.line[start-1] != ' ' {
+		start--
+	}
+	return start
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func (l *editLine) tab() {
+	if l.e.complete == nil {
+		return
+	}
+	before := string(l.line[:l.cursor])
+	candidates := l.e.complete(before)
+	if len(candidates) == 0 {
+		return
+	}
+	word := before[strings.LastIndex(before, " ")+1:]
+	prefix := commonPrefix(candidates)
+	if len(candidates) == 1 && !strings.HasSuffix(prefix, "=") {
+		prefix += " "
+	}
+	if len(prefix) > len(word) {
+		l.insert(prefix[len(word):])
+		return
+	}
+	if len(candidates) > 1 {
+		fmt.Fprintf(l.e.out, "\n%s\n", strings.Join(candidates, "  "))
+	}
+}
+
+// After ESC, the arrow and home/end/delete keys
+func (l *editLine) escape() error {
+	r, _, err := l.e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return err
+	}
+	r, _, err = l.e.in.ReadRune()
+	if err != nil {
+		return err
+	}
+	// Some keys are a number then ~, e.g. delete is ESC [ 3 ~
+	if r >= '0' && r <= '9' {
+		for next := r; next != '~'; {
+			if next, _, err = l.e.in.ReadRune(); err != nil {
+				return err
+			}
+		}
+	}
+	switch r {
+	case 'A':
+		l.browse(1)
+	case 'B':
+		l.browse(-1)
+	case 'C':
+		if l.cursor < len(l.line) {
+			l.cursor++
+		}
+	case 'D':
+		if l.cursor > 0 {
+			l.cursor--
+		}
+	case 'H', '1', '7':
+		l.cursor = 0
+	case 'F', '4', '8':
+		l.cursor = len(l.line)
+	case '3':
+		if l.cursor < len(l.line) {
+			l.line = append(l.line[:l.cursor], l.line[l.cursor+1:]...)
+		}
+	}
+	return nil
+}
+
+// Read a line, io.EOF on Ctrl-D with nothing typed and errInterrupted on
+// Ctrl-C
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	l := &editLine{e: e, prompt: prompt}
+	l.redraw()
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\n")
+			return string(l.line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(l.line) == 0 {
+				fmt.Fprint(e.out, "\n")
+				return "", io.EOF
+			}
+			if l.cursor < len(l.line) {
+				l.line = append(l.line[:l.cursor], l.line[l.cursor+1:]...)
+			}
+		case 127, 8: // Backspace
+			if l.cursor > 0 {
+				l.deleteBack(l.cursor - 1)
+			}
+		case 1: // Ctrl-A
+			l.cursor = 0
+		case 5: // Ctrl-E
+			l.cursor = len(l.line)
+		case 2: // Ctrl-B
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case 6: // Ctrl-F
+			if l.cursor < len(l.line) {
+				l.cursor++
+			}
+		case 11: // Ctrl-K
+			l.line = l.line[:l.cursor]
+		case 21: // Ctrl-U
+			l.deleteBack(0)
+		case 23: // Ctrl-W
+			l.deleteBack(l.wordStart())
+		case 12: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 16: // Ctrl-P
+			l.browse(1)
+		case 14: // Ctrl-N
+			l.browse(-1)
+		case '\t':
+			l.tab()
+		case 27:
+			if err := l.escape(); err != nil {
+				return "", err
+			}
+		default:
+			if r >= ' ' {
+				l.insert(string(r))
+			}
+		}
+		l.redraw()
+	}
+}
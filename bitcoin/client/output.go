@@ -0,0 +1,653 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// How commands print what they get back. The JSON field names are
+// relied on by scripts, add fields but don't rename or remove them
+const (
+	OUTPUT_TEXT  = "text"
+	OUTPUT_JSON  = "json"
+	OUTPUT_TABLE = "table"
+)
+
+var OUTPUT_FORMATS = []string{OUTPUT_TEXT, OUTPUT_JSON, OUTPUT_TABLE}
+
+// Set with -output, globally or on each command
+var outputFormat = OUTPUT_TEXT
+
+func checkOutputFormat(format string) error {
+	for _, f := range OUTPUT_FORMATS {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown output %s, need %s", format, strings.Join(OUTPUT_FORMATS, ", "))
+}
+
+// A command's result, marshalled as is for json
+type printable interface {
+	text() string
+	table() [][]string // Header then rows
+}
+
+func show(p printable) error {
+	switch outputFormat {
+	case OUTPUT_JSON:
+		encoded, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+	case OUTPUT_TABLE:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, row := range p.table() {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+	default:
+		if text := p.text(); text != "" {
+			fmt.Println(text)
+		}
+	}
+	return nil
+}
+
+// Progress and chatter which isn't the result, kept off stdout unless
+// it's for people
+func info(a ...interface{}) {
+	if outputFormat == OUTPUT_TEXT {
+		fmt.Println(a...)
+	} else {
+		fmt.Fprintln(os.Stderr, a...)
+	}
+}
+
+// Two column table for a single result
+func fieldTable(fields ...string) [][]string {
+	rows := [][]string{{"FIELD", "VALUE"}}
+	for i := 0; i+1 < len(fields); i += 2 {
+		rows = append(rows, []string{fields[i], fields[i+1]})
+	}
+	return rows
+}
+
+type inputJSON struct {
+	TxID     string `json:"txid"`
+	Index    uint64 `json:"index"`
+	Sequence uint64 `json:"sequence"`
+}
+
+func newInputs(inputs []*pb.TXI) []inputJSON {
+	converted := make([]inputJSON, 0, len(inputs))
+	for _, input := range inputs {
+		converted = append(converted, inputJSON{hex.EncodeToString(input.TxID), input.Index, input.Sequence})
+	}
+	return converted
+}
+
+// Pays either a key, with address and pubKey set, or a locking script
+type outputJSON struct {
+	Index     int    `json:"index"`
+	Value     uint64 `json:"value"`
+	Address   string `json:"address,omitempty"`
+	PubKey    string `json:"pubKey,omitempty"`
+	Script    string `json:"script,omitempty"` // Opcodes and data, as send -script takes in hex
+	ScriptHex string `json:"scriptHex,omitempty"`
+}
+
+func newOutputs(outputs []*pb.TXO) []outputJSON {
+	converted := make([]outputJSON, 0, len(outputs))
+	for i, output := range outputs {
+		o := outputJSON{Index: i, Value: output.Value}
+		if len(output.LockingScript) != 0 {
+			o.Script = chain.GetScriptString(output.LockingScript)
+			o.ScriptHex = hex.EncodeToString(output.LockingScript)
+		} else {
+			o.Address = getAddressFromPubKey(output.ReceiverPubKey)
+			o.PubKey = hex.EncodeToString(output.ReceiverPubKey)
+		}
+		converted = append(converted, o)
+	}
+	return converted
+}
+
+type transactionJSON struct {
+	TxID     string       `json:"txid"`
+	Coinbase bool         `json:"coinbase"`
+	Height   uint64       `json:"height"` // Only set on coinbase transactions
+	LockTime uint64       `json:"lockTime"`
+	Value    uint64       `json:"value"` // Of all the outputs
+	Inputs   []inputJSON  `json:"inputs"`
+	Outputs  []outputJSON `json:"outputs"`
+	tx       *pb.Transaction
+}
+
+func newTransaction(tx *pb.Transaction) transactionJSON {
+	converted := transactionJSON{
+		TxID:     hex.EncodeToString(chain.GetTransactionHash(tx)),
+		Coinbase: len(tx.Vin) == 0,
+		Height:   tx.Height,
+		LockTime: tx.LockTime,
+		Inputs:   newInputs(tx.Vin),
+		Outputs:  newOutputs(tx.Vout),
+		tx:       tx}
+	for _, output := range tx.Vout {
+		converted.Value += output.Value
+	}
+	return converted
+}
+
+type transactionList []transactionJSON
+
+func (txs transactionList) text() string {
+	var lines []string
+	for _, tx := range txs {
+		lines = append(lines, chain.GetTransactionString(tx.tx))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (txs transactionList) table() [][]string {
+	rows := [][]string{{"TXID", "INPUTS", "OUTPUTS", "VALUE", "LOCKTIME"}}
+	for _, tx := range txs {
+		rows = append(rows, []string{tx.TxID, fmt.Sprint(len(tx.Inputs)), fmt.Sprint(len(tx.Outputs)),
+			fmt.Sprint(tx.Value), fmt.Sprint(tx.LockTime)})
+	}
+	return rows
+}
+
+type blockJSON struct {
+	Hash         string          `json:"hash"`
+	Height       uint64          `json:"height"`
+	PrevHash     string          `json:"prevHash"`
+	MerkleRoot   string          `json:"merkleRoot"`
+	Time         uint64          `json:"time"` // Seconds from epoch
+	Difficulty   uint32          `json:"difficulty"`
+	Nonce        uint32          `json:"nonce"`
+	Transactions transactionList `json:"transactions"`
+	block        *pb.Block
+}
+
+func newBlock(block *pb.Block) blockJSON {
+	converted := blockJSON{
+		Hash:         hex.EncodeToString(chain.GetBlockHash(block)),
+		Height:       block.Header.Height,
+		PrevHash:     hex.EncodeToString(block.Header.PrevBlockHash),
+		MerkleRoot:   hex.EncodeToString(block.Header.MerkleRoot),
+		Time:         block.Header.TimeStamp,
+		Difficulty:   block.Header.DifficultyTarget,
+		Nonce:        block.Header.Nonce,
+		Transactions: transactionList{},
+		block:        block}
+	for _, tx := range block.Transactions {
+		converted.Transactions = append(converted.Transactions, newTransaction(tx))
+	}
+	return converted
+}
+
+type blockList []blockJSON
+
+func (blocks blockList) text() string {
+	var lines []string
+	for _, block := range blocks {
+		lines = append(lines, chain.GetBlockString(block.block))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (blocks blockList) table() [][]string {
+	rows := [][]string{{"HEIGHT", "HASH", "TIME", "TXS"}}
+	for _, block := range blocks {
+		rows = append(rows, []string{fmt.Sprint(block.Height), block.Hash,
+			time.Unix(int64(block.Time), 0).UTC().Format(time.RFC3339), fmt.Sprint(len(block.Transactions))})
+	}
+	return rows
+}
+
+type balanceJSON struct {
+	Balance   uint64 `json:"balance"`
+	Spendable uint64 `json:"spendable"` // Excludes immature coinbase
+}
+
+func (b balanceJSON) text() string {
+	return fmt.Sprintf("%d (spendable %d)", b.Balance, b.Spendable)
+}
+
+func (b balanceJSON) table() [][]string {
+	return [][]string{{"BALANCE", "SPENDABLE"}, {fmt.Sprint(b.Balance), fmt.Sprint(b.Spendable)}}
+}
+
+// Of any address's confirmed transactions
+type addressBalanceJSON struct {
+	Balance      uint64 `json:"balance"`
+	UTXOs        uint64 `json:"utxos"`
+	Received     uint64 `json:"received"`
+	Sent         uint64 `json:"sent"`
+	Transactions uint64 `json:"transactions"`
+}
+
+func (b addressBalanceJSON) text() string {
+	return fmt.Sprintf("%d in %d outputs, received %d sent %d over %d transactions", b.Balance, b.UTXOs, b.Received, b.Sent, b.Transactions)
+}
+
+func (b addressBalanceJSON) table() [][]string {
+	return [][]string{{"BALANCE", "UTXOS", "RECEIVED", "SENT", "TXS"},
+		{fmt.Sprint(b.Balance), fmt.Sprint(b.UTXOs), fmt.Sprint(b.Received), fmt.Sprint(b.Sent), fmt.Sprint(b.Transactions)}}
+}
+
+// One transaction of an address's history, in if it paid the address
+// more than it spent from it
+type historyEntryJSON struct {
+	TxID          string `json:"txid"`
+	Direction     string `json:"direction"` // in or out
+	Amount        uint64 `json:"amount"`    // Net of received and sent
+	Received      uint64 `json:"received"`
+	Sent          uint64 `json:"sent"`
+	Height        uint64 `json:"height"` // 0 while unconfirmed
+	Confirmations uint64 `json:"confirmations"`
+	Time          uint64 `json:"time"`
+}
+
+func (entry historyEntryJSON) status() string {
+	switch entry.Confirmations {
+	case 0:
+		return "unconfirmed"
+	case 1:
+		return "1 confirmation"
+	}
+	return fmt.Sprintf("%d confirmations", entry.Confirmations)
+}
+
+type historyJSON struct {
+	Total        uint64             `json:"total"`
+	Received     uint64             `json:"received"`
+	Sent         uint64             `json:"sent"`
+	Transactions []historyEntryJSON `json:"transactions"`
+}
+
+func newHistory(history *pb.AddressHistory) historyJSON {
+	converted := historyJSON{history.Total, history.Received, history.Sent, []historyEntryJSON{}}
+	for _, entry := range history.Transactions {
+		e := historyEntryJSON{TxID: hex.EncodeToString(entry.TxID), Direction: "in", Amount: entry.Received - entry.Sent,
+			Received: entry.Received, Sent: entry.Sent, Height: entry.Height, Confirmations: entry.Confirmations, Time: entry.Time}
+		if entry.Sent > entry.Received {
+			e.Direction, e.Amount = "out", entry.Sent-entry.Received
+		}
+		converted.Transactions = append(converted.Transactions, e)
+	}
+	return converted
+}
+
+func (h historyJSON) text() string {
+	var lines []string
+	for _, entry := range h.Transactions {
+		lines = append(lines, fmt.Sprintf("%s %-3s %d %s", entry.TxID, entry.Direction, entry.Amount, entry.status()))
+	}
+	lines = append(lines, fmt.Sprintf("%d of %d transactions, received %d sent %d", len(h.Transactions), h.Total, h.Received, h.Sent))
+	return strings.Join(lines, "\n")
+}
+
+func (h historyJSON) table() [][]string {
+	rows := [][]string{{"TXID", "DIRECTION", "AMOUNT", "HEIGHT", "STATUS"}}
+	for _, entry := range h.Transactions {
+		rows = append(rows, []string{entry.TxID, entry.Direction, fmt.Sprint(entry.Amount), fmt.Sprint(entry.Height), entry.status()})
+	}
+	return rows
+}
+
+type utxoJSON struct {
+	TxID     string `json:"txid"`
+	Index    uint64 `json:"index"`
+	Value    uint64 `json:"value"`
+	Height   uint64 `json:"height"`
+	Coinbase bool   `json:"coinbase"`
+}
+
+type utxoList []utxoJSON
+
+func (utxos utxoList) text() string {
+	var lines []string
+	for _, utxo := range utxos {
+		lines = append(lines, fmt.Sprintf("%s:%d %d at height %d", utxo.TxID, utxo.Index, utxo.Value, utxo.Height))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (utxos utxoList) table() [][]string {
+	rows := [][]string{{"TXID", "INDEX", "VALUE", "HEIGHT", "COINBASE"}}
+	for _, utxo := range utxos {
+		rows = append(rows, []string{utxo.TxID, fmt.Sprint(utxo.Index), fmt.Sprint(utxo.Value), fmt.Sprint(utxo.Height), fmt.Sprint(utxo.Coinbase)})
+	}
+	return rows
+}
+
+type addressJSON struct {
+	Address string `json:"address"`
+}
+
+func (a addressJSON) text() string {
+	return a.Address
+}
+
+func (a addressJSON) table() [][]string {
+	return [][]string{{"ADDRESS"}, {a.Address}}
+}
+
+type peerJSON struct {
+	Address string `json:"address"` // host:port
+}
+
+type peerList []peerJSON
+
+func (peers peerList) text() string {
+	var lines []string
+	for _, peer := range peers {
+		lines = append(lines, peer.Address)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (peers peerList) table() [][]string {
+	rows := [][]string{{"PEER"}}
+	for _, peer := range peers {
+		rows = append(rows, []string{peer.Address})
+	}
+	return rows
+}
+
+type miningStatusJSON struct {
+	State       string `json:"state"`
+	BlocksMined uint64 `json:"blocksMined"`
+	StartTime   uint64 `json:"startTime"` // Seconds from epoch, 0 if never started
+	Height      uint64 `json:"height"`
+	Tip         string `json:"tip"`
+}
+
+func (s miningStatusJSON) text() string {
+	lines := []string{"State: " + s.State}
+	if s.StartTime != 0 {
+		lines = append(lines, fmt.Sprintf("Mined %d blocks since %v", s.BlocksMined, time.Unix(int64(s.StartTime), 0)))
+	}
+	lines = append(lines, fmt.Sprintf("Tip %s at height %d", s.Tip, s.Height))
+	return strings.Join(lines, "\n")
+}
+
+func (s miningStatusJSON) table() [][]string {
+	return [][]string{{"STATE", "HEIGHT", "TIP", "MINED", "SINCE"},
+		{s.State, fmt.Sprint(s.Height), s.Tip, fmt.Sprint(s.BlocksMined), fmt.Sprint(s.StartTime)}}
+}
+
+type generatedJSON struct {
+	Hashes []string `json:"hashes"`
+}
+
+func (g generatedJSON) text() string {
+	return strings.Join(g.Hashes, "\n")
+}
+
+func (g generatedJSON) table() [][]string {
+	rows := [][]string{{"HASH"}}
+	for _, hash := range g.Hashes {
+		rows = append(rows, []string{hash})
+	}
+	return rows
+}
+
+type sentJSON struct {
+	TxID   string      `json:"txid"`
+	Fee    uint64      `json:"fee"`
+	Change uint64      `json:"change"`
+	Inputs []inputJSON `json:"inputs"`
+	sent   *pb.TransactionSent
+	funded bool // By coin selection, so show what it picked
+}
+
+func newSent(sent *pb.TransactionSent, funded bool) sentJSON {
+	return sentJSON{hex.EncodeToString(sent.TxID), sent.Fee, sent.Change, newInputs(sent.Inputs), sent, funded}
+}
+
+func (s sentJSON) text() string {
+	if !s.funded {
+		return fmt.Sprintf("Sent transaction %s fee %d", s.TxID, s.Fee)
+	}
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("Sent transaction %s fee %d change %d\nInputs:", s.TxID, s.Fee, s.Change))
+	for _, input := range s.sent.Inputs {
+		buf.WriteString(chain.GetTXIString(input) + "\n")
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (s sentJSON) table() [][]string {
+	return [][]string{{"TXID", "FEE", "CHANGE", "INPUTS"},
+		{s.TxID, fmt.Sprint(s.Fee), fmt.Sprint(s.Change), fmt.Sprint(len(s.Inputs))}}
+}
+
+type faultsJSON struct {
+	Peer    string  `json:"peer"` // Empty for every peer without its own
+	Latency uint64  `json:"latency"`
+	Jitter  uint64  `json:"jitter"`
+	Loss    float64 `json:"loss"`
+	Reorder float64 `json:"reorder"`
+	Down    bool    `json:"down"`
+}
+
+type faultsList []faultsJSON
+
+func (links faultsList) text() string {
+	var lines []string
+	for _, faults := range links {
+		peer := faults.Peer
+		if peer == "" {
+			peer = "every other peer"
+		}
+		lines = append(lines, fmt.Sprintf("%s: latency %dms jitter %dms loss %v reorder %v down %v",
+			peer, faults.Latency, faults.Jitter, faults.Loss, faults.Reorder, faults.Down))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (links faultsList) table() [][]string {
+	rows := [][]string{{"PEER", "LATENCY", "JITTER", "LOSS", "REORDER", "DOWN"}}
+	for _, faults := range links {
+		rows = append(rows, []string{faults.Peer, fmt.Sprint(faults.Latency), fmt.Sprint(faults.Jitter),
+			fmt.Sprint(faults.Loss), fmt.Sprint(faults.Reorder), fmt.Sprint(faults.Down)})
+	}
+	return rows
+}
+
+type logLevelJSON struct {
+	Subsystem string `json:"subsystem"`
+	Level     string `json:"level"`
+}
+
+type logLevelList []logLevelJSON
+
+func newLogLevels(reply *pb.LogLevels) logLevelList {
+	levels := logLevelList{}
+	for _, level := range reply.Levels {
+		levels = append(levels, logLevelJSON{level.Subsystem, level.Level})
+	}
+	return levels
+}
+
+func (levels logLevelList) text() string {
+	var lines []string
+	for _, level := range levels {
+		lines = append(lines, level.Subsystem+": "+level.Level)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (levels logLevelList) table() [][]string {
+	rows := [][]string{{"SUBSYSTEM", "LEVEL"}}
+	for _, level := range levels {
+		rows = append(rows, []string{level.Subsystem, level.Level})
+	}
+	return rows
+}
+
+type multisigJSON struct {
+	Address  string   `json:"address"` // Hex locking script
+	Required uint32   `json:"required"`
+	Keys     []string `json:"keys"` // Addresses
+	Balance  uint64   `json:"balance"`
+	address  *pb.MultisigAddress
+}
+
+func newMultisig(address *pb.MultisigAddress) multisigJSON {
+	converted := multisigJSON{Address: address.Address, Required: address.Required, Keys: []string{},
+		Balance: address.Balance, address: address}
+	for _, pubKey := range address.PubKeys {
+		converted.Keys = append(converted.Keys, getAddressFromPubKey(pubKey))
+	}
+	return converted
+}
+
+type multisigList []multisigJSON
+
+func (addresses multisigList) text() string {
+	var lines []string
+	for _, address := range addresses {
+		lines = append(lines, getMultisigString(address.address))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (addresses multisigList) table() [][]string {
+	rows := [][]string{{"ADDRESS", "REQUIRED", "KEYS", "BALANCE"}}
+	for _, address := range addresses {
+		rows = append(rows, []string{address.Address, fmt.Sprint(address.Required), fmt.Sprint(len(address.Keys)),
+			fmt.Sprint(address.Balance)})
+	}
+	return rows
+}
+
+type htlcJSON struct {
+	Contract  string `json:"contract"` // txid:index, as swap -contract takes
+	Value     uint64 `json:"value"`
+	Hash      string `json:"hash"`
+	Recipient string `json:"recipient"`
+	Refund    string `json:"refund"`
+	LockTime  uint64 `json:"lockTime"` // Height a refund is possible after
+	SpentBy   string `json:"spentBy"`
+	Secret    string `json:"secret"` // Only once revealed, or to the initiator
+	contract  *pb.HTLC
+	initiated bool // We made the secret, so warn about keeping it
+}
+
+func newHTLC(contract *pb.HTLC) htlcJSON {
+	return htlcJSON{
+		Contract:  fmt.Sprintf("%x:%d", contract.TxID, contract.Index),
+		Value:     contract.Value,
+		Hash:      hex.EncodeToString(contract.Hash),
+		Recipient: getAddressFromPubKey(contract.RecipientPubKey),
+		Refund:    getAddressFromPubKey(contract.RefundPubKey),
+		LockTime:  contract.LockTime,
+		SpentBy:   hex.EncodeToString(contract.SpentBy),
+		Secret:    hex.EncodeToString(contract.Preimage),
+		contract:  contract}
+}
+
+func (h htlcJSON) text() string {
+	if h.initiated {
+		return fmt.Sprintf("Secret (keep this private until you redeem): %s\n%s", h.Secret, getHTLCString(h.contract))
+	}
+	return getHTLCString(h.contract)
+}
+
+func (h htlcJSON) table() [][]string {
+	return fieldTable("contract", h.Contract, "value", fmt.Sprint(h.Value), "hash", h.Hash,
+		"recipient", h.Recipient, "refund", h.Refund, "lockTime", fmt.Sprint(h.LockTime),
+		"spentBy", h.SpentBy, "secret", h.Secret)
+}
+
+type secretJSON struct {
+	Secret string `json:"secret"` // Empty until revealed
+}
+
+func (s secretJSON) text() string {
+	if s.Secret == "" {
+		return "Secret not revealed yet"
+	}
+	return s.Secret
+}
+
+func (s secretJSON) table() [][]string {
+	return [][]string{{"SECRET"}, {s.Secret}}
+}
+
+type signatureJSON struct {
+	PubKey  string `json:"pubKey"`
+	Address string `json:"address"`
+}
+
+type partialTransactionJSON struct {
+	Transaction transactionJSON `json:"transaction"`
+	Spending    []outputJSON    `json:"spending"` // What each input spends
+	Signatures  []signatureJSON `json:"signatures"`
+	psbt        *pb.PartialTransaction
+}
+
+func newPartialTransaction(psbt *pb.PartialTransaction) partialTransactionJSON {
+	converted := partialTransactionJSON{Transaction: newTransaction(psbt.Transaction),
+		Spending: newOutputs(psbt.Spending), Signatures: []signatureJSON{}, psbt: psbt}
+	for _, signature := range psbt.Signatures {
+		converted.Signatures = append(converted.Signatures,
+			signatureJSON{hex.EncodeToString(signature.PubKey), getAddressFromPubKey(signature.PubKey)})
+	}
+	return converted
+}
+
+func (p partialTransactionJSON) text() string {
+	return getPartialTransactionString(p.psbt)
+}
+
+func (p partialTransactionJSON) table() [][]string {
+	var spending uint64
+	for _, output := range p.Spending {
+		spending += output.Value
+	}
+	return fieldTable("txid", p.Transaction.TxID, "inputs", fmt.Sprint(len(p.Transaction.Inputs)),
+		"spending", fmt.Sprint(spending), "outputs", fmt.Sprint(len(p.Transaction.Outputs)),
+		"value", fmt.Sprint(p.Transaction.Value), "signatures", fmt.Sprint(len(p.Signatures)))
+}
+
+// A partial transaction written out for the next step
+type encodedJSON struct {
+	PSBT string `json:"psbt"` // Base64
+	File string `json:"file"` // Where it was written, empty if only printed
+}
+
+func (e encodedJSON) text() string {
+	if e.File != "" {
+		return ""
+	}
+	return e.PSBT
+}
+
+func (e encodedJSON) table() [][]string {
+	return fieldTable("file", e.File, "psbt", e.PSBT)
+}
+
+// For commands with nothing to say on success, so json still gets a
+// document
+type doneJSON struct{}
+
+func (doneJSON) text() string {
+	return ""
+}
+
+func (doneJSON) table() [][]string {
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Field names of a marshalled object, so schema changes show up here
+func keys(t *testing.T, v interface{}) []string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSchemas(t *testing.T) {
+	pubKey := make([]byte, 64)
+	pubKey[63] = 1
+	coinbase := &pb.Transaction{Vout: []*pb.TXO{{ReceiverPubKey: pubKey, Value: 10}}, Height: 2}
+	spend := &pb.Transaction{
+		Vin:  []*pb.TXI{{TxID: chain.GetTransactionHash(coinbase), Index: 0}},
+		Vout: []*pb.TXO{{LockingScript: []byte{chain.OP_RETURN}, Value: 4}, {ReceiverPubKey: pubKey, Value: 5}}}
+	block := &pb.Block{Header: &pb.BlockHeader{PrevBlockHash: make([]byte, 32), Height: 2},
+		Transactions: []*pb.Transaction{coinbase, spend}}
+	converted := newBlock(block)
+	history := newHistory(&pb.AddressHistory{Total: 2, Received: 15, Sent: 10, Transactions: []*pb.AddressTransaction{
+		{TxID: chain.GetTransactionHash(spend), Received: 5, Sent: 10},
+		{TxID: chain.GetTransactionHash(coinbase), Height: 2, Confirmations: 1, Received: 10}}})
+	var tests = []struct {
+		name   string
+		value  interface{}
+		fields []string
+	}{
+		{"block", converted, []string{"difficulty", "hash", "height", "merkleRoot", "nonce", "prevHash", "time", "transactions"}},
+		{"transaction", converted.Transactions[1], []string{"coinbase", "height", "inputs", "lockTime", "outputs", "txid", "value"}},
+		{"input", converted.Transactions[1].Inputs[0], []string{"index", "sequence", "txid"}},
+		{"script output", converted.Transactions[1].Outputs[0], []string{"index", "script", "scriptHex", "value"}},
+		{"key output", converted.Transactions[1].Outputs[1], []string{"address", "index", "pubKey", "value"}},
+		{"balance", balanceJSON{20, 10}, []string{"balance", "spendable"}},
+		{"address balance", addressBalanceJSON{}, []string{"balance", "received", "sent", "transactions", "utxos"}},
+		{"history", history, []string{"received", "sent", "total", "transactions"}},
+		{"history entry", history.Transactions[0], []string{"amount", "confirmations", "direction", "height", "received", "sent", "time", "txid"}},
+		{"peer", peerJSON{"10.0.0.2:8333"}, []string{"address"}},
+		{"log level", logLevelJSON{"p2p", "debug"}, []string{"level", "subsystem"}},
+		{"mining status", miningStatusJSON{}, []string{"blocksMined", "height", "startTime", "state", "tip"}},
+		{"sent", newSent(&pb.TransactionSent{}, true), []string{"change", "fee", "inputs", "txid"}},
+	}
+	for _, test := range tests {
+		if fields := keys(t, test.value); !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("%s has fields %v, want %v", test.name, fields, test.fields)
+		}
+	}
+	if !converted.Transactions[0].Coinbase || len(converted.Transactions[0].Inputs) != 0 || converted.Transactions[1].Value != 9 {
+		t.Errorf("Transactions converted wrong %+v", converted.Transactions)
+	}
+	if spent := history.Transactions[0]; spent.Direction != "out" || spent.Amount != 5 || spent.status() != "unconfirmed" {
+		t.Errorf("Spending more than the change is outgoing %+v", spent)
+	}
+	if mined := history.Transactions[1]; mined.Direction != "in" || mined.Amount != 10 || mined.status() != "1 confirmation" {
+		t.Errorf("Coinbase is incoming %+v", mined)
+	}
+	if converted.Transactions[1].Outputs[0].Script != "OP_RETURN" {
+		t.Errorf("Script should be shown as opcodes, got %s", converted.Transactions[1].Outputs[0].Script)
+	}
+	// Empty lists stay lists, not null
+	if encoded, _ := json.Marshal(newSent(&pb.TransactionSent{}, false)); !json.Valid(encoded) ||
+		string(encoded) != `{"txid":"","fee":0,"change":0,"inputs":[]}` {
+		t.Errorf("Sent with no inputs is %s", encoded)
+	}
+}
+
+func TestTables(t *testing.T) {
+	rows := blockList{newBlock(chain.REGTEST.GenesisBlock())}.table()
+	if len(rows) != 2 || rows[0][0] != "HEIGHT" || rows[1][1] != hex.EncodeToString(chain.REGTEST.GenesisHash()) {
+		t.Errorf("Block table is %v", rows)
+	}
+	if text := (balanceJSON{20, 10}).text(); text != "20 (spendable 10)" {
+		t.Errorf("Balance text changed to %q", text)
+	}
+	for _, format := range OUTPUT_FORMATS {
+		if err := checkOutputFormat(format); err != nil {
+			t.Error(err)
+		}
+	}
+	if checkOutputFormat("xml") == nil {
+		t.Error("xml isn't an output format")
+	}
+}
@@ -0,0 +1,430 @@
+// Interactive shell, keeps connections to nodes open between commands
+// and switches between them with use:
+//
+//	go run ./client -rpc=localhost:18500 shell
+//	localhost:18500> mine -action=status
+//	localhost:18500> use 18501
+//	localhost:18501> @18500 wallet -get=balance
+//
+// Commands and flags are the same as on the command line. With a -file,
+// or when stdin isn't a terminal, it runs the commands it reads one per
+// line, stopping at the first that fails.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	HISTORY_FILE = ".bitcoin_history" // In the home directory
+	HISTORY_SIZE = 1000
+	PING_TIMEOUT = 2 * time.Second
+)
+
+var errExit = errors.New("Exit")
+
+// What the shell does itself, on top of the commands
+var builtins = [][2]string{
+	{"help [command]", "list commands, or the flags of one"},
+	{"use <host:port|port>", "switch to another node, a bare port is on localhost"},
+	{"@<node> <command>", "run one command on another node without switching"},
+	{"nodes", "nodes used so far, their height and mining state"},
+	{"network [name]", "show or change the network addresses are for"},
+	{"output [format]", "show or change how commands print, text, json or table"},
+	{"history", "commands run so far"},
+	{"exit", "leave, as does Ctrl-D"},
+}
+
+type shell struct {
+	history     []string
+	historyFile string // Where history is saved between sessions, nowhere if empty
+}
+
+func printCommands() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Commands, help <command> for their flags:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.usage)
+	}
+	w.Flush()
+}
+
+func printBuiltins() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Shell:")
+	for _, builtin := range builtins {
+		fmt.Fprintf(w, "  %s\t%s\n", builtin[0], builtin[1])
+	}
+	w.Flush()
+}
+
+func help(args []string) error {
+	if len(args) == 0 {
+		printCommands()
+		printBuiltins()
+		return nil
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return fmt.Errorf("Unknown command %s", args[0])
+	}
+	cmd.printHelp()
+	return nil
+}
+
+// Split a line into words like a shell, quotes keep spaces in a word
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("Unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// host:port as given, or just a port on localhost
+func nodeAddress(node string) string {
+	if strings.HasPrefix(node, ":") {
+		return "localhost" + node
+	}
+	if !strings.Contains(node, ":") {
+		return "localhost:" + node
+	}
+	return node
+}
+
+// Quick look at a node, to see it's there when switching to it
+func ping(address string) string {
+	conn, err := connectTo(address)
+	if err != nil {
+		return err.Error()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), PING_TIMEOUT)
+	defer cancel()
+	status, err := pb.NewMinerClient(conn).GetMiningStatus(ctx, &pb.Empty{})
+	if err != nil {
+		return "not reachable"
+	}
+	return fmt.Sprintf("height %d, mining %s", status.Height, status.State)
+}
+
+// Every node used so far, which connections are kept open to
+func knownNodes() []string {
+	nodes := []string{rpcAddress}
+	for address := range connections {
+		if address != rpcAddress {
+			nodes = append(nodes, address)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+func (sh *shell) execute(line string) error {
+	args, err := splitArgs(line)
+	if err != nil || len(args) == 0 {
+		return err
+	}
+	if strings.HasPrefix(args[0], "@") {
+		if len(args) == 1 {
+			return fmt.Errorf("Need a command to run on %s", args[0][1:])
+		}
+		previous := rpcAddress
+		rpcAddress = nodeAddress(args[0][1:])
+		defer func() { rpcAddress = previous }()
+		args = args[1:]
+	}
+	switch args[0] {
+	case "help", "?":
+		return help(args[1:])
+	case "use":
+		if len(args) != 2 {
+			return errors.New("Use which node? use <host:port|port>")
+		}
+		rpcAddress = nodeAddress(args[1])
+		fmt.Printf("Using %s, %s\n", rpcAddress, ping(rpcAddress))
+		return nil
+	case "nodes":
+		for _, address := range knownNodes() {
+			current := " "
+			if address == rpcAddress {
+				current = "*"
+			}
+			fmt.Printf("%s %s %s\n", current, address, ping(address))
+		}
+		return nil
+	case "network":
+		if len(args) == 2 {
+			return setNetwork(args[1])
+		}
+		fmt.Println(network.Name)
+		return nil
+	case "output":
+		if len(args) == 2 {
+			if err := checkOutputFormat(args[1]); err != nil {
+				return err
+			}
+			outputFormat = args[1]
+			return nil
+		}
+		fmt.Println(outputFormat)
+		return nil
+	case "history":
+		for i, line := range sh.history {
+			fmt.Printf("%4d  %s\n", i+1, line)
+		}
+		return nil
+	case "exit", "quit":
+		return errExit
+	}
+	return runCommand(args)
+}
+
+// Flags of the command, with their values once there's an =
+func (cmd *command) completions(word string) []string {
+	var options []string
+	if eq := strings.Index(word, "="); eq > 0 {
+		name := strings.TrimLeft(word[:eq], "-")
+		values := cmd.choices[name]
+		if name == "output" {
+			values = OUTPUT_FORMATS
+		}
+		for _, value := range values {
+			options = append(options, word[:eq+1]+value)
+		}
+		return options
+	}
+	fs := cmd.flagSet()
+	cmd.flags(fs)
+	fs.VisitAll(func(f *flag.Flag) {
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			options = append(options, "-"+f.Name)
+		} else {
+			options = append(options, "-"+f.Name+"=")
+		}
+	})
+	return options
+}
+
+// Tab completion of the last word of before
+func (sh *shell) complete(before string) []string {
+	words := strings.Split(before, " ")
+	word := words[len(words)-1]
+	previous := strings.Fields(strings.Join(words[:len(words)-1], " "))
+	if len(previous) > 0 && strings.HasPrefix(previous[0], "@") {
+		previous = previous[1:]
+	}
+	var options []string
+	switch {
+	case len(previous) == 0 && strings.HasPrefix(word, "@"):
+		for _, node := range knownNodes() {
+			options = append(options, "@"+node)
+		}
+	case len(previous) == 0 || (previous[0] == "help" && len(previous) == 1):
+		for _, cmd := range commands {
+			options = append(options, cmd.name)
+		}
+		if len(previous) == 0 {
+			for _, builtin := range builtins {
+				if !strings.HasPrefix(builtin[0], "@") {
+					options = append(options, strings.Fields(builtin[0])[0])
+				}
+			}
+		}
+	case previous[0] == "use" && len(previous) == 1:
+		options = knownNodes()
+	case previous[0] == "output" && len(previous) == 1:
+		options = OUTPUT_FORMATS
+	case previous[0] == "network" && len(previous) == 1:
+		for name := range chain.NETWORKS {
+			options = append(options, name)
+		}
+	default:
+		if cmd := findCommand(previous[0]); cmd != nil {
+			options = cmd.completions(word)
+		}
+	}
+	var matches []string
+	for _, option := range options {
+		if strings.HasPrefix(option, word) {
+			matches = append(matches, option)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// Run the commands read from r, one per line, ignoring blank lines and
+// ones starting with #
+func (sh *shell) runScript(r io.Reader, keepGoing bool) error {
+	scanner := bufio.NewScanner(r)
+	failed := 0
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sh.history = append(sh.history, line)
+		err := sh.execute(line)
+		if err == errExit {
+			break
+		}
+		if err != nil && !keepGoing {
+			return fmt.Errorf("Line %d: %v", n, err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Line %d: %v\n", n, err)
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d commands failed", failed)
+	}
+	return nil
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+// The last HISTORY_SIZE lines of the history file, trimming it to those
+func loadHistory(path string) []string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	history := strings.Split(strings.TrimRight(string(contents), "\n"), "\n")
+	if len(history) > HISTORY_SIZE {
+		history = history[len(history)-HISTORY_SIZE:]
+		ioutil.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+	}
+	return history
+}
+
+func (sh *shell) remember(line string) {
+	if len(sh.history) != 0 && sh.history[len(sh.history)-1] == line {
+		return
+	}
+	sh.history = append(sh.history, line)
+	if len(sh.history) > HISTORY_SIZE {
+		sh.history = sh.history[1:]
+	}
+	if sh.historyFile == "" {
+		return
+	}
+	file, err := os.OpenFile(sh.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
+
+func (sh *shell) interactive() error {
+	sh.historyFile = historyPath()
+	sh.history = loadHistory(sh.historyFile)
+	editor := newLineEditor(os.Stdin, os.Stdout)
+	editor.complete = sh.complete
+	fmt.Printf("Using %s, %s\nhelp lists commands, exit or Ctrl-D to leave\n", rpcAddress, ping(rpcAddress))
+	for {
+		editor.history = sh.history
+		restore, err := makeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		line, err := editor.readLine(rpcAddress + "> ")
+		restore()
+		if err == io.EOF {
+			return nil
+		}
+		if err == errInterrupted {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		sh.remember(line)
+		if err := sh.execute(line); err == errExit {
+			return nil
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+func runShell(args []string) error {
+	fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+	file := fs.String("file", "", "run the commands in this file, one per line")
+	keepGoing := fs.Bool("keep-going", false, "in a script, carry on after a command fails")
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	sh := &shell{}
+	if *file != "" {
+		script, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer script.Close()
+		return sh.runScript(script, *keepGoing)
+	}
+	if !isTerminal(int(os.Stdin.Fd())) {
+		return sh.runScript(os.Stdin, *keepGoing)
+	}
+	return sh.interactive()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/nodetest"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	var tests = []struct {
+		line string
+		args []string
+	}{
+		{"  mine   -action=start ", []string{"mine", "-action=start"}},
+		{`new -name="alice smith"`, []string{"new", "-name=alice smith"}},
+		{`send -file='my payouts.csv' -fee=1`, []string{"send", "-file=my payouts.csv", "-fee=1"}},
+		{`keygen -out=a\ b.pem`, []string{"keygen", "-out=a b.pem"}},
+		{"", nil},
+	}
+	for _, test := range tests {
+		args, err := splitArgs(test.line)
+		if err != nil || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("Split %q into %q, %v", test.line, args, err)
+		}
+	}
+	if _, err := splitArgs(`new -name="alice`); err == nil {
+		t.Error("Unterminated quote should be an error")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	sh := &shell{}
+	var tests = []struct {
+		before string
+		want   []string
+	}{
+		{"m", []string{"mine", "multisig"}},
+		{"he", []string{"help"}},
+		{"help ge", []string{"generate"}},
+		{"mine -a", []string{"-action="}},
+		{"mine -action=sta", []string{"-action=start", "-action=status"}},
+		{"faults -action=set -do", []string{"-down"}},
+		{"@localhost:1 wallet -get=b", []string{"-get=balance"}},
+		{"network reg", []string{"regtest"}},
+		{"nope -", nil},
+	}
+	for _, test := range tests {
+		if got := sh.complete(test.before); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Completed %q to %q, want %q", test.before, got, test.want)
+		}
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	var tests = []struct {
+		name string
+		keys string
+		want string
+	}{
+		{"completion", "mi\t-a\tsta\tt\t\r", "mine -action=status "},
+		{"history", "\x1b[A\x1b[A\x1b[B\r", "wallet -get=balance"},
+		{"back to typed line", "gen\x1b[A\x1b[B\r", "gen"},
+		{"insert", "ab\x1b[Dc\r", "acb"},
+		{"home and end", "bc\x01a\x05d\r", "abcd"},
+		{"delete", "abc\x1b[D\x1b[D\x1b[3~\r", "ac"},
+		{"kill line", "xyz\x15ok\r", "ok"},
+		{"kill word", "mine -action\x17\r", "mine "},
+	}
+	for _, test := range tests {
+		editor := newLineEditor(strings.NewReader(test.keys), ioutil.Discard)
+		editor.history = []string{"state -get=blocks", "wallet -get=balance"}
+		editor.complete = (&shell{}).complete
+		line, err := editor.readLine("> ")
+		if err != nil || line != test.want {
+			t.Errorf("%s: read %q, %v", test.name, line, err)
+		}
+	}
+	editor := newLineEditor(strings.NewReader("\x04"), ioutil.Discard)
+	if _, err := editor.readLine("> "); err != io.EOF {
+		t.Errorf("Ctrl-D on an empty line should be EOF, got %v", err)
+	}
+	editor = newLineEditor(strings.NewReader("abc\x03"), ioutil.Discard)
+	if _, err := editor.readLine("> "); err != errInterrupted {
+		t.Errorf("Ctrl-C should interrupt, got %v", err)
+	}
+}
+
+func TestScript(t *testing.T) {
+	network := nodetest.NewNetwork(t, nodetest.Line(2))
+	// Nodes are only reachable in memory, so hand the shell their connections
+	for _, n := range network.Nodes {
+		connections["node:"+n.IP] = n.Client()
+	}
+	defer func(previous string) {
+		rpcAddress = previous
+		for address := range connections {
+			delete(connections, address)
+		}
+	}(rpcAddress)
+	sh := &shell{}
+	script := `
+# Mine on the first node then check on the second without switching
+use node:10.0.0.1
+generate -blocks=2
+@node:10.0.0.2 mine -action=status
+`
+	if err := sh.runScript(strings.NewReader(script), false); err != nil {
+		t.Fatal(err)
+	}
+	if rpcAddress != "node:10.0.0.1" {
+		t.Errorf("Should still be using the first node, got %s", rpcAddress)
+	}
+	height := network.Nodes[0].Height()
+	if !network.WaitForHeight(height) {
+		t.Error("Generated blocks didn't reach both nodes")
+	}
+	err := sh.runScript(strings.NewReader("generate -blocks=1\nmine -action=dance\ngenerate -blocks=1\n"), false)
+	if err == nil || !strings.HasPrefix(err.Error(), "Line 2") {
+		t.Errorf("Script should stop at line 2, got %v", err)
+	}
+	if network.Nodes[0].Height() != height+1 {
+		t.Errorf("Nothing after the failed line should run, height is %d", network.Nodes[0].Height())
+	}
+	err = sh.runScript(strings.NewReader("mine -action=dance\ngenerate -blocks=1\n"), true)
+	if err == nil || network.Nodes[0].Height() != height+2 {
+		t.Errorf("Keep going should run the rest and still fail, got %v at height %d", err, network.Nodes[0].Height())
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// No line editing here, the shell reads plain lines instead
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("Line editing isn't supported on this platform")
+}
@@ -0,0 +1,240 @@
+// Node settings. Each one has a default which can be overridden by the
+// config file, then by an environment variable, then by a flag, so a
+// shared file can be tweaked per node, e.g. to run several on one host:
+//
+//	BITCOIN_LISTEN=:8334 ./bitcoin -config=devnet.toml -datadir=node2
+//
+// The config file is TOML with the same names as the flags, using
+// underscores instead of dashes. Environment variables are the flag
+// name upper cased with a BITCOIN_ prefix. The network picks the chain
+// parameters, which can be overridden individually for private networks.
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	"github.com/connorwstein/Blockchain/bitcoin/p2p"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	DEFAULT_NETWORK    = "mainnet"
+	DEFAULT_PEERS_FILE = "networks.txt"
+	DEFAULT_PEER_CHECK = 2000 // Milliseconds between looking for new peers
+	DEFAULT_MINE_SPEED = 20   // Milliseconds between nonce increments
+	ENV_PREFIX         = "BITCOIN_"
+)
+
+type Config struct {
+	Network   string   `toml:"network"`
+	Listen    string   `toml:"listen"`     // Address the gRPC server listens on, defaults to the network's port
+	Peers     []string `toml:"peers"`      // Seeds as host or host:port, the port defaults to the network's
+	PeersFile string   `toml:"peers_file"` // More seeds, one per line
+	DataDir   string   `toml:"datadir"`    // Where blocks are stored, memory only if empty
+	MiningKey string   `toml:"mining_key"` // PEM key file to mine to instead of a new account
+	PeerCheck uint64   `toml:"peer_check"`
+	MineSpeed uint64   `toml:"mine_speed"`
+	Rest      string   `toml:"rest"` // Address the HTTP/JSON gateway listens on, off if empty
+	// Bearer token for the gateway's spending and mining routes, which are
+	// off if empty
+	RestToken string `toml:"rest_token"`
+	// Index every address as blocks come in, instead of scanning the chain
+	// for each lookup
+	AddressIndex bool `toml:"address_index"`
+	// Address Prometheus can scrape /metrics from, off if empty
+	Metrics string `toml:"metrics"`
+	// e.g. "info" or "warn,chain=debug" for one subsystem
+	LogLevel  string `toml:"log_level"`
+	LogFormat string `toml:"log_format"` // console or json
+	// Override the network's parameters, every node on a network has to
+	// agree on these. Empty or 0 keeps the network's value
+	Target           string `toml:"target"` // Hex, the easiest and starting target
+	BlockReward      uint64 `toml:"block_reward"`
+	HalvingInterval  uint64 `toml:"halving_interval"`
+	CoinbaseMaturity uint64 `toml:"coinbase_maturity"`
+	// Injected into messages sent to peers for testing, only from the file
+	Faults []p2p.LinkFaults `toml:"fault"`
+}
+
+func Default() *Config {
+	return &Config{
+		Network:   DEFAULT_NETWORK,
+		Listen:    ":" + chain.MAINNET.Port,
+		PeersFile: DEFAULT_PEERS_FILE,
+		PeerCheck: DEFAULT_PEER_CHECK,
+		MineSpeed: DEFAULT_MINE_SPEED,
+		LogLevel:  logging.DEFAULT_LEVEL,
+		LogFormat: logging.DEFAULT_FORMAT,
+	}
+}
+
+// A setting which can come from a flag or the environment
+type option struct {
+	name  string
+	usage string
+	set   func(config *Config, value string) error
+	// Flags which can be given without a value to turn them on
+	boolean bool
+}
+
+func stringOption(name string, usage string, field func(*Config) *string) option {
+	return option{name: name, usage: usage, set: func(config *Config, value string) error {
+		*field(config) = value
+		return nil
+	}}
+}
+
+func uintOption(name string, usage string, field func(*Config) *uint64) option {
+	return option{name: name, usage: usage, set: func(config *Config, value string) error {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid %s %q, need a number", name, value))
+		}
+		*field(config) = n
+		return nil
+	}}
+}
+
+func boolOption(name string, usage string, field func(*Config) *bool) option {
+	return option{name, usage, func(config *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid %s %q, need true or false", name, value))
+		}
+		*field(config) = b
+		return nil
+	}, true}
+}
+
+var options = []option{
+	stringOption("network", "mainnet, testnet or regtest (default "+DEFAULT_NETWORK+")", func(c *Config) *string { return &c.Network }),
+	stringOption("listen", "address to listen on (default the network's port)", func(c *Config) *string { return &c.Listen }),
+	{name: "peers", usage: "comma separated seeds as host or host:port", set: func(config *Config, value string) error {
+		config.Peers = nil
+		for _, peer := range strings.Split(value, ",") {
+			if peer = strings.TrimSpace(peer); peer != "" {
+				config.Peers = append(config.Peers, peer)
+			}
+		}
+		return nil
+	}},
+	stringOption("peers-file", "file with more seeds, one per line (default "+DEFAULT_PEERS_FILE+")", func(c *Config) *string { return &c.PeersFile }),
+	stringOption("rest", "address to serve the HTTP/JSON gateway on, e.g. :8080, off if empty", func(c *Config) *string { return &c.Rest }),
+	stringOption("rest-token", "bearer token for the gateway's wallet, mining and broadcast routes, off if empty", func(c *Config) *string { return &c.RestToken }),
+	stringOption("metrics", "address to serve Prometheus metrics on at /metrics, e.g. :9100, off if empty", func(c *Config) *string { return &c.Metrics }),
+	stringOption("log-level", "debug, info, warn or error, then any per subsystem, e.g. warn,p2p=debug (default "+logging.DEFAULT_LEVEL+")", func(c *Config) *string { return &c.LogLevel }),
+	stringOption("log-format", logging.FORMAT_CONSOLE+" or "+logging.FORMAT_JSON+" (default "+logging.DEFAULT_FORMAT+")", func(c *Config) *string { return &c.LogFormat }),
+	boolOption("address-index", "index every address for fast history, balance and UTXO lookups", func(c *Config) *bool { return &c.AddressIndex }),
+	stringOption("datadir", "directory to store the chain in, kept in memory only if empty", func(c *Config) *string { return &c.DataDir }),
+	stringOption("mining-key", "PEM key file to mine to, e.g. from the client's keygen", func(c *Config) *string { return &c.MiningKey }),
+	uintOption("peer-check", "milliseconds between looking for new peers", func(c *Config) *uint64 { return &c.PeerCheck }),
+	uintOption("mine-speed", "milliseconds between nonce increments", func(c *Config) *uint64 { return &c.MineSpeed }),
+	stringOption("target", "hex easiest and starting difficulty target", func(c *Config) *string { return &c.Target }),
+	uintOption("block-reward", "subsidy of the first blocks", func(c *Config) *uint64 { return &c.BlockReward }),
+	uintOption("halving-interval", "blocks between the subsidy halving", func(c *Config) *uint64 { return &c.HalvingInterval }),
+	uintOption("coinbase-maturity", "confirmations before mined coin can be spent", func(c *Config) *uint64 { return &c.CoinbaseMaturity }),
+}
+
+func envName(name string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// Build the config from the command line arguments (without the program
+// name), the config file they or BITCOIN_CONFIG point to and the environment
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("bitcoin", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv(envName("config")), "TOML config file")
+	// Flags win over everything, so only apply them at the end
+	flags := make(map[string]string)
+	for _, opt := range options {
+		name := opt.name
+		set := func(value string) error {
+			flags[name] = value
+			return nil
+		}
+		if opt.boolean {
+			fs.BoolFunc(name, opt.usage, set)
+		} else {
+			fs.Func(name, opt.usage, set)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	config := Default()
+	config.Listen = ""
+	if *configFile != "" {
+		meta, err := toml.DecodeFile(*configFile, config)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) != 0 {
+			return nil, errors.New(fmt.Sprintf("Unknown setting %s in %s", undecoded[0], *configFile))
+		}
+	}
+	for _, opt := range options {
+		if value, ok := os.LookupEnv(envName(opt.name)); ok {
+			if err := opt.set(config, value); err != nil {
+				return nil, errors.New(fmt.Sprintf("%s: %v", envName(opt.name), err))
+			}
+		}
+	}
+	for _, opt := range options {
+		if value, ok := flags[opt.name]; ok {
+			if err := opt.set(config, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	params, err := config.Params()
+	if err != nil {
+		return nil, err
+	}
+	for _, faults := range config.Faults {
+		if err := faults.Check(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := logging.ParseLevels(config.LogLevel); err != nil {
+		return nil, err
+	}
+	if config.LogFormat != logging.FORMAT_CONSOLE && config.LogFormat != logging.FORMAT_JSON {
+		return nil, errors.New(fmt.Sprintf("Invalid log format %q, need %s or %s", config.LogFormat, logging.FORMAT_CONSOLE, logging.FORMAT_JSON))
+	}
+	if config.Listen == "" {
+		config.Listen = ":" + params.Port
+	}
+	return config, nil
+}
+
+// The network's parameters with any overrides
+func (config *Config) Params() (*chain.ChainParams, error) {
+	params, err := chain.GetChainParams(config.Network)
+	if err != nil {
+		return nil, err
+	}
+	if config.Target != "" {
+		target, err := hex.DecodeString(config.Target)
+		if err != nil || len(target) == 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid target %q, need hex", config.Target))
+		}
+		params.PowLimit = target
+	}
+	if config.BlockReward != 0 {
+		params.BlockReward = config.BlockReward
+	}
+	if config.HalvingInterval != 0 {
+		params.HalvingInterval = config.HalvingInterval
+	}
+	if config.CoinbaseMaturity != 0 {
+		params.CoinbaseMaturity = config.CoinbaseMaturity
+	}
+	return params, nil
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/p2p"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "bitcoin.toml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	config, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, Default()) {
+		t.Errorf("No settings gave %+v", config)
+	}
+	params, err := config.Params()
+	if err != nil || !reflect.DeepEqual(params, &chain.MAINNET) {
+		t.Errorf("Default params %+v %v", params, err)
+	}
+	// The network decides the port
+	config, err = Load([]string{"-network", "regtest"})
+	if err != nil || config.Listen != ":"+chain.REGTEST.Port {
+		t.Errorf("Regtest config %+v %v", config, err)
+	}
+}
+
+// Flags beat the environment which beats the file
+func TestPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+listen = ":9000"
+peers = ["10.0.0.1", "127.0.0.1:8334"]
+datadir = "file"
+block_reward = 50
+`)
+	t.Setenv("BITCOIN_DATADIR", "env")
+	t.Setenv("BITCOIN_BLOCK_REWARD", "25")
+	config, err := Load([]string{"-config", path, "-block-reward", "5", "-mine-speed", "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Listen != ":9000" || !reflect.DeepEqual(config.Peers, []string{"10.0.0.1", "127.0.0.1:8334"}) {
+		t.Errorf("File settings not used %+v", config)
+	}
+	if config.DataDir != "env" {
+		t.Errorf("Environment should override the file, got %s", config.DataDir)
+	}
+	if config.BlockReward != 5 || config.MineSpeed != 0 {
+		t.Errorf("Flags should override everything, got reward %d speed %d", config.BlockReward, config.MineSpeed)
+	}
+	params, _ := config.Params()
+	if params.BlockReward != 5 || params.HalvingInterval != chain.HALVING_INTERVAL {
+		t.Errorf("Only set values should override the network, got %+v", params)
+	}
+	// The config file can come from the environment too
+	t.Setenv("BITCOIN_CONFIG", path)
+	t.Setenv("BITCOIN_PEERS", "a, b:1")
+	config, err = Load(nil)
+	if err != nil || config.Listen != ":9000" || !reflect.DeepEqual(config.Peers, []string{"a", "b:1"}) {
+		t.Errorf("BITCOIN_CONFIG gave %+v %v", config, err)
+	}
+}
+
+// Bool flags don't need a value, but can still be turned off
+func TestBoolOptions(t *testing.T) {
+	config, err := Load([]string{"-address-index", "-network", "regtest"})
+	if err != nil || !config.AddressIndex || config.Network != "regtest" {
+		t.Errorf("Address index flag without a value %+v %v", config, err)
+	}
+	t.Setenv("BITCOIN_ADDRESS_INDEX", "true")
+	config, err = Load(nil)
+	if err != nil || !config.AddressIndex {
+		t.Errorf("Address index from the environment %+v %v", config, err)
+	}
+	config, err = Load([]string{"-address-index=false"})
+	if err != nil || config.AddressIndex {
+		t.Errorf("Address index turned off by the flag %+v %v", config, err)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	path := writeConfig(t, `
+[[fault]]
+latency = "200ms"
+loss = 0.1
+
+[[fault]]
+peer = "10.0.0.2"
+down = true
+`)
+	config, err := Load([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []p2p.LinkFaults{{Latency: 200 * time.Millisecond, Loss: 0.1}, {Peer: "10.0.0.2", Down: true}}
+	if !reflect.DeepEqual(config.Faults, want) {
+		t.Errorf("Faults are %+v", config.Faults)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	cases := map[string][]string{
+		"unknown setting": {"-config", writeConfig(t, "lisen = \":9000\"\n")},
+		"bad toml":        {"-config", writeConfig(t, "listen = \n")},
+		"missing file":    {"-config", filepath.Join(t.TempDir(), "missing.toml")},
+		"bad number":      {"-block-reward", "ten"},
+		"bad bool":        {"-address-index=maybe"},
+		"bad target":      {"-target", "xyz"},
+		"unknown network": {"-network", "simnet"},
+		"unknown flag":    {"-port", "1"},
+		"bad log level":   {"-log-level", "info,disk=debug"},
+		"bad log format":  {"-log-format", "xml"},
+		"bad loss":        {"-config", writeConfig(t, "[[fault]]\nloss = 2.0\n")},
+		"bad latency":     {"-config", writeConfig(t, "[[fault]]\nlatency = \"soon\"\n")},
+	}
+	for name, args := range cases {
+		if _, err := Load(args); err == nil {
+			t.Errorf("Loading with %s should fail", name)
+		}
+	}
+}
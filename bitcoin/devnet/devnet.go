@@ -0,0 +1,315 @@
+// Runs a network of nodes on localhost, no docker needed:
+//
+//	go run ./devnet -spec=devnet/devnet.toml
+//
+// Each node is this program again in node mode, with its own port, data
+// directory, key and log file under the spec's dir. Once they are up the
+// miners start and a table of every node's height, peers and balance is
+// printed until interrupted, which stops all the nodes.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/config"
+	"github.com/connorwstein/Blockchain/bitcoin/node"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	NODE_ENV      = "BITCOIN_DEVNET_NODE" // Set when we are started as one of the nodes
+	START_TIMEOUT = 30 * time.Second
+	STOP_TIMEOUT  = 5 * time.Second
+	RPC_TIMEOUT   = 2 * time.Second
+)
+
+type process struct {
+	spec    NodeSpec
+	dir     string
+	rpc     string
+	address string // To pay the node's wallet
+	cmd     *exec.Cmd
+	exited  chan struct{}
+	err     error // Why it exited
+	conn    *grpc.ClientConn
+}
+
+func (p *process) logFile() string {
+	return filepath.Join(p.dir, "node.log")
+}
+
+// Key the node mines to, kept so its wallet survives restarts
+func loadOrCreateKey(path string) (*ecdsa.PrivateKey, error) {
+	key, err := wallet.ReadKey(path)
+	if err == nil || !os.IsNotExist(err) {
+		return key, err
+	}
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return key, wallet.WriteKey(path, key)
+}
+
+func start(spec *Spec, nodeSpec NodeSpec) (*process, error) {
+	params, _ := chain.GetChainParams(spec.Network)
+	p := &process{spec: nodeSpec, dir: filepath.Join(spec.Dir, nodeSpec.Name),
+		rpc: fmt.Sprintf("127.0.0.1:%d", nodeSpec.Port), exited: make(chan struct{})}
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(p.dir, "key.pem")
+	key, err := loadOrCreateKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	p.address = params.GetAddress(chain.GetPubKeyBytes(key))
+	var peers []string
+	for _, peer := range nodeSpec.Peers {
+		for _, other := range spec.Node {
+			if other.Name == peer {
+				peers = append(peers, fmt.Sprintf("127.0.0.1:%d", other.Port))
+			}
+		}
+	}
+	log, err := os.Create(p.logFile())
+	if err != nil {
+		return nil, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	p.cmd = exec.Command(executable,
+		"-network="+spec.Network,
+		"-listen="+p.rpc,
+		"-peers="+strings.Join(peers, ","),
+		"-peers-file=",
+		"-datadir="+p.dir,
+		"-mining-key="+keyPath,
+		fmt.Sprintf("-mine-speed=%d", spec.MineSpeed))
+	p.cmd.Env = append(os.Environ(), NODE_ENV+"=1")
+	p.cmd.Stdout = log
+	p.cmd.Stderr = log
+	if err := p.cmd.Start(); err != nil {
+		log.Close()
+		return nil, err
+	}
+	go func() {
+		p.err = p.cmd.Wait()
+		log.Close()
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+// Wait for the node's RPCs to come up
+func (p *process) connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), START_TIMEOUT)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.exited:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	conn, err := grpc.DialContext(ctx, p.rpc, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return errors.New(fmt.Sprintf("%s didn't start, see %s", p.spec.Name, p.logFile()))
+	}
+	p.conn = conn
+	return nil
+}
+
+func (p *process) stop() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-p.exited:
+	case <-time.After(STOP_TIMEOUT):
+		p.cmd.Process.Kill()
+		<-p.exited
+	}
+}
+
+func (p *process) status() []string {
+	name := p.spec.Name
+	if p.spec.Mine {
+		name += " (miner)"
+	}
+	select {
+	case <-p.exited:
+		return []string{name, p.rpc, "exited", fmt.Sprint(p.err), "see " + p.logFile()}
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RPC_TIMEOUT)
+	defer cancel()
+	mining, err := pb.NewMinerClient(p.conn).GetMiningStatus(ctx, &pb.Empty{})
+	if err != nil {
+		return []string{name, p.rpc, "unreachable", err.Error()}
+	}
+	peers, err := pb.NewPeeringClient(p.conn).GetPeers(ctx, &pb.Empty{})
+	if err != nil {
+		return []string{name, p.rpc, "unreachable", err.Error()}
+	}
+	balance, err := pb.NewWalletClient(p.conn).GetBalance(ctx, &pb.Empty{})
+	if err != nil {
+		return []string{name, p.rpc, "unreachable", err.Error()}
+	}
+	return []string{name, p.rpc,
+		fmt.Sprint(mining.Height),
+		fmt.Sprintf("%x", mining.Tip[:4]),
+		fmt.Sprintf("%d/%d", len(peers.Peers), len(p.spec.Peers)),
+		fmt.Sprintf("%d (%d spendable)", balance.Balance, balance.Spendable),
+		mining.State}
+}
+
+func printStatus(processes []*process) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\n%s\n", time.Now().Format("15:04:05"))
+	fmt.Fprintln(w, "NODE\tRPC\tHEIGHT\tTIP\tPEERS\tBALANCE\tMINING")
+	for _, p := range processes {
+		fmt.Fprintln(w, strings.Join(p.status(), "\t"))
+	}
+	w.Flush()
+}
+
+func waitForPeers(processes []*process) error {
+	fmt.Println("Waiting for the nodes to peer")
+	timeout := time.After(START_TIMEOUT)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		connected := true
+		for _, p := range processes {
+			peers, err := pb.NewPeeringClient(p.conn).GetPeers(context.Background(), &pb.Empty{})
+			if err != nil || len(peers.Peers) < len(p.spec.Peers) {
+				connected = false
+				break
+			}
+		}
+		if connected {
+			return nil
+		}
+		select {
+		case <-timeout:
+			return errors.New("Nodes didn't all peer in time, see their node.log")
+		case <-ticker.C:
+		}
+	}
+}
+
+// Start every node, stopping any already started if one fails
+func launch(spec *Spec) ([]*process, error) {
+	var processes []*process
+	fail := func(err error) ([]*process, error) {
+		for _, p := range processes {
+			p.stop()
+		}
+		return nil, err
+	}
+	for _, nodeSpec := range spec.Node {
+		p, err := start(spec, nodeSpec)
+		if err != nil {
+			return fail(err)
+		}
+		processes = append(processes, p)
+	}
+	for _, p := range processes {
+		if err := p.connect(); err != nil {
+			return fail(err)
+		}
+		fmt.Printf("%s listening on %s, address %s\n", p.spec.Name, p.rpc, p.address)
+	}
+	// Nodes don't fetch blocks they missed, so only mine once everyone
+	// is there to hear about them
+	if err := waitForPeers(processes); err != nil {
+		return fail(err)
+	}
+	for _, p := range processes {
+		if !p.spec.Mine {
+			continue
+		}
+		if _, err := pb.NewMinerClient(p.conn).StartMining(context.Background(), &pb.Empty{}); err != nil {
+			return fail(err)
+		}
+	}
+	return processes, nil
+}
+
+func main() {
+	if os.Getenv(NODE_ENV) != "" {
+		cfg, err := config.Load(os.Args[1:])
+		if err == nil {
+			err = node.Run(cfg)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	specFile := flag.String("spec", "", "TOML file of nodes, their peers and which mine")
+	nodes := flag.Int("nodes", DEFAULT_NODES, "without -spec, how many nodes")
+	topology := flag.String("topology", DEFAULT_TOPOLOGY, "without -spec, line, ring, star or mesh")
+	miners := flag.Int("miners", 1, "without -spec, how many of the nodes mine")
+	clean := flag.Bool("clean", false, "delete the nodes' chains and keys first")
+	interval := flag.Duration("status", 2*time.Second, "how often to print the status table")
+	flag.Parse()
+	spec := DefaultSpec()
+	spec.Nodes, spec.Topology, spec.Miners = *nodes, *topology, *miners
+	if *specFile != "" {
+		var err error
+		if spec, err = LoadSpec(*specFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if err := spec.Expand(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *clean {
+		os.RemoveAll(spec.Dir)
+	}
+	processes, err := launch(spec)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		printStatus(processes)
+		select {
+		case <-interrupt:
+			fmt.Println("Stopping")
+			for _, p := range processes {
+				p.stop()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"sort"
+)
+
+const (
+	DEFAULT_NETWORK    = "regtest"
+	DEFAULT_DIR        = "devnet-data"
+	DEFAULT_BASE_PORT  = 18500
+	DEFAULT_MINE_SPEED = 500 // Regtest blocks take 2 tries on average, so about 1 a second
+	DEFAULT_NODES      = 3
+	DEFAULT_TOPOLOGY   = "line"
+)
+
+type NodeSpec struct {
+	Name  string   `toml:"name"`
+	Peers []string `toml:"peers"` // Other nodes by name, links go both ways
+	Mine  bool     `toml:"mine"`
+	Port  int      `toml:"-"` // Filled in by Expand
+}
+
+// What to launch, from a TOML file. Either list the nodes or give a
+// number of them and how to connect them
+type Spec struct {
+	Network   string `toml:"network"`
+	Dir       string `toml:"dir"`       // Each node gets its own directory in here
+	BasePort  int    `toml:"base_port"` // Node i listens on base_port + i
+	MineSpeed uint64 `toml:"mine_speed"`
+	// Without any [[node]] tables, this many nodes named node0, node1...
+	// linked by topology, the first miners of which mine
+	Nodes    int        `toml:"nodes"`
+	Topology string     `toml:"topology"` // line, ring, star or mesh
+	Miners   int        `toml:"miners"`
+	Node     []NodeSpec `toml:"node"`
+}
+
+func DefaultSpec() *Spec {
+	return &Spec{
+		Network:   DEFAULT_NETWORK,
+		Dir:       DEFAULT_DIR,
+		BasePort:  DEFAULT_BASE_PORT,
+		MineSpeed: DEFAULT_MINE_SPEED,
+		Nodes:     DEFAULT_NODES,
+		Topology:  DEFAULT_TOPOLOGY,
+		Miners:    1}
+}
+
+func LoadSpec(path string) (*Spec, error) {
+	spec := DefaultSpec()
+	meta, err := toml.DecodeFile(path, spec)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) != 0 {
+		return nil, errors.New(fmt.Sprintf("Unknown setting %s in %s", undecoded[0], path))
+	}
+	return spec, nil
+}
+
+// Pairs of node indexes to link
+func topologyLinks(topology string, n int) ([][2]int, error) {
+	var links [][2]int
+	switch topology {
+	case "line", "ring":
+		for i := 0; i+1 < n; i++ {
+			links = append(links, [2]int{i, i + 1})
+		}
+		if topology == "ring" && n > 2 {
+			links = append(links, [2]int{n - 1, 0})
+		}
+	case "star":
+		for i := 1; i < n; i++ {
+			links = append(links, [2]int{0, i})
+		}
+	case "mesh":
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				links = append(links, [2]int{i, j})
+			}
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown topology %q, need line, ring, star or mesh", topology))
+	}
+	return links, nil
+}
+
+// Check the spec and fill in the nodes, their ports and both ends of
+// every link
+func (spec *Spec) Expand() error {
+	if _, err := chain.GetChainParams(spec.Network); err != nil {
+		return err
+	}
+	if len(spec.Node) == 0 {
+		links, err := topologyLinks(spec.Topology, spec.Nodes)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < spec.Nodes; i++ {
+			spec.Node = append(spec.Node, NodeSpec{Name: fmt.Sprintf("node%d", i), Mine: i < spec.Miners})
+		}
+		for _, link := range links {
+			spec.Node[link[0]].Peers = append(spec.Node[link[0]].Peers, spec.Node[link[1]].Name)
+		}
+	}
+	if len(spec.Node) == 0 {
+		return errors.New("Need at least one node")
+	}
+	index := make(map[string]int)
+	for i, node := range spec.Node {
+		if node.Name == "" {
+			return errors.New(fmt.Sprintf("Node %d needs a name", i))
+		}
+		if _, ok := index[node.Name]; ok {
+			return errors.New(fmt.Sprintf("More than one node called %s", node.Name))
+		}
+		index[node.Name] = i
+	}
+	peers := make([]map[string]bool, len(spec.Node))
+	for i := range spec.Node {
+		peers[i] = make(map[string]bool)
+	}
+	for i, node := range spec.Node {
+		for _, peer := range node.Peers {
+			j, ok := index[peer]
+			if !ok {
+				return errors.New(fmt.Sprintf("%s peers with unknown node %s", node.Name, peer))
+			}
+			if i == j {
+				return errors.New(fmt.Sprintf("%s can't peer with itself", node.Name))
+			}
+			peers[i][peer] = true
+			peers[j][node.Name] = true
+		}
+	}
+	for i := range spec.Node {
+		spec.Node[i].Peers = nil
+		for peer := range peers[i] {
+			spec.Node[i].Peers = append(spec.Node[i].Peers, peer)
+		}
+		sort.Strings(spec.Node[i].Peers)
+		spec.Node[i].Port = spec.BasePort + i
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTopologies(t *testing.T) {
+	var tests = []struct {
+		topology string
+		peers    [][]string
+	}{
+		{"line", [][]string{{"node1"}, {"node0", "node2"}, {"node1", "node3"}, {"node2"}}},
+		{"ring", [][]string{{"node1", "node3"}, {"node0", "node2"}, {"node1", "node3"}, {"node0", "node2"}}},
+		{"star", [][]string{{"node1", "node2", "node3"}, {"node0"}, {"node0"}, {"node0"}}},
+		{"mesh", [][]string{{"node1", "node2", "node3"}, {"node0", "node2", "node3"}, {"node0", "node1", "node3"}, {"node0", "node1", "node2"}}},
+	}
+	for _, test := range tests {
+		spec := DefaultSpec()
+		spec.Nodes, spec.Topology, spec.Miners = 4, test.topology, 2
+		if err := spec.Expand(); err != nil {
+			t.Fatal(err)
+		}
+		for i, node := range spec.Node {
+			if !reflect.DeepEqual(node.Peers, test.peers[i]) {
+				t.Errorf("%s node%d peers with %v, want %v", test.topology, i, node.Peers, test.peers[i])
+			}
+			if node.Port != DEFAULT_BASE_PORT+i {
+				t.Errorf("%s node%d on port %d", test.topology, i, node.Port)
+			}
+			if node.Mine != (i < 2) {
+				t.Errorf("%s node%d mining is %v", test.topology, i, node.Mine)
+			}
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	var tests = []struct {
+		name string
+		spec Spec
+	}{
+		{"unknown network", Spec{Network: "nope", Node: []NodeSpec{{Name: "a"}}}},
+		{"unknown topology", Spec{Network: "regtest", Nodes: 2, Topology: "tree"}},
+		{"no nodes", Spec{Network: "regtest", Topology: "line"}},
+		{"no name", Spec{Network: "regtest", Node: []NodeSpec{{Name: "a"}, {}}}},
+		{"duplicate", Spec{Network: "regtest", Node: []NodeSpec{{Name: "a"}, {Name: "a"}}}},
+		{"unknown peer", Spec{Network: "regtest", Node: []NodeSpec{{Name: "a", Peers: []string{"b"}}}}},
+		{"self peer", Spec{Network: "regtest", Node: []NodeSpec{{Name: "a", Peers: []string{"a"}}}}},
+	}
+	for _, test := range tests {
+		if err := test.spec.Expand(); err == nil {
+			t.Errorf("%s should not expand", test.name)
+		}
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	spec, err := LoadSpec("devnet.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.Expand(); err != nil {
+		t.Fatal(err)
+	}
+	miners := 0
+	for _, node := range spec.Node {
+		if len(node.Peers) == 0 {
+			t.Errorf("%s has no peers", node.Name)
+		}
+		if node.Mine {
+			miners++
+		}
+	}
+	if len(spec.Node) != 5 || miners != 1 {
+		t.Errorf("Expected 5 nodes with 1 miner, got %d with %d", len(spec.Node), miners)
+	}
+}
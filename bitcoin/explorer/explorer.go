@@ -0,0 +1,30 @@
+// Block explorer for looking around a node's chain in a browser, served
+// by bitcoind with -rest at /explorer/. It is a single page reading the
+// REST gateway's JSON, every asset is embedded so it works offline:
+//
+//	./bitcoin -network=testnet -rest=:8080
+//	open http://localhost:8080/explorer/
+//
+// Pages are recent blocks, a block, a transaction, an address with its
+// balance and history, the mempool and the node's peers.
+package explorer
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Where bitcoind mounts it, the page finds the API relative to this
+const PATH = "/explorer/"
+
+//go:embed static
+var static embed.FS
+
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix(PATH, http.FileServer(http.FS(files)))
+}
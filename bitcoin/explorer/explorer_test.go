@@ -0,0 +1,41 @@
+package explorer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAssets(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	var tests = []struct {
+		path        string
+		contentType string
+	}{
+		{PATH, "text/html"},
+		{PATH + "explorer.js", "text/javascript"},
+		{PATH + "explorer.css", "text/css"},
+	}
+	for _, test := range tests {
+		resp, err := http.Get(server.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), test.contentType) {
+			t.Errorf("%s returned %d %s", test.path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		// Has to work offline, so nothing from anywhere else
+		if external := regexp.MustCompile(`(src|href)="(https?:)?//`).Find(body); external != nil {
+			t.Errorf("%s loads an external asset %s", test.path, external)
+		}
+	}
+	if resp, err := http.Get(server.URL + PATH + "nope.js"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Missing asset should be not found, got %v %v", resp, err)
+	}
+}
@@ -0,0 +1,280 @@
+// Single page explorer over the REST gateway. Pages are picked by the
+// location hash, e.g. #/block/12 or #/address/r123...?offset=20
+"use strict";
+
+// The gateway serves the explorer one level below its API
+const API = new URL("..", location.href).pathname;
+const PAGE_SIZE = 20;
+
+const page = document.getElementById("page");
+
+function esc(value) {
+  return String(value).replace(/[&<>"']/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;"})[c]);
+}
+
+async function get(path) {
+  const resp = await fetch(API + path.replace(/^\//, ""));
+  if (!resp.ok) {
+    let message = resp.status + " " + resp.statusText;
+    try {
+      message = (await resp.json()).error;
+    } catch (e) {}
+    const err = new Error(message);
+    err.status = resp.status;
+    throw err;
+  }
+  return resp.headers.get("Content-Type").startsWith("application/json") ? resp.json() : resp.text();
+}
+
+function time(seconds) {
+  if (!seconds) {
+    return "";
+  }
+  return new Date(seconds * 1000).toISOString().replace("T", " ").replace(/\.000Z$/, " UTC");
+}
+
+function age(seconds) {
+  const s = Math.max(0, Math.floor(Date.now() / 1000) - seconds);
+  if (s < 60) return s + "s ago";
+  if (s < 3600) return Math.floor(s / 60) + "m ago";
+  if (s < 86400) return Math.floor(s / 3600) + "h ago";
+  return Math.floor(s / 86400) + "d ago";
+}
+
+function short(hash) {
+  return hash.length > 16 ? hash.slice(0, 8) + "…" + hash.slice(-8) : hash;
+}
+
+const link = {
+  block: (id, text) => `<a class="hash" href="#/block/${esc(id)}">${esc(text === undefined ? id : text)}</a>`,
+  tx: (id, text) => `<a class="hash" href="#/tx/${esc(id)}">${esc(text === undefined ? id : text)}</a>`,
+  address: (address, text) => `<a class="hash" href="#/address/${encodeURIComponent(address)}">${esc(text === undefined ? short(address) : text)}</a>`,
+};
+
+function table(headers, rows) {
+  if (rows.length === 0) {
+    return `<p class="muted">Nothing here</p>`;
+  }
+  return `<table><tr>${headers.map(h => `<th>${esc(h)}</th>`).join("")}</tr>` +
+    rows.map(row => `<tr>${row.map(cell => `<td>${cell}</td>`).join("")}</tr>`).join("") + `</table>`;
+}
+
+function fields(rows) {
+  return `<table class="fields">` + rows.map(([name, value]) => `<tr><th>${esc(name)}</th><td>${value}</td></tr>`).join("") + `</table>`;
+}
+
+// Newer and older links for a list paged with offset
+function pager(base, offset, total) {
+  if (offset === 0 && total <= PAGE_SIZE) {
+    return "";
+  }
+  const links = [];
+  if (offset > 0) {
+    links.push(`<a href="${base}?offset=${Math.max(0, offset - PAGE_SIZE)}">← Newer</a>`);
+  }
+  if (offset + PAGE_SIZE < total) {
+    links.push(`<a href="${base}?offset=${offset + PAGE_SIZE}">Older →</a>`);
+  }
+  const end = Math.min(total, offset + PAGE_SIZE);
+  return `<div class="pager">${links.join("")}<span class="muted">${total ? offset + 1 : 0}–${end} of ${total}</span></div>`;
+}
+
+// Where an output goes, an address or a script
+function destination(output) {
+  if (output.address) {
+    return link.address(output.address);
+  }
+  if (output.scriptHex) {
+    return `<a class="hash" href="#/address/${esc(output.scriptHex)}">${esc(output.script)}</a>`;
+  }
+  return `<span class="muted">unknown</span>`;
+}
+
+async function blocksPage(params) {
+  const offset = Number(params.get("offset") || 0);
+  const [blocks, status] = await Promise.all([get(`/blocks?offset=${offset}&limit=${PAGE_SIZE}`), get("/mining/status")]);
+  const tip = blocks.blocks.length && offset === 0 ? blocks.blocks[0] : null;
+  let html = `<h2>Blocks</h2><p>Height ${esc(blocks.total)}` +
+    (tip ? `, tip ${link.block(tip.hash, short(tip.hash))} found ${esc(age(tip.time))}` : "") +
+    `, miner ${esc(status.state)}</p>`;
+  html += table(["Height", "Hash", "Time", "Transactions", "Value"], blocks.blocks.map(b => [
+    link.block(b.height, b.height), link.block(b.hash), esc(time(b.time)),
+    `<span class="num">${b.transactions.length}</span>`,
+    `<span class="num">${b.transactions.reduce((sum, tx) => sum + tx.value, 0)}</span>`,
+  ]));
+  return html + pager("#/", offset, blocks.total);
+}
+
+function transactionRows(txs) {
+  return txs.map(tx => [
+    link.tx(tx.txid),
+    tx.coinbase ? "coinbase" : esc(tx.inputs.length),
+    esc(tx.outputs.length),
+    `<span class="num">${esc(tx.value)}</span>`,
+  ]);
+}
+
+async function blockPage(id) {
+  const block = await get(`/blocks/${encodeURIComponent(id)}`);
+  let html = `<h2>Block ${esc(block.height)}</h2>`;
+  html += fields([
+    ["Hash", `<span class="hash">${esc(block.hash)}</span>`],
+    ["Previous", block.height > 1 ? link.block(block.prevHash) : `<span class="muted">genesis</span>`],
+    ["Next", link.block(block.height + 1, "block " + (block.height + 1))],
+    ["Time", esc(time(block.time)) + ` <span class="muted">${esc(age(block.time))}</span>`],
+    ["Merkle root", `<span class="hash">${esc(block.merkleRoot)}</span>`],
+    ["Difficulty", esc(block.difficulty)],
+    ["Nonce", esc(block.nonce)],
+  ]);
+  html += `<h3>Transactions</h3>` + table(["Txid", "Inputs", "Outputs", "Value"], transactionRows(block.transactions));
+  html += `<h3>Serialized</h3><p><a href="${API}blocks/${esc(block.hash)}.hex">hex</a></p>`;
+  return html;
+}
+
+async function transactionPage(id) {
+  const tx = await get(`/tx/${encodeURIComponent(id)}`);
+  const confirmed = tx.blockHash !== "";
+  let html = `<h2>Transaction</h2>`;
+  html += fields([
+    ["Txid", `<span class="hash">${esc(tx.txid)}</span>`],
+    ["Status", confirmed ?
+      `${esc(tx.confirmations)} confirmation${tx.confirmations === 1 ? "" : "s"} in ${link.block(tx.blockHash, "block " + tx.blockHeight)}` :
+      `<a href="#/mempool">In the mempool</a>, unconfirmed`],
+    ["Value", esc(tx.value)],
+    ["Lock time", tx.lockTime ? esc(tx.lockTime) : `<span class="muted">none</span>`],
+  ]);
+  html += `<h3>Inputs</h3>`;
+  html += tx.coinbase ? `<p>Coinbase, new coin for the block at height ${esc(tx.height)}</p>` :
+    table(["Spends", "Sequence", "Unlocking script"], tx.inputs.map(input => [
+      link.tx(input.txid, short(input.txid) + ":" + input.index),
+      esc(input.sequence),
+      input.unlockingScript ? `<span class="hash">${esc(short(input.unlockingScript))}</span>` : `<span class="muted">signature</span>`,
+    ]));
+  html += `<h3>Outputs</h3>` + table(["Index", "To", "Value"], tx.outputs.map(output => [
+    esc(output.index), destination(output), `<span class="num">${esc(output.value)}</span>`,
+  ]));
+  html += `<h3>Serialized</h3><pre id="hex"><a href="#" id="show-hex">show hex</a></pre>`;
+  return html;
+}
+
+async function addressPage(address, params) {
+  const offset = Number(params.get("offset") || 0);
+  const query = `?offset=${offset}&limit=${PAGE_SIZE}`;
+  const encoded = encodeURIComponent(address);
+  const [history, utxos] = await Promise.all([get(`/address/${encoded}/history${query}`), get(`/address/${encoded}/utxos?limit=${PAGE_SIZE}`)]);
+  let html = `<h2>Address</h2>`;
+  html += fields([
+    ["Address", `<span class="hash">${esc(address)}</span>`],
+    ["Balance", esc(utxos.balance)],
+    ["Received", esc(history.received)],
+    ["Sent", esc(history.sent)],
+    ["Transactions", esc(history.total)],
+  ]);
+  html += `<h3>History</h3>` + table(["Txid", "Block", "Time", "Received", "Sent"], history.transactions.map(entry => [
+    link.tx(entry.txid),
+    entry.height ? link.block(entry.blockHash, entry.height) : `<span class="muted">mempool</span>`,
+    esc(time(entry.time)),
+    entry.received ? `<span class="num in">+${esc(entry.received)}</span>` : "",
+    entry.sent ? `<span class="num out">−${esc(entry.sent)}</span>` : "",
+  ]));
+  html += pager(`#/address/${encoded}`, offset, history.total);
+  html += `<h3>Unspent outputs</h3>` + table(["Output", "Block", "Value"], utxos.utxos.map(utxo => [
+    link.tx(utxo.txid, short(utxo.txid) + ":" + utxo.index),
+    link.block(utxo.height, utxo.height) + (utxo.coinbase ? ` <span class="muted">coinbase</span>` : ""),
+    `<span class="num">${esc(utxo.value)}</span>`,
+  ]));
+  if (utxos.total > utxos.utxos.length) {
+    html += `<p class="muted">${esc(utxos.total - utxos.utxos.length)} more</p>`;
+  }
+  return html;
+}
+
+async function mempoolPage(params) {
+  const offset = Number(params.get("offset") || 0);
+  const mempool = await get(`/mempool?offset=${offset}&limit=${PAGE_SIZE}`);
+  return `<h2>Mempool</h2>` + table(["Txid", "Inputs", "Outputs", "Value"], transactionRows(mempool.transactions)) +
+    pager("#/mempool", offset, mempool.total);
+}
+
+async function peersPage() {
+  const peers = await get("/peers");
+  return `<h2>Peers</h2>` + table(["Address"], peers.map(peer => [esc(peer.address)]));
+}
+
+// Height, then block or transaction hash, then address
+async function search(query) {
+  query = query.trim();
+  if (/^\d+$/.test(query)) {
+    return "#/block/" + query;
+  }
+  if (/^[0-9a-f]{64}$/i.test(query)) {
+    try {
+      await get("/blocks/" + query);
+      return "#/block/" + query;
+    } catch (e) {
+      return "#/tx/" + query;
+    }
+  }
+  return "#/address/" + encodeURIComponent(query);
+}
+
+const routes = [
+  [/^\/$/, (m, params) => blocksPage(params)],
+  [/^\/block\/([^/]+)$/, m => blockPage(m[1])],
+  [/^\/tx\/([^/]+)$/, m => transactionPage(m[1])],
+  [/^\/address\/([^/]+)$/, (m, params) => addressPage(decodeURIComponent(m[1]), params)],
+  [/^\/mempool$/, (m, params) => mempoolPage(params)],
+  [/^\/peers$/, () => peersPage()],
+];
+
+async function render() {
+  const [path, query] = (location.hash.slice(1) || "/").split("?");
+  const params = new URLSearchParams(query);
+  for (const [pattern, view] of routes) {
+    const m = path.match(pattern);
+    if (!m) {
+      continue;
+    }
+    try {
+      page.innerHTML = await view(m, params);
+    } catch (e) {
+      page.innerHTML = `<h2>${e.status === 404 ? "Not found" : "Error"}</h2><p class="error">${esc(e.message)}</p>`;
+    }
+    window.scrollTo(0, 0);
+    return;
+  }
+  page.innerHTML = `<h2>Not found</h2><p>No page ${esc(path)}</p>`;
+}
+
+async function refreshStatus() {
+  try {
+    const status = await get("/mining/status");
+    document.getElementById("status").textContent = `height ${status.height}, miner ${status.state}`;
+  } catch (e) {
+    document.getElementById("status").textContent = "node unreachable";
+  }
+}
+
+document.getElementById("search").addEventListener("submit", async event => {
+  event.preventDefault();
+  const query = document.getElementById("query");
+  if (query.value.trim() !== "") {
+    location.hash = await search(query.value);
+    query.value = "";
+  }
+});
+
+// Raw hex on a transaction page, only fetched when asked for
+page.addEventListener("click", async event => {
+  if (event.target.id !== "show-hex") {
+    return;
+  }
+  event.preventDefault();
+  const txid = location.hash.split("/")[2];
+  document.getElementById("hex").textContent = await get(`/tx/${txid}.hex`);
+});
+
+window.addEventListener("hashchange", render);
+render();
+refreshStatus();
+setInterval(refreshStatus, 10000);
@@ -0,0 +1,17 @@
+module github.com/connorwstein/Blockchain/bitcoin
+
+go 1.25.0
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/golang/protobuf v1.5.4
+	golang.org/x/net v0.57.0
+	golang.org/x/sys v0.47.0
+	google.golang.org/grpc v1.56.3
+)
+
+require (
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
@@ -0,0 +1,164 @@
+// Leveled, structured logging with a logger per subsystem, so one part
+// of the node can be turned up without drowning in the rest. Messages
+// are short and constant, the details go in attributes:
+//
+//	logging.Chain.Info("Added block", logging.Block(hash), "height", height)
+//
+// Blocks and transactions are always logged under the block and tx keys,
+// so grepping for block=<hash> follows one block from the peer that sent
+// it, through validation, onto the chain and out to our peers. Levels are
+// set for every subsystem or one at a time, e.g. "info,p2p=debug", and
+// can be changed while the node runs.
+package logging
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	FORMAT_CONSOLE = "console" // key=value lines for reading in a terminal
+	FORMAT_JSON    = "json"    // One object per line for log collectors
+	DEFAULT_FORMAT = FORMAT_CONSOLE
+	DEFAULT_LEVEL  = "info"
+)
+
+type subsystem struct {
+	logger *slog.Logger
+	level  slog.LevelVar
+}
+
+var subsystems = make(map[string]*subsystem)
+
+func newLogger(name string) *slog.Logger {
+	sub := &subsystem{logger: new(slog.Logger)}
+	subsystems[name] = sub
+	return sub.logger
+}
+
+var (
+	Chain   = newLogger("chain")   // Validating blocks and transactions, extending the tip
+	Mempool = newLogger("mempool") // Transactions waiting to be mined
+	P2P     = newLogger("p2p")     // Peers and what we send and receive from them
+	Miner   = newLogger("miner")
+	Wallet  = newLogger("wallet") // Keys, addresses and the transactions we make
+	Node    = newLogger("node")   // Starting up and serving RPC, REST and metrics
+)
+
+func init() {
+	SetOutput(os.Stdout, DEFAULT_FORMAT)
+}
+
+// Names of the subsystems, sorted
+func Subsystems() []string {
+	var names []string
+	for name := range subsystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send every subsystem's messages to w. Only meant to be called when
+// starting up, before anything is logged
+func SetOutput(w io.Writer, format string) error {
+	if format != FORMAT_CONSOLE && format != FORMAT_JSON {
+		return errors.New(fmt.Sprintf("Unknown log format %q, need %s or %s", format, FORMAT_CONSOLE, FORMAT_JSON))
+	}
+	for name, sub := range subsystems {
+		options := &slog.HandlerOptions{Level: &sub.level}
+		var handler slog.Handler
+		if format == FORMAT_JSON {
+			handler = slog.NewJSONHandler(w, options)
+		} else {
+			handler = slog.NewTextHandler(w, options)
+		}
+		*sub.logger = *slog.New(handler.WithAttrs([]slog.Attr{slog.String("subsystem", name)}))
+	}
+	return nil
+}
+
+func parseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, errors.New(fmt.Sprintf("Unknown log level %q, need debug, info, warn or error", level))
+	}
+	return l, nil
+}
+
+// A level for every subsystem, then any overrides for single ones, e.g.
+// "warn,chain=debug". The returned map has an entry for each subsystem
+func ParseLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for i, part := range strings.Split(spec, ",") {
+		name, level, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			if i != 0 {
+				return nil, errors.New(fmt.Sprintf("Log level %q must come before the subsystem ones", part))
+			}
+			level, name = name, ""
+		}
+		l, err := parseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			for name := range subsystems {
+				levels[name] = l
+			}
+		} else if _, ok := subsystems[name]; ok {
+			levels[name] = l
+		} else {
+			return nil, errors.New(fmt.Sprintf("Unknown log subsystem %q, need one of %s", name, strings.Join(Subsystems(), ", ")))
+		}
+	}
+	for name, sub := range subsystems {
+		if _, ok := levels[name]; !ok {
+			levels[name] = sub.level.Level()
+		}
+	}
+	return levels, nil
+}
+
+func SetLevels(spec string) error {
+	levels, err := ParseLevels(spec)
+	if err != nil {
+		return err
+	}
+	for name, level := range levels {
+		subsystems[name].level.Set(level)
+	}
+	return nil
+}
+
+// Empty subsystem for all of them. Safe to call while logging
+func SetLevel(subsystem string, level string) error {
+	if subsystem == "" {
+		return SetLevels(level)
+	}
+	return SetLevels(subsystem + "=" + level)
+}
+
+// Lower case like it's given, e.g. debug
+func GetLevel(subsystem string) string {
+	sub, ok := subsystems[subsystem]
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(sub.level.Level().String())
+}
+
+// The attributes a block or transaction is logged with
+func Block(hash []byte) slog.Attr {
+	return slog.String("block", hex.EncodeToString(hash))
+}
+
+func Tx(hash []byte) slog.Attr {
+	return slog.String("tx", hex.EncodeToString(hash))
+}
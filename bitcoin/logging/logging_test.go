@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	defer SetLevels(DEFAULT_LEVEL)
+	if err := SetLevels("warn, p2p=debug"); err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel("chain") != "warn" || GetLevel("p2p") != "debug" {
+		t.Errorf("Got chain %s p2p %s", GetLevel("chain"), GetLevel("p2p"))
+	}
+	// Only the one subsystem changes
+	if err := SetLevel("miner", "ERROR"); err != nil || GetLevel("miner") != "error" || GetLevel("p2p") != "debug" {
+		t.Errorf("Got miner %s p2p %s %v", GetLevel("miner"), GetLevel("p2p"), err)
+	}
+	for _, spec := range []string{"loud", "chain=loud", "disk=info", "chain=debug,info", ""} {
+		if err := SetLevels(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+	if GetLevel("chain") != "warn" {
+		t.Error("A rejected spec shouldn't change anything")
+	}
+}
+
+func TestOutput(t *testing.T) {
+	defer SetOutput(os.Stdout, DEFAULT_FORMAT)
+	defer SetLevels(DEFAULT_LEVEL)
+	var buf bytes.Buffer
+	if err := SetOutput(&buf, FORMAT_JSON); err != nil {
+		t.Fatal(err)
+	}
+	SetLevels("info,chain=debug")
+	Chain.Debug("Added block", Block([]byte{0xab, 0xcd}), "height", 3)
+	Miner.Debug("Tried a nonce")
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("%v in %s", err, buf.String())
+	}
+	if line["msg"] != "Added block" || line["subsystem"] != "chain" || line["block"] != "abcd" || line["height"] != 3.0 {
+		t.Errorf("Got %v", line)
+	}
+	buf.Reset()
+	SetOutput(&buf, FORMAT_CONSOLE)
+	Mempool.Warn("Dropped transaction", Tx([]byte{1}))
+	if !strings.Contains(buf.String(), `level=WARN msg="Dropped transaction" subsystem=mempool tx=01`) {
+		t.Errorf("Got %s", buf.String())
+	}
+	if SetOutput(&buf, "xml") == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,75 @@
+// Transactions waiting to be mined and the policy deciding which ones
+// we relay. Policy isn't consensus, a node won't relay or mine a non
+// standard transaction but will accept a block containing one,
+// otherwise nodes with different policies would split the chain.
+package mempool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+)
+
+const (
+	MAX_STANDARD_TX_SIZE = 100000
+	MAX_STANDARD_OUTPUTS = 1000
+	// Coin per 1000 bytes. The coin is so coarse that even 1 would cost
+	// a tenth of a block reward to send, so free by default
+	MIN_RELAY_FEE_RATE = 0
+)
+
+type MemPool struct {
+	Transactions map[string]*pb.Transaction
+	// Coin per 1000 bytes a transaction must pay to be relayed
+	MinRelayFeeRate uint64
+}
+
+func (memPool *MemPool) AddTransaction(transaction *pb.Transaction) {
+	tx := chain.GetTransactionHash(transaction)
+	memPool.Transactions[string(tx[:])] = transaction
+	logging.Mempool.Info("Added transaction", logging.Tx(tx), "size", chain.GetTransactionSize(transaction),
+		"transactions", len(memPool.Transactions))
+}
+
+// Whether a transaction in the mempool already spends this UTXO
+func (memPool *MemPool) IsSpent(utxo *chain.UTXO) bool {
+	txID := chain.GetTransactionHash(utxo.Transaction)
+	for _, transaction := range memPool.Transactions {
+		for _, txi := range transaction.Vin {
+			if bytes.Equal(txi.TxID, txID) && txi.Index == uint64(utxo.Index) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks which don't need the chain, cheap enough to do before verifying
+func (memPool *MemPool) CheckStandard(transaction *pb.Transaction) error {
+	if size := chain.GetTransactionSize(transaction); size > MAX_STANDARD_TX_SIZE {
+		return errors.New(fmt.Sprintf("Non standard transaction: %d bytes, the limit is %d", size, MAX_STANDARD_TX_SIZE))
+	}
+	if len(transaction.Vout) > MAX_STANDARD_OUTPUTS {
+		return errors.New(fmt.Sprintf("Non standard transaction: %d outputs, the limit is %d", len(transaction.Vout), MAX_STANDARD_OUTPUTS))
+	}
+	for i, txo := range transaction.Vout {
+		// Data outputs are never spent so their value doesn't matter
+		if chain.IsDust(txo.Value, memPool.MinRelayFeeRate) && !chain.IsDataScript(txo.LockingScript) {
+			return errors.New(fmt.Sprintf("Non standard transaction: output %d of %d is dust", i, txo.Value))
+		}
+	}
+	return nil
+}
+
+// The fee has to be worked out from the inputs, so only check a verified transaction
+func (memPool *MemPool) CheckRelayFee(transaction *pb.Transaction, fee uint64) error {
+	size := chain.GetTransactionSize(transaction)
+	minFee := chain.FeeForSize(size, memPool.MinRelayFeeRate)
+	if fee < minFee {
+		return errors.New(fmt.Sprintf("Fee %d is below the minimum relay fee of %d for %d bytes", fee, minFee, size))
+	}
+	return nil
+}
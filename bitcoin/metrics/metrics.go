@@ -0,0 +1,228 @@
+// Counters, gauges and histograms written out in the Prometheus text
+// format, just enough for a node to be scraped without pulling in the
+// client library. Metrics can have labels, given as values in the order
+// their names were registered:
+//
+//	rejected := registry.NewCounter("rejected_total", "Rejected objects", "object", "reason")
+//	rejected.Inc("block", "invalid")
+//
+// A Registry is an http.Handler serving every metric registered with it.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Seconds, for how long validating things takes
+var LATENCY_BUCKETS = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+type Registry struct {
+	mutex   sync.Mutex
+	metrics []metric // Written in the order they were registered
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *Registry) Write(w io.Writer) {
+	r.mutex.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mutex.Unlock()
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.Write(w)
+}
+
+func writeHeader(w io.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.Replace(help, "\n", " ", -1), name, kind)
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// {a="x",b="y"}, empty without labels
+func formatLabels(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[i])
+		pairs[i] = name + `="` + value + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Values of a counter or gauge, one per combination of label values
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mutex  sync.Mutex
+	values map[string]float64
+	keys   map[string][]string // Label values of each key in values
+}
+
+func newFamily(name string, help string, kind string, labels []string) *family {
+	f := &family{name: name, help: help, kind: kind, labels: labels,
+		values: make(map[string]float64), keys: make(map[string][]string)}
+	if len(labels) == 0 {
+		// Always has a value, even before it's set
+		f.values[""] = 0
+		f.keys[""] = nil
+	}
+	return f
+}
+
+func (f *family) update(labelValues []string, update func(value float64) float64) {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("%s has labels %v, got values %v", f.name, f.labels, labelValues))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.keys[key]; !ok {
+		f.keys[key] = append([]string(nil), labelValues...)
+	}
+	f.values[key] = update(f.values[key])
+}
+
+func (f *family) write(w io.Writer) {
+	writeHeader(w, f.name, f.help, f.kind)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	keys := make([]string, 0, len(f.values))
+	for key := range f.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(f.labels, f.keys[key]), formatValue(f.values[key]))
+	}
+}
+
+// Only goes up, from when the node started
+type Counter struct {
+	*family
+}
+
+func (r *Registry) NewCounter(name string, help string, labels ...string) *Counter {
+	c := &Counter{newFamily(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("%s can't go down by %v", c.name, delta))
+	}
+	c.update(labelValues, func(value float64) float64 { return value + delta })
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+type Gauge struct {
+	*family
+}
+
+func (r *Registry) NewGauge(name string, help string, labels ...string) *Gauge {
+	g := &Gauge{newFamily(name, help, "gauge", labels)}
+	r.register(g)
+	return g
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(float64) float64 { return value })
+}
+
+// A gauge worked out when scraped, e.g. from the state of the chain
+type gaugeFunc struct {
+	name string
+	help string
+	f    func() float64
+}
+
+func (r *Registry) NewGaugeFunc(name string, help string, f func() float64) {
+	r.register(&gaugeFunc{name, help, f})
+}
+
+func (g *gaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.f()))
+}
+
+// Counts of observations up to each bucket's upper bound
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	mutex   sync.Mutex
+	counts  []uint64 // Per bucket, not cumulative
+	count   uint64
+	sum     float64
+}
+
+func (r *Registry) NewHistogram(name string, help string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+	r.register(h)
+	return h
+}
+
+func (h *Histogram) Observe(value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.count++
+	h.sum += value
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		h.counts[i]++
+	}
+}
+
+// Seconds since start, for timing something
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) write(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatValue(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", h.name, formatValue(h.sum), h.name, h.count)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExposition(t *testing.T) {
+	r := NewRegistry()
+	rejected := r.NewCounter("rejected_total", "Rejected objects", "object", "reason")
+	rejected.Inc("transaction", "fee")
+	rejected.Add(2, "block", `bad "quote"`)
+	rejected.Inc("transaction", "fee")
+	r.NewGauge("peers", "Connected peers").Set(3)
+	r.NewGaugeFunc("height", "Chain height", func() float64 { return 12 })
+	latency := r.NewHistogram("latency_seconds", "How long it took", []float64{0.1, 1})
+	latency.Observe(0.1)
+	latency.Observe(0.5)
+	latency.Observe(7)
+	var buf bytes.Buffer
+	r.Write(&buf)
+	want := `# HELP rejected_total Rejected objects
+# TYPE rejected_total counter
+rejected_total{object="block",reason="bad \"quote\""} 2
+rejected_total{object="transaction",reason="fee"} 2
+# HELP peers Connected peers
+# TYPE peers gauge
+peers 3
+# HELP height Chain height
+# TYPE height gauge
+height 12
+# HELP latency_seconds How long it took
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 7.6
+latency_seconds_count 3
+`
+	if buf.String() != want {
+		t.Errorf("Got\n%s\nwant\n%s", buf.String(), want)
+	}
+	// Counters without labels are there from the start
+	r.NewCounter("blocks_total", "Blocks")
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") ||
+		!strings.HasSuffix(recorder.Body.String(), "\nblocks_total 0\n") {
+		t.Errorf("Served %s %s", recorder.Header(), recorder.Body)
+	}
+}
+
+func TestWrongLabels(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Missing label values should panic")
+		}
+	}()
+	NewRegistry().NewCounter("rejected_total", "Rejected objects", "object", "reason").Inc("block")
+}
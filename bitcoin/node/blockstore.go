@@ -0,0 +1,70 @@
+// Blocks live in memory but are also appended to a file in the data
+// directory as they are accepted, so a restarted node picks up the
+// chain where it left off instead of starting again from genesis.
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/serialize"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const BLOCK_FILE = "blocks.dat"
+
+// Nothing is stored without a data directory
+func (s *Server) storeBlock(block *pb.Block) {
+	if s.DataDir == "" {
+		return
+	}
+	file, err := os.OpenFile(filepath.Join(s.DataDir, BLOCK_FILE), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logging.Chain.Error("Failed to store block", logging.Block(chain.GetBlockHash(block)), "err", err)
+		return
+	}
+	defer file.Close()
+	if err := serialize.WriteBlock(file, block); err != nil {
+		logging.Chain.Error("Failed to store block", logging.Block(chain.GetBlockHash(block)), "err", err)
+	}
+}
+
+// Blocks were valid when stored, so only check they still chain together
+func (s *Server) LoadBlocks() error {
+	file, err := os.Open(filepath.Join(s.DataDir, BLOCK_FILE))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	for {
+		block, err := serialize.ReadBlock(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.New(fmt.Sprintf("Block file is corrupt after height %d: %v", s.Blockchain.NextBlockNum-1, err))
+		}
+		if !bytes.Equal(block.Header.PrevBlockHash, chain.GetBlockHash(s.Blockchain.TipsOfChains[0])) {
+			return errors.New(fmt.Sprintf("Block at height %d doesn't follow the one before it", block.Header.Height))
+		}
+		if block.Header.DifficultyTarget != chain.GetCompactTarget(s.Blockchain.Target) {
+			// Stored before headers carried the target
+			return errors.New(fmt.Sprintf("Block at height %d has target %08x but %08x is due", block.Header.Height,
+				block.Header.DifficultyTarget, chain.GetCompactTarget(s.Blockchain.Target)))
+		}
+		s.Blockchain.AddBlock(block)
+	}
+	logging.Chain.Info("Loaded blocks", "blocks", len(s.Blockchain.Blocks)-1, "height", s.Blockchain.NextBlockNum-1,
+		"datadir", s.DataDir)
+	return nil
+}
@@ -0,0 +1,53 @@
+package node
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreAndLoadBlocks(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	s.DataDir = t.TempDir()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	mineBlocks(s, t, 4)
+
+	// Same starting target, the blocks have to be at the targets it leads to
+	restarted := NewServer()
+	restarted.DataDir = s.DataDir
+	if err := restarted.LoadBlocks(); err == nil {
+		t.Error("Blocks mined at another starting target shouldn't load")
+	}
+	restarted = NewServer()
+	restarted.DataDir = s.DataDir
+	restarted.Blockchain.SetTarget(target)
+	if err := restarted.LoadBlocks(); err != nil {
+		t.Fatal(err)
+	}
+	if restarted.Blockchain.NextBlockNum != s.Blockchain.NextBlockNum ||
+		!bytes.Equal(chain.GetBlockHash(restarted.Blockchain.TipsOfChains[0]), chain.GetBlockHash(s.Blockchain.TipsOfChains[0])) {
+		t.Errorf("Loaded up to block %d, mined up to %d", restarted.Blockchain.NextBlockNum-1, s.Blockchain.NextBlockNum-1)
+	}
+	if balance := restarted.Blockchain.GetBalance(&s.Wallet.Key.PublicKey); balance != s.Blockchain.GetBalance(&s.Wallet.Key.PublicKey) {
+		t.Errorf("Balance after loading is %d", balance)
+	}
+
+	// A half written block is reported rather than loaded
+	path := filepath.Join(s.DataDir, BLOCK_FILE)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, data[:len(data)-1], 0644)
+	if err := NewServer().LoadBlocks(); err != nil {
+		t.Error("No data directory should load nothing")
+	}
+	corrupt := NewServer()
+	corrupt.DataDir = s.DataDir
+	if err := corrupt.LoadBlocks(); err == nil {
+		t.Error("Truncated block file should fail to load")
+	}
+}
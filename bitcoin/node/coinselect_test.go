@@ -0,0 +1,28 @@
+package node
+
+import (
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"strings"
+	"testing"
+)
+
+func TestUTXOsToCoverTransaction(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	// Relax difficulty for this
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 4)
+	inputs := wallet.GetUTXOsToCoverTransaction(s.Blockchain, s.Wallet.Key, chain.BLOCK_REWARD+1)
+	var total uint64
+	for _, utxo := range inputs {
+		total += s.Blockchain.GetValueUTXO(utxo)
+	}
+	if len(inputs) != 2 || total < chain.BLOCK_REWARD+1 {
+		t.Errorf("Expected 2 inputs covering %d, got %d totalling %d", chain.BLOCK_REWARD+1, len(inputs), total)
+	}
+}
@@ -0,0 +1,50 @@
+// Admin RPCs for injecting faults into the messages we send our peers,
+// see p2p.FaultInjector.
+package node
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	"github.com/connorwstein/Blockchain/bitcoin/p2p"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"time"
+)
+
+func linkFaultsFromProto(in *pb.LinkFaults) p2p.LinkFaults {
+	return p2p.LinkFaults{
+		Peer:    in.Peer,
+		Latency: time.Duration(in.Latency) * time.Millisecond,
+		Jitter:  time.Duration(in.Jitter) * time.Millisecond,
+		Loss:    in.Loss,
+		Reorder: in.Reorder,
+		Down:    in.Down}
+}
+
+func linkFaultsToProto(faults p2p.LinkFaults) *pb.LinkFaults {
+	return &pb.LinkFaults{
+		Peer:    faults.Peer,
+		Latency: uint64(faults.Latency / time.Millisecond),
+		Jitter:  uint64(faults.Jitter / time.Millisecond),
+		Loss:    faults.Loss,
+		Reorder: faults.Reorder,
+		Down:    faults.Down}
+}
+
+func (s *Server) SetFaults(ctx context.Context, in *pb.LinkFaults) (*pb.Empty, error) {
+	logging.P2P.Info("Set faults", "faults", in.String())
+	return &pb.Empty{}, s.faults.Set(linkFaultsFromProto(in))
+}
+
+func (s *Server) ClearFaults(ctx context.Context, in *pb.Empty) (*pb.Empty, error) {
+	logging.P2P.Info("Cleared faults")
+	s.faults.Clear()
+	return &pb.Empty{}, nil
+}
+
+func (s *Server) GetFaults(ctx context.Context, in *pb.Empty) (*pb.FaultsList, error) {
+	var reply pb.FaultsList
+	for _, faults := range s.faults.List() {
+		reply.Links = append(reply.Links, linkFaultsToProto(faults))
+	}
+	return &reply, nil
+}
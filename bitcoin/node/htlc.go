@@ -0,0 +1,159 @@
+// RPCs for hash time locked contracts, the scripts and how a swap
+// works are in the chain package.
+package node
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+)
+
+func (s *Server) CreateHTLC(ctx context.Context, in *pb.HTLCRequest) (*pb.HTLC, error) {
+	if s.Wallet.Key == nil {
+		return &pb.HTLC{}, errors.New("Need to make an account first")
+	}
+	if len(in.Hash) != sha256.Size || len(in.RecipientPubKey) <= 32 {
+		return &pb.HTLC{}, errors.New("Need a recipient and the SHA256 hash of the secret")
+	}
+	if in.LockBlocks == 0 {
+		return &pb.HTLC{}, errors.New("Need a lock time for the refund")
+	}
+	s.stateLock.RLock()
+	lockTime := uint64(s.Blockchain.NextBlockNum) + in.LockBlocks
+	s.stateLock.RUnlock()
+	script := chain.HTLCScript(in.Hash, in.RecipientPubKey, chain.GetPubKeyBytes(s.Wallet.Key), lockTime)
+	sent, err := s.SendTransaction(ctx, &pb.TransactionRequest{FeeRate: in.FeeRate,
+		Outputs: []*pb.TXO{&pb.TXO{LockingScript: script, Value: in.Value}}})
+	if err != nil {
+		return &pb.HTLC{}, err
+	}
+	contract, _ := chain.ParseHTLCScript(script)
+	contract.TxID = sent.TxID
+	contract.Value = in.Value
+	// Change comes first if there is any
+	if sent.Change != 0 {
+		contract.Index = 1
+	}
+	logging.Wallet.Info("Created HTLC", logging.Tx(contract.TxID), "index", contract.Index, "lock_time", lockTime)
+	return contract, nil
+}
+
+// Find the contract, and how it was spent if it has been, in the chain or mempool
+func (s *Server) getHTLC(txID []byte, index uint64) (*pb.HTLC, error) {
+	trans := s.Blockchain.GetTransaction(txID)
+	if trans == nil {
+		trans = s.MemPool.Transactions[string(txID)]
+	}
+	if trans == nil || index >= uint64(len(trans.Vout)) {
+		return nil, errors.New("No such output")
+	}
+	contract, ok := chain.ParseHTLCScript(trans.Vout[index].LockingScript)
+	if !ok {
+		return nil, errors.New("Output is not a hash time locked contract")
+	}
+	contract.TxID = txID
+	contract.Index = index
+	contract.Value = trans.Vout[index].Value
+	var transactions []*pb.Transaction
+	for _, block := range s.Blockchain.GetMainChain() {
+		transactions = append(transactions, block.Transactions...)
+	}
+	for _, transaction := range s.MemPool.Transactions {
+		transactions = append(transactions, transaction)
+	}
+	for _, transaction := range transactions {
+		for _, txi := range transaction.Vin {
+			if !bytes.Equal(txi.TxID, txID) || txi.Index != index {
+				continue
+			}
+			contract.SpentBy = chain.GetTransactionHash(transaction)
+			contract.Preimage = chain.ExtractPreimage(txi.UnlockingScript, contract.Hash)
+		}
+	}
+	return contract, nil
+}
+
+func (s *Server) GetHTLC(ctx context.Context, in *pb.HTLCSpend) (*pb.HTLC, error) {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	contract, err := s.getHTLC(in.TxID, in.Index)
+	if err != nil {
+		return &pb.HTLC{}, err
+	}
+	return contract, nil
+}
+
+// Pay the whole contract minus the fee to our wallet
+func (s *Server) spendHTLC(in *pb.HTLCSpend, redeem bool) (*pb.TransactionSent, error) {
+	var reply pb.TransactionSent
+	if s.Wallet.Key == nil {
+		return &reply, errors.New("Need to make an account first")
+	}
+	err := s.addAndBroadcast(func() (*pb.Transaction, error) {
+		contract, err := s.getHTLC(in.TxID, in.Index)
+		if err != nil {
+			return nil, err
+		}
+		if len(contract.SpentBy) != 0 {
+			return nil, errors.New(fmt.Sprintf("Already spent by %x", contract.SpentBy))
+		}
+		if in.Fee >= contract.Value {
+			return nil, errors.New("Fee is more than the contract is worth")
+		}
+		pubKey := chain.GetPubKeyBytes(s.Wallet.Key)
+		var trans pb.Transaction
+		trans.Vin = []*pb.TXI{&pb.TXI{TxID: in.TxID, Index: in.Index}}
+		trans.Vout = []*pb.TXO{&pb.TXO{ReceiverPubKey: pubKey, Value: contract.Value - in.Fee}}
+		var b chain.ScriptBuilder
+		if redeem {
+			if !bytes.Equal(contract.RecipientPubKey, pubKey) {
+				return nil, errors.New("Only the recipient can redeem")
+			}
+			if sum := sha256.Sum256(in.Preimage); len(in.Preimage) != chain.SWAP_SECRET_SIZE || !bytes.Equal(sum[:], contract.Hash) {
+				return nil, errors.New("Secret does not match the contract hash")
+			}
+			b.AddData(wallet.SignTransactionHash(&trans, s.Wallet.Key)).AddData(in.Preimage).AddInt(1)
+		} else {
+			if !bytes.Equal(contract.RefundPubKey, pubKey) {
+				return nil, errors.New("Only the refunder can refund")
+			}
+			// Has to be signed with the lock time set
+			trans.LockTime = contract.LockTime
+			b.AddData(wallet.SignTransactionHash(&trans, s.Wallet.Key)).AddInt(0)
+		}
+		trans.Vin[0].UnlockingScript = b.Script()
+		if err := s.Blockchain.CheckMempoolTimeLocks(&trans); err != nil {
+			return nil, errors.New(fmt.Sprintf("Can't refund until after height %d", contract.LockTime))
+		}
+		if err := s.MemPool.CheckStandard(&trans); err != nil {
+			return nil, err
+		}
+		if !s.Blockchain.VerifyTransaction(&trans) {
+			return nil, errors.New("Contract spend is invalid")
+		}
+		if err := s.MemPool.CheckRelayFee(&trans, s.Blockchain.GetTransactionFee(&trans)); err != nil {
+			return nil, err
+		}
+		logging.Wallet.Info("Sending transaction", logging.Tx(chain.GetTransactionHash(&trans)), "fee", in.Fee)
+		s.MemPool.AddTransaction(&trans)
+		reply.TxID = chain.GetTransactionHash(&trans)
+		reply.Inputs = trans.Vin
+		reply.Fee = in.Fee
+		return &trans, nil
+	})
+	return &reply, err
+}
+
+func (s *Server) RedeemHTLC(ctx context.Context, in *pb.HTLCSpend) (*pb.TransactionSent, error) {
+	return s.spendHTLC(in, true)
+}
+
+func (s *Server) RefundHTLC(ctx context.Context, in *pb.HTLCSpend) (*pb.TransactionSent, error) {
+	return s.spendHTLC(in, false)
+}
@@ -0,0 +1,117 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+func newTestChain(t *testing.T) *Server {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 3)
+	return s
+}
+
+// Swap between two separate chains. Switching a node's wallet key
+// stands in for each party having a node on both chains.
+func TestAtomicSwap(t *testing.T) {
+	chainA := newTestChain(t)
+	chainB := newTestChain(t)
+	alice, bob := chainA.Wallet.Key, chainB.Wallet.Key
+	secret := make([]byte, chain.SWAP_SECRET_SIZE)
+	rand.Read(secret)
+	hash := sha256.Sum256(secret)
+
+	// Alice locks 5 on A for Bob
+	initiated, err := chainA.CreateHTLC(context.Background(), &pb.HTLCRequest{RecipientPubKey: chain.GetPubKeyBytes(bob),
+		Value: 5, Hash: hash[:], LockBlocks: 48})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(chainA, t, chainA.Blockchain.NextBlockNum)
+	// Bob checks the terms then locks 4 on B for Alice under the same hash
+	audit, err := chainA.GetHTLC(context.Background(), &pb.HTLCSpend{TxID: initiated.TxID, Index: initiated.Index})
+	if err != nil || audit.Value != 5 || !strings.EqualFold(hex.EncodeToString(audit.Hash), hex.EncodeToString(hash[:])) {
+		t.Fatalf("Audit failed %v %v", audit, err)
+	}
+	participated, err := chainB.CreateHTLC(context.Background(), &pb.HTLCRequest{RecipientPubKey: chain.GetPubKeyBytes(alice),
+		Value: 4, Hash: audit.Hash, LockBlocks: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(chainB, t, chainB.Blockchain.NextBlockNum)
+
+	// Alice redeems on B, revealing the secret
+	chainB.Wallet.Key = alice
+	spend := &pb.HTLCSpend{TxID: participated.TxID, Index: participated.Index, Preimage: make([]byte, chain.SWAP_SECRET_SIZE), Fee: 1}
+	if _, err = chainB.RedeemHTLC(context.Background(), spend); err == nil {
+		t.Error("Should not redeem with the wrong secret")
+	}
+	spend.Preimage = secret
+	if _, err = chainB.RedeemHTLC(context.Background(), spend); err != nil {
+		t.Fatal(err)
+	}
+	chainB.Wallet.Key = bob
+	mineBlocks(chainB, t, chainB.Blockchain.NextBlockNum)
+	if balance := chainB.Blockchain.GetBalance(&alice.PublicKey); balance != 3 {
+		t.Errorf("Alice's balance on B is %d should be 3", balance)
+	}
+
+	// Bob finds the secret on B and redeems on A
+	found, err := chainB.GetHTLC(context.Background(), &pb.HTLCSpend{TxID: participated.TxID, Index: participated.Index})
+	if err != nil || hex.EncodeToString(found.Preimage) != hex.EncodeToString(secret) {
+		t.Fatalf("Secret not found on chain %v", err)
+	}
+	chainA.Wallet.Key = bob
+	if _, err = chainA.RefundHTLC(context.Background(), &pb.HTLCSpend{TxID: initiated.TxID, Index: initiated.Index}); err == nil {
+		t.Error("Only the initiator can refund")
+	}
+	_, err = chainA.RedeemHTLC(context.Background(), &pb.HTLCSpend{TxID: initiated.TxID, Index: initiated.Index,
+		Preimage: found.Preimage, Fee: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainA.Wallet.Key = alice
+	mineBlocks(chainA, t, chainA.Blockchain.NextBlockNum)
+	if balance := chainA.Blockchain.GetBalance(&bob.PublicKey); balance != 4 {
+		t.Errorf("Bob's balance on A is %d should be 4", balance)
+	}
+}
+
+func TestRefundHTLC(t *testing.T) {
+	s := newTestChain(t)
+	hash := sha256.Sum256([]byte("never revealed"))
+	recipient, _ := ecdsa.GenerateKey(s.Wallet.Key.Curve, rand.Reader)
+	created, err := s.CreateHTLC(context.Background(), &pb.HTLCRequest{RecipientPubKey: chain.GetPubKeyBytes(recipient),
+		Value: 2, Hash: hash[:], LockBlocks: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	refund := &pb.HTLCSpend{TxID: created.TxID, Index: created.Index}
+	// Mining may have raced past the lock time already
+	if uint64(s.Blockchain.NextBlockNum) <= created.LockTime {
+		if _, err = s.RefundHTLC(context.Background(), refund); err == nil {
+			t.Errorf("Refunded at height %d, locked until after %d", s.Blockchain.NextBlockNum, created.LockTime)
+		}
+	}
+	mineBlocks(s, t, int(created.LockTime))
+	if _, err = s.RefundHTLC(context.Background(), refund); err != nil {
+		t.Fatal(err)
+	}
+	found, _ := s.GetHTLC(context.Background(), refund)
+	if len(found.SpentBy) == 0 || len(found.Preimage) != 0 {
+		t.Error("Refund should spend the contract without revealing a secret")
+	}
+}
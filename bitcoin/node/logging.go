@@ -0,0 +1,30 @@
+// Admin RPCs for turning a subsystem's logging up or down while the node
+// runs, see the logging package.
+package node
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+)
+
+func (s *Server) SetLogLevel(ctx context.Context, in *pb.LogLevel) (*pb.LogLevels, error) {
+	if err := logging.SetLevel(in.Subsystem, in.Level); err != nil {
+		return nil, err
+	}
+	// Not the subsystem key, that's which logger this is
+	logger := in.Subsystem
+	if logger == "" {
+		logger = "all"
+	}
+	logging.Node.Info("Set log level", "logger", logger, "level", in.Level)
+	return s.GetLogLevels(ctx, &pb.Empty{})
+}
+
+func (s *Server) GetLogLevels(ctx context.Context, in *pb.Empty) (*pb.LogLevels, error) {
+	var reply pb.LogLevels
+	for _, subsystem := range logging.Subsystems() {
+		reply.Levels = append(reply.Levels, &pb.LogLevel{Subsystem: subsystem, Level: logging.GetLevel(subsystem)})
+	}
+	return &reply, nil
+}
@@ -0,0 +1,31 @@
+package node
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	s := newRegtestServer(t)
+	defer logging.SetLevels(logging.DEFAULT_LEVEL)
+	ctx := context.Background()
+	reply, err := s.SetLogLevel(ctx, &pb.LogLevel{Subsystem: "chain", Level: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels := make(map[string]string)
+	for _, level := range reply.Levels {
+		levels[level.Subsystem] = level.Level
+	}
+	if len(levels) != len(logging.Subsystems()) || levels["chain"] != "debug" || levels["p2p"] != "info" {
+		t.Errorf("Only chain should be at debug %v", levels)
+	}
+	if _, err := s.SetLogLevel(ctx, &pb.LogLevel{Level: "warn"}); err != nil || logging.GetLevel("chain") != "warn" {
+		t.Errorf("Every subsystem should be at warn %v", err)
+	}
+	if _, err := s.SetLogLevel(ctx, &pb.LogLevel{Subsystem: "disk", Level: "debug"}); err == nil {
+		t.Error("Expected an unknown subsystem to be rejected")
+	}
+}
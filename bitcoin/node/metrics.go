@@ -0,0 +1,130 @@
+// What a node exposes for Prometheus to scrape with -metrics. Counters
+// are fed from where things happen (receiving blocks and transactions,
+// mining and talking to peers), the state of the chain is read when
+// scraped.
+package node
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	"github.com/connorwstein/Blockchain/bitcoin/metrics"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const METRICS_PATH = "/metrics"
+
+// Why blocks and transactions get turned away, the reason label
+const (
+	REJECT_INVALID      = "invalid"
+	REJECT_DUPLICATE    = "duplicate"
+	REJECT_OUT_OF_ORDER = "out_of_order"
+	REJECT_COINBASE     = "coinbase"
+	REJECT_NONSTANDARD  = "nonstandard"
+	REJECT_TIMELOCK     = "timelock"
+	REJECT_FEE          = "fee"
+	REJECT_CONFLICT     = "conflict"
+)
+
+type nodeMetrics struct {
+	*metrics.Registry
+	blockValidation *metrics.Histogram
+	txValidation    *metrics.Histogram
+	mempoolSize     *metrics.Gauge
+	mempoolBytes    *metrics.Gauge
+	hashes          *metrics.Counter
+	hashRate        *metrics.Gauge
+	blocksFound     *metrics.Counter
+	reorgs          *metrics.Counter
+	messageBytes    *metrics.Counter
+	rejected        *metrics.Counter
+}
+
+func newNodeMetrics(s *Server) *nodeMetrics {
+	r := metrics.NewRegistry()
+	m := &nodeMetrics{Registry: r}
+	// Scraped from the HTTP server's goroutines
+	tip := func() *pb.Block {
+		s.stateLock.RLock()
+		defer s.stateLock.RUnlock()
+		return s.Blockchain.TipsOfChains[0]
+	}
+	r.NewGaugeFunc("bitcoin_chain_height", "Height of the tip, genesis is 1", func() float64 {
+		return float64(tip().Header.Height)
+	})
+	r.NewGaugeFunc("bitcoin_chain_tip_age_seconds", "Seconds since the tip's timestamp", func() float64 {
+		return float64(time.Now().Unix()) - float64(tip().Header.TimeStamp)
+	})
+	m.reorgs = r.NewCounter("bitcoin_chain_reorgs_total", "Times a block from another fork replaced the tip")
+	m.blockValidation = r.NewHistogram("bitcoin_block_validation_seconds", "Time to validate a received block", metrics.LATENCY_BUCKETS)
+	m.txValidation = r.NewHistogram("bitcoin_tx_validation_seconds", "Time to validate a transaction for the mempool", metrics.LATENCY_BUCKETS)
+	m.mempoolSize = r.NewGauge("bitcoin_mempool_transactions", "Transactions waiting to be mined")
+	m.mempoolBytes = r.NewGauge("bitcoin_mempool_bytes", "Serialized size of the mempool")
+	m.hashes = r.NewCounter("bitcoin_miner_hashes_total", "Block hashes tried")
+	m.hashRate = r.NewGauge("bitcoin_miner_hash_rate", "Hashes per second mining the last block")
+	m.blocksFound = r.NewCounter("bitcoin_miner_blocks_found_total", "Blocks we mined")
+	r.NewGaugeFunc("bitcoin_peers", "Connected peers", func() float64 {
+		return float64(s.ConnectedPeers())
+	})
+	m.messageBytes = r.NewCounter("bitcoin_p2p_bytes_total", "Bytes of messages to and from peers", "direction", "type")
+	m.rejected = r.NewCounter("bitcoin_rejected_total", "Blocks and transactions turned away", "object", "reason")
+	return m
+}
+
+// Message type label, e.g. Block for a protos.Block
+func messageType(message proto.Message) string {
+	name := proto.MessageName(message)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func (m *nodeMetrics) received(message proto.Message) {
+	m.messageBytes.Add(float64(proto.Size(message)), "in", messageType(message))
+}
+
+// Counts what we send a peer
+func (m *nodeMetrics) interceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if message, ok := req.(proto.Message); ok {
+			m.messageBytes.Add(float64(proto.Size(message)), "out", messageType(message))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (m *nodeMetrics) rejectBlock(reason string) {
+	m.rejected.Inc("block", reason)
+}
+
+func (m *nodeMetrics) rejectTransaction(reason string) {
+	m.rejected.Inc("transaction", reason)
+}
+
+// After anything goes into or out of the mempool
+func (m *nodeMetrics) setMempool(transactions map[string]*pb.Transaction) {
+	var size uint64
+	for _, transaction := range transactions {
+		size += chain.GetTransactionSize(transaction)
+	}
+	m.mempoolSize.Set(float64(len(transactions)))
+	m.mempoolBytes.Set(float64(size))
+}
+
+func (m *nodeMetrics) mined(hashes uint64, elapsed time.Duration) {
+	m.hashes.Add(float64(hashes))
+	if elapsed > 0 {
+		m.hashRate.Set(float64(hashes) / elapsed.Seconds())
+	}
+}
+
+// Blocks until the listener fails
+func (s *Server) ServeMetrics(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle(METRICS_PATH, s.metrics)
+	logging.Node.Info("Serving metrics", "address", address, "path", METRICS_PATH)
+	return http.ListenAndServe(address, mux)
+}
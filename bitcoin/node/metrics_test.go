@@ -0,0 +1,64 @@
+package node
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Wallet.CreateKey()
+	blocks, err := s.Generate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s.ReceiveTransaction(ctx, &pb.Transaction{Vout: []*pb.TXO{{Value: 1}}})
+	s.ReceiveBlock(ctx, blocks[0])
+	// Another node's fork one longer, we get its last block
+	other := newRegtestServer(t)
+	other.Wallet.CreateKey()
+	fork, err := other.Generate(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Its parent is unknown, so it's not counted as a reorg
+	s.ReceiveBlock(ctx, fork[2])
+	// One built on a block of ours below the tip is
+	s.stateLock.Lock()
+	s.countReorg(&pb.Block{Header: &pb.BlockHeader{PrevBlockHash: chain.GetBlockHash(blocks[0])}})
+	s.stateLock.Unlock()
+	// What gets sent to a peer
+	invoke := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+	s.metrics.interceptor()(ctx, "/protos.Blocks/ReceiveBlock", blocks[1], &pb.Empty{}, nil, invoke)
+	recorder := httptest.NewRecorder()
+	s.metrics.ServeHTTP(recorder, httptest.NewRequest("GET", METRICS_PATH, nil))
+	scraped := recorder.Body.String()
+	for _, line := range []string{
+		"bitcoin_chain_height 4",
+		"bitcoin_chain_reorgs_total 1",
+		"bitcoin_miner_blocks_found_total 2",
+		"bitcoin_mempool_transactions 0",
+		"bitcoin_peers 0",
+		"bitcoin_block_validation_seconds_count 2",
+		"bitcoin_tx_validation_seconds_count 1",
+		`bitcoin_rejected_total{object="transaction",reason="coinbase"} 1`,
+		`bitcoin_p2p_bytes_total{direction="in",type="Transaction"}`,
+		`bitcoin_p2p_bytes_total{direction="out",type="Block"}`,
+	} {
+		if !strings.Contains(scraped, "\n"+line) {
+			t.Errorf("Missing %s in\n%s", line, scraped)
+		}
+	}
+	// Mining checks a hash at least once per block
+	if strings.Contains(scraped, "\nbitcoin_miner_hashes_total 0\n") || !strings.Contains(scraped, `bitcoin_rejected_total{object="block"`) {
+		t.Errorf("Hashes and the resent block should be counted\n%s", scraped)
+	}
+}
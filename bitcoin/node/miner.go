@@ -0,0 +1,339 @@
+// Need functions to mine blocks
+// accumultate transactions into blocks
+// and broadcast new blocks
+package node
+
+import (
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+	"net"
+	"sync"
+	"time"
+)
+
+// Miner states. Stopped -> running -> stopping -> stopped for background
+// mining, stopped -> generating -> stopped for GenerateBlocks, which also
+// goes through stopping if it is cancelled
+const (
+	MINING_STOPPED    = "stopped"
+	MINING_RUNNING    = "running"
+	MINING_STOPPING   = "stopping"
+	MINING_GENERATING = "generating"
+)
+
+type miner struct {
+	sync.Mutex
+	state       string
+	stop        chan struct{} // Closed to cancel whatever is mining
+	done        chan struct{} // Closed once it has finished
+	blocksMined uint64
+	startTime   time.Time
+}
+
+func newMiner() *miner {
+	return &miner{state: MINING_STOPPED}
+}
+
+// Move from stopped to state, returning the channels to cancel it and
+// which close once it is done
+func (m *miner) start(state string) (chan struct{}, chan struct{}, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.state != MINING_STOPPED {
+		return nil, nil, errors.New(fmt.Sprintf("Miner is already %s", m.state))
+	}
+	m.state = state
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	m.blocksMined = 0
+	m.startTime = time.Now()
+	return m.stop, m.done, nil
+}
+
+// Ask whatever is mining to stop, returning a channel closed once it has.
+// Nil if nothing is mining
+func (m *miner) cancel() chan struct{} {
+	m.Lock()
+	defer m.Unlock()
+	if m.state == MINING_STOPPED {
+		return nil
+	}
+	m.cancelLocked()
+	return m.done
+}
+
+// Only stop if done's mining hasn't finished, so a late cancel can't stop
+// whatever started next
+func (m *miner) cancelIf(done chan struct{}) {
+	m.Lock()
+	defer m.Unlock()
+	if m.done == done {
+		m.cancelLocked()
+	}
+}
+
+func (m *miner) cancelLocked() {
+	if m.state == MINING_RUNNING || m.state == MINING_GENERATING {
+		close(m.stop)
+		m.state = MINING_STOPPING
+	}
+}
+
+func (m *miner) mined() {
+	m.Lock()
+	defer m.Unlock()
+	m.blocksMined++
+}
+
+func (m *miner) finish() {
+	m.Lock()
+	defer m.Unlock()
+	m.state = MINING_STOPPED
+	close(m.done)
+}
+
+func (s *Server) mineBlock(block *pb.Block, stop <-chan struct{}) bool {
+	var hashes uint64
+	defer func(start time.Time) { s.metrics.mined(hashes, time.Since(start)) }(time.Now())
+	target := chain.GetTargetFromCompact(block.Header.DifficultyTarget)
+	// Increment the nonce until the hash starts with some
+	// leading zeroes (depends on the difficulty)
+	for {
+		select {
+		case <-stop:
+			logging.Miner.Debug("Abandoned block", "height", block.Header.Height, "hashes", hashes)
+			return false
+		default:
+			hashes++
+			if !chain.CheckHashMined(target, chain.GetBlockHash(block)) {
+				// Increment the nonce, append the block data to it then hash it
+				block.Header.Nonce += 1
+			} else {
+				logging.Miner.Info("Mined block", logging.Block(chain.GetBlockHash(block)), "height", block.Header.Height,
+					"transactions", len(block.Transactions), "hashes", hashes)
+				return true
+			}
+		}
+		time.Sleep(time.Duration(s.config.MineSpeed) * time.Millisecond)
+	}
+}
+
+func (s *Server) StartMining(ctx context.Context, in *pb.Empty) (*pb.Empty, error) {
+	var reply pb.Empty
+	if s.Wallet.Key == nil {
+		logging.Wallet.Warn("Need to create an account first")
+		return &reply, nil
+	}
+	stop, _, err := s.miner.start(MINING_RUNNING)
+	if err != nil {
+		logging.Miner.Info("Can't start mining", "err", err)
+		return &reply, err
+	}
+	go s.mine(chain.GetPubKeyBytes(s.Wallet.Key), stop)
+	return &reply, nil
+}
+
+// Doesn't return until the current block has been abandoned, so no more
+// blocks get added after it
+func (s *Server) StopMining(ctx context.Context, in *pb.Empty) (*pb.Empty, error) {
+	var reply pb.Empty
+	done := s.miner.cancel()
+	if done == nil {
+		logging.Miner.Debug("Already not mining")
+		return &reply, nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return &reply, ctx.Err()
+	}
+	return &reply, nil
+}
+
+func (s *Server) GetMiningStatus(ctx context.Context, in *pb.Empty) (*pb.MiningStatus, error) {
+	s.miner.Lock()
+	status := pb.MiningStatus{State: s.miner.state, BlocksMined: s.miner.blocksMined}
+	if s.miner.state != MINING_STOPPED {
+		status.StartTime = uint64(s.miner.startTime.Unix())
+	}
+	s.miner.Unlock()
+	s.stateLock.RLock()
+	tip := s.Blockchain.TipsOfChains[0]
+	s.stateLock.RUnlock()
+	status.Height = tip.Header.Height
+	status.Tip = chain.GetBlockHash(tip)
+	return &status, nil
+}
+
+func (s *Server) GenerateBlocks(ctx context.Context, in *pb.GenerateRequest) (*pb.GeneratedBlocks, error) {
+	var reply pb.GeneratedBlocks
+	var pubKey []byte
+	if in.Address != "" {
+		var err error
+		if pubKey, err = s.Blockchain.Params.GetPubKeyFromAddress(in.Address); err != nil {
+			return nil, err
+		}
+	} else if s.Wallet.Key != nil {
+		pubKey = chain.GetPubKeyBytes(s.Wallet.Key)
+	} else {
+		return nil, errors.New("Need an address or to create an account first")
+	}
+	blocks, err := s.generate(ctx, int(in.Blocks), pubKey)
+	for _, block := range blocks {
+		reply.Hashes = append(reply.Hashes, chain.GetBlockHash(block))
+	}
+	return &reply, err
+}
+
+// Go routine to continuously mine while still accumulating blocks in the mempool
+// note that we can mine a block without anything in the block and still get paid
+// Next block on our tip paying us, with whatever is in the mempool right now
+func (s *Server) createBlock(pubKey []byte) *pb.Block {
+	var newBlock pb.Block
+	var newBlockHeader pb.BlockHeader
+	newBlock.Header = &newBlockHeader
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	prevBlock := s.Blockchain.TipsOfChains[0]
+	newBlock.Header.TimeStamp = s.Blockchain.GetNextBlockTime(prevBlock)
+	newBlock.Header.PrevBlockHash = chain.GetBlockHash(prevBlock)
+	newBlock.Header.Height = uint64(s.Blockchain.NextBlockNum)
+	newBlock.Header.DifficultyTarget = chain.GetCompactTarget(s.Blockchain.GetNextTarget(prevBlock))
+	newBlock.Transactions = make([]*pb.Transaction, 0)
+	var mint pb.Transaction
+	// Coinbase transaction is actually unsigned
+	var TXO pb.TXO
+	TXO.ReceiverPubKey = pubKey
+	TXO.Value = s.Blockchain.GetBlockSubsidy(newBlock.Header.Height)
+	mint.Height = uint64(s.Blockchain.NextBlockNum)
+	mint.Vout = make([]*pb.TXO, 0)
+	mint.Vout = append(mint.Vout, &TXO)
+	newBlock.Transactions = append(newBlock.Transactions, &mint)
+	// Now add all the other ones (could be empty), collecting their fees.
+	// Leave any which are still time locked at this height, or don't fit, for a later block.
+	// Only one of a pair spending the same output can go in, the block would be invalid otherwise
+	spent := make(map[string]bool)
+	medianTime := s.Blockchain.GetMedianTimePast(prevBlock)
+	// Leaving room for the merkle root which isn't set yet
+	blockSize := chain.GetBlockSize(&newBlock) + 32
+	for _, transaction := range s.MemPool.Transactions {
+		if len(newBlock.Transactions) == chain.MAX_BLOCK_TRANSACTIONS {
+			break
+		}
+		size := chain.GetTransactionSize(transaction) + chain.BLOCK_TX_OVERHEAD
+		if blockSize+size > chain.MAX_BLOCK_SIZE {
+			continue
+		}
+		if !s.Blockchain.VerifyTransactionAt(transaction, newBlock.Header.Height, medianTime) {
+			continue
+		}
+		conflict := false
+		for _, txi := range transaction.Vin {
+			conflict = conflict || spent[fmt.Sprintf("%x:%d", txi.TxID, txi.Index)]
+		}
+		if conflict {
+			continue
+		}
+		for _, txi := range transaction.Vin {
+			spent[fmt.Sprintf("%x:%d", txi.TxID, txi.Index)] = true
+		}
+		newBlock.Transactions = append(newBlock.Transactions, transaction)
+		blockSize += size
+		TXO.Value += s.Blockchain.GetTransactionFee(transaction)
+	}
+	newBlock.Header.MerkleRoot = chain.GetMerkleRoot(newBlock.Transactions)
+	return &newBlock
+}
+
+// Add a block we mined to our chain and send it to our peers
+func (s *Server) acceptMinedBlock(newBlock *pb.Block) {
+	// With a successfully mined block we can clear the mempool of ONLY the
+	// transactions we mined (others could have accumulated while we were mining)
+	s.stateLock.Lock()
+	for i := range newBlock.Transactions {
+		delete(s.MemPool.Transactions, string(chain.GetTransactionHash(newBlock.Transactions[i])))
+	}
+	s.metrics.setMempool(s.MemPool.Transactions)
+	s.metrics.blocksFound.Inc()
+	// A peer's block can come in while we mine
+	s.countReorg(newBlock)
+	s.Blockchain.AddBlock(newBlock)
+	s.storeBlock(newBlock)
+	s.stateLock.Unlock()
+	// Broadcast this block
+	// Send block to all peers. Block is valid since we just mined it
+	for _, myPeer := range s.getPeers() {
+		// Find which one of our IP addresses is in the same network as the peer
+		ipAddr, _ := net.ResolveIPAddr("ip", myPeer.SourceIP)
+		// This cast works because ipAddr is a pointer and the pointer to ipAddr does implement
+		// the Addr interface
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: ipAddr})
+		c := pb.NewBlocksClient(myPeer.Conn)
+		_, err := c.ReceiveBlock(ctx, newBlock)
+		logging.P2P.Debug("Sent block", logging.Block(chain.GetBlockHash(newBlock)), "peer", myPeer.PeerIP, "err", err)
+	}
+}
+
+func (s *Server) mine(pubKey []byte, stop chan struct{}) {
+	// Take whatever is in the mempool right now and start mining it in a block
+	logging.Miner.Info("Started mining")
+	defer s.miner.finish()
+	for {
+		newBlock := s.createBlock(pubKey)
+		// Blocks until mining is complete or we are stopped
+		// Need a way to abort if a new block at the same number is received while mining
+		// TODO: need to support 2 miners
+		result := s.mineBlock(newBlock, stop)
+		// After mining we cannot modify the block, otherwise its hash will no longer
+		// be valid
+		if !result {
+			logging.Miner.Info("Stopped mining")
+			return
+		}
+		s.acceptMinedBlock(newBlock)
+		s.miner.mined()
+	}
+}
+
+// Mine n blocks to pubKey straight away, meant for regtest where the
+// target is trivial so tests can make blocks whenever they need them.
+// Cancelling ctx or StopMining returns the blocks mined so far
+func (s *Server) generate(ctx context.Context, n int, pubKey []byte) ([]*pb.Block, error) {
+	stop, done, err := s.miner.start(MINING_GENERATING)
+	if err != nil {
+		return nil, err
+	}
+	defer s.miner.finish()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.miner.cancelIf(done)
+		case <-done:
+		}
+	}()
+	var blocks []*pb.Block
+	for i := 0; i < n; i++ {
+		newBlock := s.createBlock(pubKey)
+		// Regtest blocks can be quicker than noticing the cancel
+		if ctx.Err() != nil || !s.mineBlock(newBlock, stop) {
+			return blocks, errors.New(fmt.Sprintf("Stopped after generating %d of %d blocks", len(blocks), n))
+		}
+		s.acceptMinedBlock(newBlock)
+		s.miner.mined()
+		blocks = append(blocks, newBlock)
+	}
+	return blocks, nil
+}
+
+// Generate paying our wallet
+func (s *Server) Generate(n int) ([]*pb.Block, error) {
+	if s.Wallet.Key == nil {
+		return nil, errors.New("Need to create an account first")
+	}
+	return s.generate(context.Background(), n, chain.GetPubKeyBytes(s.Wallet.Key))
+}
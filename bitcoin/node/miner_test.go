@@ -0,0 +1,229 @@
+package node
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mineBlockHelper(s *Server, minChainLength int) error {
+	timeout := time.After(30 * time.Second)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	// ticker.C is a channel of Time instants
+	for mined := false; !mined; {
+		select {
+		case <-timeout:
+			// If our timeout Time instant appears on that channel
+			// it is time to end
+			return errors.New("Failed to mine block in time")
+		case <-ticker.C:
+			// Check if we have mined a block
+			// if so we are done
+			s.stateLock.RLock()
+			mined = len(s.Blockchain.Blocks) >= minChainLength
+			s.stateLock.RUnlock()
+		}
+	}
+	return nil
+}
+
+// Mine at least minChainLength blocks, fail the
+// test if anything bad happens
+func mineBlocks(s *Server, t *testing.T, minChainLength int) {
+	_, err := s.StartMining(context.Background(), &pb.Empty{})
+	if err != nil {
+		t.Fail()
+	}
+	// 2 = genesis block as well as our new block
+	if err = mineBlockHelper(s, minChainLength); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+	_, err = s.StopMining(context.Background(), &pb.Empty{})
+	if err != nil {
+		t.Fail()
+	}
+}
+
+// Check balance updates upon mining
+func TestMineBlock(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	// Relax difficulty for this
+	target, _ := hex.DecodeString(strings.Join([]string{"e", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	mineBlocks(s, t, 3)
+	balance := int(s.Blockchain.GetBalance(&s.Wallet.Key.PublicKey))
+	numBlocks := len(s.Blockchain.Blocks)
+	if balance != (numBlocks-1)*chain.BLOCK_REWARD {
+		t.Logf("Balance is %d, should be %d", balance, (numBlocks-1)*chain.BLOCK_REWARD)
+		t.Fail()
+	}
+}
+
+func TestMiningStatus(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Wallet.CreateKey()
+	status, _ := s.GetMiningStatus(context.Background(), &pb.Empty{})
+	if status.State != MINING_STOPPED || status.Height != 1 {
+		t.Errorf("New node status %v", status)
+	}
+	if _, err := s.StartMining(context.Background(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartMining(context.Background(), &pb.Empty{}); err == nil {
+		t.Error("Starting twice should fail")
+	}
+	if _, err := s.Generate(1); err == nil {
+		t.Error("Generating while mining should fail")
+	}
+	if err := mineBlockHelper(s, 3); err != nil {
+		t.Fatal(err)
+	}
+	status, _ = s.GetMiningStatus(context.Background(), &pb.Empty{})
+	if status.State != MINING_RUNNING || status.BlocksMined < 2 || status.StartTime == 0 {
+		t.Errorf("Mining status %v", status)
+	}
+	s.StopMining(context.Background(), &pb.Empty{})
+	// Nothing gets added once stopped
+	numBlocks := len(s.Blockchain.Blocks)
+	time.Sleep(50 * time.Millisecond)
+	status, _ = s.GetMiningStatus(context.Background(), &pb.Empty{})
+	if status.State != MINING_STOPPED || len(s.Blockchain.Blocks) != numBlocks || int(status.Height) != numBlocks {
+		t.Errorf("Stopped status %v with %d blocks", status, len(s.Blockchain.Blocks))
+	}
+	// Stopping again doesn't block
+	if _, err := s.StopMining(context.Background(), &pb.Empty{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGenerateBlocks(t *testing.T) {
+	s := newRegtestServer(t)
+	if _, err := s.GenerateBlocks(context.Background(), &pb.GenerateRequest{Blocks: 1}); err == nil {
+		t.Error("Generating without an address or account should fail")
+	}
+	other := newRegtestServer(t)
+	other.Wallet.CreateKey()
+	address := s.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(other.Wallet.Key))
+	reply, err := s.GenerateBlocks(context.Background(), &pb.GenerateRequest{Blocks: 5, Address: address})
+	if err != nil || len(reply.Hashes) != 5 {
+		t.Fatalf("Generated %v %v", reply, err)
+	}
+	tip := s.Blockchain.TipsOfChains[0]
+	if !bytes.Equal(reply.Hashes[4], chain.GetBlockHash(tip)) || tip.Header.Height != 6 {
+		t.Errorf("Last hash %x is not the tip at height %d", reply.Hashes[4], tip.Header.Height)
+	}
+	if balance := s.Blockchain.GetBalance(&other.Wallet.Key.PublicKey); balance != 5*chain.BLOCK_REWARD {
+		t.Errorf("Address got %d", balance)
+	}
+	if _, err = s.GenerateBlocks(context.Background(), &pb.GenerateRequest{Blocks: 1, Address: "t123"}); err == nil {
+		t.Error("Testnet address on regtest should fail")
+	}
+	// A cancelled request stops straight away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reply, err = s.GenerateBlocks(ctx, &pb.GenerateRequest{Blocks: 1000, Address: address})
+	if err == nil || len(reply.Hashes) == 1000 {
+		t.Errorf("Cancelled generate made %d blocks %v", len(reply.Hashes), err)
+	}
+	status, _ := s.GetMiningStatus(context.Background(), &pb.Empty{})
+	if status.State != MINING_STOPPED {
+		t.Errorf("Miner is %s after generating", status.State)
+	}
+}
+
+// Two mempool transactions spending the same output can't both go in a block
+func TestCreateBlockConflicts(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Wallet.CreateKey()
+	s.Blockchain.CoinbaseMaturity = 1
+	if _, err := s.Generate(2); err != nil {
+		t.Fatal(err)
+	}
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	utxo := s.Blockchain.GetUTXOs(&s.Wallet.Key.PublicKey)[0]
+	txID := chain.GetTransactionHash(utxo.Transaction)
+	// Differ only in value so both are valid on their own. Straight into the
+	// mempool as if they'd got in before the policy caught up
+	for _, value := range []uint64{1, 2} {
+		spend := pb.Transaction{Vin: []*pb.TXI{&pb.TXI{TxID: txID, Index: uint64(utxo.Index)}},
+			Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(receiverKey), Value: value}}}
+		wallet.SignTransaction(&spend, s.Wallet.Key)
+		if !s.Blockchain.VerifyTransaction(&spend) {
+			t.Fatalf("Spend of %d should be valid", value)
+		}
+		s.MemPool.AddTransaction(&spend)
+	}
+	block := s.createBlock(chain.GetPubKeyBytes(s.Wallet.Key))
+	if len(block.Transactions) != 2 {
+		t.Errorf("Block has %d transactions, should be the coinbase and one spend", len(block.Transactions))
+	}
+	s.mineBlock(block, nil)
+	if !s.Blockchain.BlockIsValid(block) {
+		t.Error("Block with one of the conflicting spends should be valid")
+	}
+	// The other one is left behind
+	s.acceptMinedBlock(block)
+	if len(s.MemPool.Transactions) != 1 || s.Blockchain.NextBlockNum != 5 {
+		t.Errorf("Mempool has %d transactions at height %d", len(s.MemPool.Transactions), s.Blockchain.NextBlockNum)
+	}
+}
+
+// A block is checked against the target due after its own parent, not
+// whatever is due on our tip
+func TestBlockTarget(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Wallet.CreateKey()
+	// Blocks all in the same second so the target drops by 4 after height 6.
+	// The first interval starts at genesis, long ago, so stays at the limit
+	s.Blockchain.Params.RetargetInterval = 3
+	s.Blockchain.Params.TargetSpacing = 600
+	blocks, err := s.Generate(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := blocks[0].Header.DifficultyTarget
+	if blocks[4].Header.DifficultyTarget != start || blocks[5].Header.DifficultyTarget == start {
+		t.Fatalf("Targets %08x %08x %08x should retarget after height 6", start,
+			blocks[4].Header.DifficultyTarget, blocks[5].Header.DifficultyTarget)
+	}
+	tipBits := chain.GetCompactTarget(s.Blockchain.Target)
+	if tipBits != blocks[5].Header.DifficultyTarget {
+		t.Errorf("Tip target %08x should be %08x", tipBits, blocks[5].Header.DifficultyTarget)
+	}
+	on := func(parent *pb.Block, bits uint32) *pb.Block {
+		coinbase := pb.Transaction{Height: parent.Header.Height + 1,
+			Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(s.Wallet.Key), Value: 1}}}
+		block := pb.Block{Header: &pb.BlockHeader{PrevBlockHash: chain.GetBlockHash(parent), Height: parent.Header.Height + 1,
+			TimeStamp: s.Blockchain.GetNextBlockTime(parent), DifficultyTarget: bits}, Transactions: []*pb.Transaction{&coinbase}}
+		block.Header.MerkleRoot = chain.GetMerkleRoot(block.Transactions)
+		s.mineBlock(&block, make(chan struct{}))
+		return &block
+	}
+	// Before the retarget the starting target is due
+	if !s.Blockchain.BlockIsValid(on(blocks[3], start)) {
+		t.Error("Block at the starting target on height 6 should be valid")
+	}
+	if s.Blockchain.BlockIsValid(on(blocks[3], tipBits)) {
+		t.Error("Block at the tip's target on height 6 should be invalid")
+	}
+	// And after it only the new one
+	if s.Blockchain.BlockIsValid(on(blocks[5], start)) {
+		t.Error("Block at the starting target on height 8 should be invalid")
+	}
+	if !s.Blockchain.BlockIsValid(on(blocks[5], tipBits)) {
+		t.Error("Block at the new target on height 8 should be valid")
+	}
+}
@@ -0,0 +1,52 @@
+// RPCs for creating and watching multisig addresses, spending from
+// them goes through the partially signed transaction RPCs.
+package node
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+)
+
+func (s *Server) getMultisigAddress(script []byte) *pb.MultisigAddress {
+	m, pubKeys, _ := chain.ParseMultisigScript(script)
+	var balance uint64
+	s.stateLock.RLock()
+	for _, utxo := range s.Blockchain.GetScriptUTXOs(script) {
+		balance += utxo.Value()
+	}
+	s.stateLock.RUnlock()
+	return &pb.MultisigAddress{Address: chain.GetScriptAddress(script), LockingScript: script,
+		Required: uint32(m), PubKeys: pubKeys, Balance: balance}
+}
+
+func (s *Server) CreateMultisig(ctx context.Context, in *pb.MultisigRequest) (*pb.MultisigAddress, error) {
+	if len(in.PubKeys) == 0 || len(in.PubKeys) > 16 {
+		return &pb.MultisigAddress{}, errors.New("Need between 1 and 16 pubkeys")
+	}
+	if in.Required == 0 || int(in.Required) > len(in.PubKeys) {
+		return &pb.MultisigAddress{}, errors.New(fmt.Sprintf("Required signatures must be between 1 and %d", len(in.PubKeys)))
+	}
+	for _, pubKey := range in.PubKeys {
+		if len(pubKey) <= 32 {
+			return &pb.MultisigAddress{}, errors.New("Invalid public key " + hex.EncodeToString(pubKey))
+		}
+	}
+	script := chain.MultisigScript(int(in.Required), in.PubKeys)
+	s.Wallet.WatchScript(script)
+	logging.Wallet.Info("Watching multisig", "required", in.Required, "keys", len(in.PubKeys), "script", hex.EncodeToString(script))
+	return s.getMultisigAddress(script), nil
+}
+
+func (s *Server) GetMultisigAddresses(in *pb.Empty, stream pb.Wallet_GetMultisigAddressesServer) error {
+	for _, script := range s.Wallet.Scripts {
+		if err := stream.Send(s.getMultisigAddress(script)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
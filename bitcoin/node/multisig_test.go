@@ -0,0 +1,92 @@
+package node
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+// 2 of 3 where the node holds one key and the others are held elsewhere
+func TestMultisigSpend(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 3)
+	officer2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	officer3, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	req := pb.MultisigRequest{Required: 2, PubKeys: [][]byte{chain.GetPubKeyBytes(s.Wallet.Key),
+		chain.GetPubKeyBytes(officer2), chain.GetPubKeyBytes(officer3)}}
+	address, err := s.CreateMultisig(context.Background(), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script, err := chain.GetScriptFromAddress(address.Address); err != nil || !bytes.Equal(script, address.LockingScript) {
+		t.Errorf("Multisig address %s should be its script %v", address.Address, err)
+	}
+	if _, err = s.CreateMultisig(context.Background(), &pb.MultisigRequest{Required: 4, PubKeys: req.PubKeys}); err == nil {
+		t.Error("Can't require more signatures than keys")
+	}
+	_, err = s.SendTransaction(context.Background(), &pb.TransactionRequest{
+		Outputs: []*pb.TXO{&pb.TXO{LockingScript: address.LockingScript, Value: 9}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	if balance := s.getMultisigAddress(address.LockingScript).Balance; balance != 9 {
+		t.Fatalf("Multisig balance is %d should be 9", balance)
+	}
+
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	spend := pb.TransactionRequest{Value: 5, ReceiverPubKey: chain.GetPubKeyBytes(receiverKey),
+		SenderScript: address.LockingScript, Fee: 1}
+	if _, err = s.SendTransaction(context.Background(), &spend); err == nil {
+		t.Error("Spending from a script should need a partially signed transaction")
+	}
+	psbt, err := s.CreateTransaction(context.Background(), &spend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each officer signs their own copy, as if on different machines
+	signedByNode, err := s.SignTransaction(context.Background(), &pb.PartialTransaction{Transaction: psbt.Transaction, Spending: psbt.Spending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.FinalizeTransaction(context.Background(), signedByNode); err == nil {
+		t.Error("One signature should not be enough")
+	}
+	signedByOfficer := &pb.PartialTransaction{Transaction: psbt.Transaction, Spending: psbt.Spending}
+	if err = wallet.AddPartialSignature(signedByOfficer, officer3); err != nil {
+		t.Fatal(err)
+	}
+	outsider, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err = wallet.AddPartialSignature(signedByOfficer, outsider); err == nil {
+		t.Error("Key outside the multisig should not sign")
+	}
+	combined, err := s.CombineTransactions(context.Background(), &pb.PartialTransactions{
+		Transactions: []*pb.PartialTransaction{signedByNode, signedByOfficer}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.FinalizeTransaction(context.Background(), combined); err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	if balance := s.Blockchain.GetBalance(&receiverKey.PublicKey); balance != 5 {
+		t.Errorf("Receiver balance is %d should be 5", balance)
+	}
+	// Change goes back to the multisig
+	if balance := s.getMultisigAddress(address.LockingScript).Balance; balance != 3 {
+		t.Errorf("Multisig balance is %d should be 3", balance)
+	}
+}
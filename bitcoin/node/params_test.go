@@ -0,0 +1,55 @@
+package node
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/config"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+func newRegtestServer(t *testing.T) *Server {
+	cfg := config.Default()
+	cfg.Network = "regtest"
+	cfg.MineSpeed = 0
+	s, err := NewServerFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRegtestGenerate(t *testing.T) {
+	s := newRegtestServer(t)
+	if _, err := s.Generate(1); err == nil {
+		t.Error("Generating without a key should fail")
+	}
+	if _, err := s.GetAddress(context.Background(), &pb.Empty{}); err == nil {
+		t.Error("Address without a key should fail")
+	}
+	s.Wallet.CreateKey()
+	blocks, err := s.Generate(3)
+	if err != nil || len(blocks) != 3 {
+		t.Fatalf("Generated %d blocks %v", len(blocks), err)
+	}
+	// Genesis plus the 3 new ones
+	if len(s.Blockchain.Blocks) != 4 || blocks[2].Header.Height != 4 {
+		t.Errorf("Chain has %d blocks, tip height %d", len(s.Blockchain.Blocks), blocks[2].Header.Height)
+	}
+	if balance := s.Blockchain.GetBalance(&s.Wallet.Key.PublicKey); balance != 3*chain.BLOCK_REWARD {
+		t.Errorf("Balance %d after 3 blocks", balance)
+	}
+}
+
+func TestPeerNetwork(t *testing.T) {
+	s := newRegtestServer(t)
+	hello := &pb.Hello{Network: chain.MAINNET.Name, GenesisHash: chain.MAINNET.GenesisHash()}
+	if _, err := s.Connect(context.Background(), hello); err == nil {
+		t.Error("Regtest node should refuse a mainnet peer")
+	}
+	hello = &pb.Hello{Network: chain.REGTEST.Name, GenesisHash: chain.REGTEST.GenesisHash()}
+	ack, err := s.Connect(context.Background(), hello)
+	if err != nil || ack.Network != chain.REGTEST.Name {
+		t.Errorf("Regtest peer got %v %v", ack, err)
+	}
+}
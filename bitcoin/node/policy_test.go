@@ -0,0 +1,83 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/mempool"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+func TestMemPoolPolicy(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	s.MemPool.MinRelayFeeRate = 1
+	mineBlocks(s, t, 3)
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	receiver := chain.GetPubKeyBytes(receiverKey)
+	utxo := s.Blockchain.GetUTXOs(&s.Wallet.Key.PublicKey)[0]
+	spend := func(outputs []*pb.TXO) error {
+		trans := pb.Transaction{Vin: []*pb.TXI{&pb.TXI{TxID: chain.GetTransactionHash(utxo.Transaction), Index: uint64(utxo.Index)}},
+			Vout: outputs}
+		wallet.SignTransaction(&trans, s.Wallet.Key)
+		_, err := s.ReceiveTransaction(context.Background(), &trans)
+		return err
+	}
+	var many []*pb.TXO
+	for i := 0; i <= mempool.MAX_STANDARD_OUTPUTS; i++ {
+		many = append(many, &pb.TXO{LockingScript: chain.DataScript(nil)})
+	}
+	cases := []struct {
+		outputs []*pb.TXO
+		reason  string
+	}{
+		{[]*pb.TXO{&pb.TXO{ReceiverPubKey: receiver, Value: 1}}, "dust"},
+		{[]*pb.TXO{&pb.TXO{ReceiverPubKey: receiver, Value: utxo.Value()}}, "minimum relay fee"},
+		{[]*pb.TXO{&pb.TXO{LockingScript: make([]byte, mempool.MAX_STANDARD_TX_SIZE)}}, "bytes"},
+		{many, "outputs"},
+	}
+	for _, c := range cases {
+		if err := spend(c.outputs); err == nil || !strings.Contains(err.Error(), c.reason) {
+			t.Errorf("Expected rejection for %s got %v", c.reason, err)
+		}
+	}
+	if len(s.MemPool.Transactions) != 0 {
+		t.Errorf("%d non standard transactions in the mempool", len(s.MemPool.Transactions))
+	}
+	// Paying the fee and leaving data outputs empty is fine
+	if err := spend([]*pb.TXO{&pb.TXO{ReceiverPubKey: receiver, Value: utxo.Value() - 1},
+		&pb.TXO{LockingScript: chain.DataScript([]byte("memo"))}}); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.SendTransaction(context.Background(), &pb.TransactionRequest{Value: 1, ReceiverPubKey: receiver, FeeRate: 1}); err == nil {
+		t.Error("Should not send dust")
+	}
+	// Something else spending the same output is turned away, however it pays
+	if err := spend([]*pb.TXO{&pb.TXO{ReceiverPubKey: receiver, Value: utxo.Value() - 2}}); err == nil ||
+		!strings.Contains(err.Error(), "already spent") {
+		t.Errorf("Expected a conflict got %v", err)
+	}
+	if len(s.MemPool.Transactions) != 1 {
+		t.Errorf("%d transactions in the mempool, should just be the first spend", len(s.MemPool.Transactions))
+	}
+	// As is listing the same input twice ourselves
+	other := s.Blockchain.GetUTXOs(&s.Wallet.Key.PublicKey)[1]
+	input := &pb.TXI{TxID: chain.GetTransactionHash(other.Transaction), Index: uint64(other.Index)}
+	req := pb.TransactionRequest{Value: other.Value(), ReceiverPubKey: receiver, Inputs: []*pb.TXI{input, input}, FeeRate: 1}
+	if _, err := s.SendTransaction(context.Background(), &req); err == nil {
+		t.Error("Should not send a transaction spending an input twice")
+	}
+	if len(s.MemPool.Transactions) != 1 {
+		t.Errorf("%d transactions in the mempool after the duplicate inputs", len(s.MemPool.Transactions))
+	}
+}
@@ -0,0 +1,129 @@
+// RPCs for the partially signed transaction flow:
+// CreateTransaction -> SignTransaction (here or offline) ->
+// CombineTransactions (if signatures were collected separately) ->
+// FinalizeTransaction which broadcasts it
+package node
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+)
+
+func (s *Server) CreateTransaction(ctx context.Context, in *pb.TransactionRequest) (*pb.PartialTransaction, error) {
+	var reply pb.PartialTransaction
+	sender := in.SenderPubKey
+	if len(sender) == 0 && len(in.SenderScript) == 0 {
+		if s.Wallet.Key == nil {
+			return &reply, errors.New("Need to make an account first or give a sender")
+		}
+		sender = chain.GetPubKeyBytes(s.Wallet.Key)
+	}
+	if len(in.SenderScript) == 0 && len(sender) <= 32 {
+		return &reply, errors.New("Invalid sender public key")
+	}
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	trans, _, err := s.buildTransaction(in, sender)
+	if err != nil {
+		return &reply, err
+	}
+	reply.Transaction = trans
+	for _, txi := range trans.Vin {
+		reply.Spending = append(reply.Spending, s.Blockchain.GetTransaction(txi.TxID).Vout[txi.Index])
+	}
+	logging.Wallet.Info("Created unsigned transaction", logging.Tx(chain.GetTransactionHash(trans)))
+	return &reply, nil
+}
+
+// Sign with the wallet key, which has to own at least one of the inputs
+func (s *Server) SignTransaction(ctx context.Context, in *pb.PartialTransaction) (*pb.PartialTransaction, error) {
+	if s.Wallet.Key == nil {
+		return in, errors.New("Need to make an account first")
+	}
+	if err := wallet.AddPartialSignature(in, s.Wallet.Key); err != nil {
+		return in, err
+	}
+	return in, nil
+}
+
+func (s *Server) CombineTransactions(ctx context.Context, in *pb.PartialTransactions) (*pb.PartialTransaction, error) {
+	var reply pb.PartialTransaction
+	if len(in.Transactions) == 0 {
+		return &reply, errors.New("Nothing to combine")
+	}
+	reply.Transaction = in.Transactions[0].Transaction
+	reply.Spending = in.Transactions[0].Spending
+	if reply.Transaction == nil {
+		return &reply, errors.New("Malformed partial transaction")
+	}
+	txID := chain.GetTransactionHash(reply.Transaction)
+	for i, psbt := range in.Transactions {
+		if psbt.Transaction == nil || !bytes.Equal(chain.GetTransactionHash(psbt.Transaction), txID) {
+			return &reply, errors.New(fmt.Sprintf("Partial transaction %d is for a different transaction", i))
+		}
+		wallet.AddSignatures(&reply, psbt.Signatures)
+	}
+	return &reply, nil
+}
+
+// Every input must be signed by the key(s) which own it. Outputs locked to
+// a receiver pubkey share the single transaction signature, so currently
+// those inputs must all belong to the same key. Script inputs get an
+// unlocking script built from the signatures collected
+func (s *Server) FinalizeTransaction(ctx context.Context, in *pb.PartialTransaction) (*pb.TransactionSent, error) {
+	var reply pb.TransactionSent
+	if in.Transaction == nil || len(in.Transaction.Vin) == 0 {
+		return &reply, errors.New("Malformed partial transaction")
+	}
+	err := s.addAndBroadcast(func() (*pb.Transaction, error) {
+		trans := in.Transaction
+		if err := s.Blockchain.CheckMempoolTimeLocks(trans); err != nil {
+			return nil, err
+		}
+		txID := chain.GetTransactionHash(trans)
+		for i, txi := range trans.Vin {
+			// Trust the chain rather than the spending list we were given
+			spending := s.Blockchain.GetTransaction(txi.TxID)
+			if spending == nil || txi.Index >= uint64(len(spending.Vout)) {
+				return nil, errors.New(fmt.Sprintf("Input %d spends an unknown output", i))
+			}
+			txo := spending.Vout[txi.Index]
+			if len(txo.LockingScript) != 0 {
+				unlocking, err := wallet.BuildUnlockingScript(txo.LockingScript, in.Signatures, txID)
+				if err != nil {
+					return nil, errors.New(fmt.Sprintf("Input %d: %v", i, err))
+				}
+				txi.UnlockingScript = unlocking
+				continue
+			}
+			signature := wallet.FindSignature(in.Signatures, txo.ReceiverPubKey, txID)
+			if signature == nil {
+				return nil, errors.New("Missing signature from input owner " + hex.EncodeToString(txo.ReceiverPubKey))
+			}
+			trans.Signature = signature
+		}
+		if err := s.MemPool.CheckStandard(trans); err != nil {
+			return nil, err
+		}
+		if !s.Blockchain.VerifyTransaction(trans) {
+			return nil, errors.New("Finalized transaction is invalid")
+		}
+		if err := s.MemPool.CheckRelayFee(trans, s.Blockchain.GetTransactionFee(trans)); err != nil {
+			return nil, err
+		}
+		logging.Wallet.Info("Sending transaction", logging.Tx(txID))
+		s.MemPool.AddTransaction(trans)
+		reply.TxID = txID
+		reply.Inputs = trans.Vin
+		reply.Fee = s.Blockchain.GetTransactionFee(trans)
+		return trans, nil
+	})
+	return &reply, err
+}
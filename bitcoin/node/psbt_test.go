@@ -0,0 +1,73 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+func TestOfflineSigning(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 3)
+	offlineKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	req := pb.TransactionRequest{Value: 8, ReceiverPubKey: chain.GetPubKeyBytes(offlineKey)}
+	if _, err := s.SendTransaction(context.Background(), &req); err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	req = pb.TransactionRequest{Value: 5, ReceiverPubKey: chain.GetPubKeyBytes(receiverKey),
+		SenderPubKey: chain.GetPubKeyBytes(offlineKey), Fee: 1}
+	psbt, err := s.CreateTransaction(context.Background(), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(psbt.Spending) != 1 || len(psbt.Transaction.Signature) != 0 {
+		t.Fatalf("Expected one unsigned input, got %d inputs", len(psbt.Spending))
+	}
+	// The node's key doesn't own the input
+	if _, err = s.SignTransaction(context.Background(), psbt); err == nil {
+		t.Error("Node should not be able to sign for the offline key")
+	}
+	if _, err = s.FinalizeTransaction(context.Background(), psbt); err == nil {
+		t.Error("Should not finalize without a signature")
+	}
+	signed := &pb.PartialTransaction{Transaction: psbt.Transaction, Spending: psbt.Spending}
+	if err = wallet.AddPartialSignature(signed, offlineKey); err != nil {
+		t.Fatal(err)
+	}
+	combined, err := s.CombineTransactions(context.Background(), &pb.PartialTransactions{Transactions: []*pb.PartialTransaction{psbt, signed}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combined.Signatures) != 1 {
+		t.Errorf("Expected one signature after combining, got %d", len(combined.Signatures))
+	}
+	sent, err := s.FinalizeTransaction(context.Background(), combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Fee != 1 {
+		t.Errorf("Fee is %d should be 1", sent.Fee)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	if balance := s.Blockchain.GetBalance(&receiverKey.PublicKey); balance != 5 {
+		t.Errorf("Receiver balance is %d should be 5", balance)
+	}
+	if balance := s.Blockchain.GetBalance(&offlineKey.PublicKey); balance != 2 {
+		t.Errorf("Offline key balance is %d should be 2", balance)
+	}
+}
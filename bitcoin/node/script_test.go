@@ -0,0 +1,60 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+func signHash(key *ecdsa.PrivateKey, hash []byte) []byte {
+	r, s, _ := ecdsa.Sign(rand.Reader, key, hash)
+	return chain.GetSignatureBytes(r, s)
+}
+
+// Lock coin to a pay to pubkey hash script then spend it with an unlocking script
+func TestSpendScriptOutput(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 3)
+	scriptKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	locking := chain.PayToPubKeyHashScript(chain.GetPubKeyBytes(scriptKey))
+	req := pb.TransactionRequest{Outputs: []*pb.TXO{&pb.TXO{LockingScript: locking, Value: 6}},
+		Fee: 1}
+	sent, err := s.SendTransaction(context.Background(), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	funding := s.Blockchain.GetTransaction(sent.TxID)
+	index := len(funding.Vout) - 1
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	spend := pb.Transaction{Vin: []*pb.TXI{&pb.TXI{TxID: sent.TxID, Index: uint64(index)}},
+		Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(receiverKey), Value: 6}}}
+	// Signing with the wrong key fails
+	spend.Vin[0].UnlockingScript = chain.PayToPubKeyHashUnlockingScript(signHash(receiverKey, chain.GetTransactionHash(&spend)), chain.GetPubKeyBytes(receiverKey))
+	if _, err = s.ReceiveTransaction(context.Background(), &spend); err == nil {
+		t.Error("Should not accept an unlocking script from the wrong key")
+	}
+	spend.Vin[0].UnlockingScript = chain.PayToPubKeyHashUnlockingScript(signHash(scriptKey, chain.GetTransactionHash(&spend)), chain.GetPubKeyBytes(scriptKey))
+	if _, err = s.ReceiveTransaction(context.Background(), &spend); err != nil {
+		t.Fatal(err)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	if balance := s.Blockchain.GetBalance(&receiverKey.PublicKey); balance != 6 {
+		t.Errorf("Receiver balance is %d should be 6", balance)
+	}
+	// Now spent, can't be spent again
+	if s.Blockchain.VerifyTransaction(&spend) {
+		t.Error("Script output spent twice")
+	}
+}
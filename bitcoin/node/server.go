@@ -0,0 +1,420 @@
+package node
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/config"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	"github.com/connorwstein/Blockchain/bitcoin/mempool"
+	"github.com/connorwstein/Blockchain/bitcoin/p2p"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/peer"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	peerLock sync.RWMutex
+	peerList map[string]p2p.Peer
+	ips      []net.IPNet // Set of our IP addresses
+	// Guards the chain and mempool. Never held while sending to peers as
+	// they can relay straight back to us
+	stateLock sync.RWMutex
+	chain.Blockchain
+	mempool.MemPool // Has unconfirmed transactions
+	wallet.Wallet
+	miner       *miner
+	networkTime *p2p.NetworkTime // Our clock adjusted by our peers'
+	DataDir     string           // Where blocks are stored, memory only if empty
+	// Added to the options for dialing peers, e.g. an in memory transport for tests
+	DialOptions []grpc.DialOption
+	faults      *p2p.FaultInjector // Applied to every message we send a peer
+	config      *config.Config
+	metrics     *nodeMetrics
+}
+
+func RegisterServices(s *grpc.Server, server *Server) {
+	pb.RegisterTransactionsServer(s, server)
+	pb.RegisterPeeringServer(s, server)
+	pb.RegisterStateServer(s, server)
+	pb.RegisterWalletServer(s, server)
+	pb.RegisterMinerServer(s, server)
+	pb.RegisterBlocksServer(s, server)
+	pb.RegisterSwapsServer(s, server)
+	pb.RegisterAdminServer(s, server)
+}
+
+func StartServer(server *Server, address string) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		logging.Node.Error("gRPC server failed to start listening", "address", address, "err", err)
+		return
+	}
+	s := grpc.NewServer()
+	RegisterServices(s, server)
+	// Blocking call
+	if err := s.Serve(lis); err != nil {
+		logging.Node.Error("gRPC server failed to start serving", "address", address, "err", err)
+	}
+}
+
+// Everything the bitcoin command does: load the chain, look for peers and
+// serve until the listener fails
+func Run(cfg *config.Config) error {
+	logging.Node.Info("Listening", "address", cfg.Listen)
+	nodeList := cfg.Peers
+	if cfg.PeersFile != "" {
+		// Depends on the IPs of your network
+		fileNodes, err := p2p.GetNodeList(cfg.PeersFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		nodeList = append(nodeList, fileNodes...)
+	}
+	server, err := NewServerFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.Metrics != "" {
+		go func() {
+			if err := server.ServeMetrics(cfg.Metrics); err != nil {
+				logging.Node.Error("Serving metrics failed", "err", err)
+			}
+		}()
+	}
+	if err := server.LoadBlocks(); err != nil {
+		return err
+	}
+	nodeList = p2p.RemoveOurIPs(p2p.GetOurIPs(), nodeList)
+	server.ConnectToPeers(nodeList)
+	StartServer(server, cfg.Listen)
+	return nil
+}
+
+// A block at the next height built on one of our blocks other than the tip
+// replaces the tip. Unknown parents are taken too, but we can't tell those
+// from blocks we just missed, so they aren't counted. Until blocks sync this
+// can't happen, heights only go up. Called holding the state lock
+func (s *Server) countReorg(block *pb.Block) {
+	tip := s.Blockchain.TipsOfChains[0]
+	_, known := s.Blockchain.Blocks[string(block.Header.PrevBlockHash)]
+	if known && !bytes.Equal(block.Header.PrevBlockHash, chain.GetBlockHash(tip)) {
+		logging.Chain.Info("Replacing tip with a block from another fork", logging.Block(chain.GetBlockHash(tip)),
+			"height", tip.Header.Height)
+		s.metrics.reorgs.Inc()
+	}
+}
+
+// Validate a block from a peer and add it to our chain, false if we didn't
+func (s *Server) acceptBlock(in *pb.Block, senderIP string) bool {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	hash := chain.GetBlockHash(in)
+	blockHash := string(hash)
+	// Add this block to our chain after verifying it. Since
+	// the majority of the nodes are honest and doing this validation
+	// miners are incentivized to be honest otherwise the block with their reward won't actually be included in the longest chain and is
+	// thus unusable
+	// Verify: block is actually mined and transactions are valid
+	start := time.Now()
+	valid := s.Blockchain.BlockIsValid(in)
+	s.metrics.blockValidation.ObserveSince(start)
+	if !valid {
+		// The chain logs why
+		s.metrics.rejectBlock(REJECT_INVALID)
+		return false
+	}
+	if _, ok := s.Blockchain.Blocks[blockHash]; ok {
+		logging.Chain.Debug("Already have block", logging.Block(hash))
+		s.metrics.rejectBlock(REJECT_DUPLICATE)
+		return false
+	}
+	// Only take the block if it is the next one we were looking for
+	if int(in.Header.Height) != s.Blockchain.NextBlockNum {
+		// Otherwise something is wrong
+		logging.Chain.Info("Out of order block", logging.Block(hash), "height", in.Header.Height,
+			"next", s.Blockchain.NextBlockNum)
+		s.metrics.rejectBlock(REJECT_OUT_OF_ORDER)
+		return false
+	}
+	logging.Chain.Info("Added block", logging.Block(hash), "height", in.Header.Height,
+		"transactions", len(in.Transactions), "peer", senderIP)
+	// Clear its transactions from the mempool as they are now confirmed
+	for i := range in.Transactions {
+		delete(s.MemPool.Transactions, string(chain.GetTransactionHash(in.Transactions[i])))
+	}
+	s.metrics.setMempool(s.MemPool.Transactions)
+	s.countReorg(in)
+	s.Blockchain.AddBlock(in)
+	s.storeBlock(in)
+	// Now the length of our blockchain should be s.Blockchian.nextBlockNum
+	if s.Blockchain.NextBlockNum != (len(s.Blockchain.Blocks) + 1) {
+		logging.Chain.Error("Chain is inconsistent after adding block", logging.Block(hash),
+			"blocks", len(s.Blockchain.Blocks), "next", s.Blockchain.NextBlockNum)
+	}
+	return true
+}
+
+func (s *Server) ReceiveBlock(ctx context.Context, in *pb.Block) (*pb.Empty, error) {
+	var reply pb.Empty
+	senderIP := p2p.GetSenderIP(ctx)
+	s.metrics.received(in)
+	hash := chain.GetBlockHash(in)
+	logging.P2P.Debug("Received block", logging.Block(hash), "height", in.Header.Height, "peer", senderIP)
+	if !s.acceptBlock(in, senderIP) {
+		return &reply, nil
+	}
+	// Forward this new block along
+	for _, myPeer := range s.getPeers() {
+		if senderIP == "" || myPeer.PeerIP == senderIP {
+			// Don't send back to the receiver
+			continue
+		}
+		// Find which one of our IP addresses is in the same network as the peer
+		ipAddr, _ := net.ResolveIPAddr("ip", myPeer.SourceIP)
+		// This cast works because ipAddr is a pointer and the pointer to ipAddr does implement
+		// the Addr interface
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: ipAddr})
+		c := pb.NewBlocksClient(myPeer.Conn)
+		_, err := c.ReceiveBlock(ctx, in)
+		logging.P2P.Debug("Relayed block", logging.Block(hash), "peer", myPeer.PeerIP, "err", err)
+	}
+	return &reply, nil
+}
+
+func (s *Server) GetAddress(ctx context.Context, in *pb.Empty) (*pb.AccountCreated, error) {
+	var account pb.AccountCreated
+	if s.Wallet.Key == nil {
+		return &account, errors.New("Need to make an account first")
+	}
+	account.Address = s.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(s.Wallet.Key))
+	return &account, nil
+}
+
+func (s *Server) GetBlocks(in *pb.Empty, stream pb.State_GetBlocksServer) error {
+	// Walk the mempool
+	// This is the only slow part, is building a sorted list
+	s.stateLock.RLock()
+	orderedBlocks := make([]*pb.Block, len(s.Blockchain.Blocks))
+	for _, block := range s.Blockchain.Blocks {
+		orderedBlocks[block.Header.Height-1] = block
+	}
+	s.stateLock.RUnlock()
+	for _, block := range orderedBlocks {
+		if block != nil {
+			stream.Send(block)
+		}
+	}
+	return nil
+}
+
+// Peers have to be building on the same genesis block
+func (s *Server) checkNetwork(network string, genesisHash []byte) error {
+	if !bytes.Equal(genesisHash, s.Blockchain.Params.GenesisHash()) {
+		return errors.New(fmt.Sprintf("Peer is on network %q, we are on %s", network, s.Blockchain.Params.Name))
+	}
+	return nil
+}
+
+func (s *Server) Connect(ctx context.Context, in *pb.Hello) (*pb.Ack, error) {
+	var reply pb.Ack
+	s.metrics.received(in)
+	if err := s.checkNetwork(in.Network, in.GenesisHash); err != nil {
+		logging.P2P.Info("Refused peer", "peer", p2p.GetSenderIP(ctx), "err", err)
+		return nil, err
+	}
+	logging.P2P.Debug("Peer connected", "peer", p2p.GetSenderIP(ctx))
+	s.networkTime.AddSample(p2p.GetSenderIP(ctx), in.Time)
+	reply.Time = uint64(time.Now().Unix())
+	reply.Network = s.Blockchain.Params.Name
+	reply.GenesisHash = s.Blockchain.Params.GenesisHash()
+	return &reply, nil
+}
+
+func (s *Server) NewAccount(ctx context.Context, in *pb.Account) (*pb.AccountCreated, error) {
+	var reply pb.AccountCreated
+	err := s.Wallet.CreateKey()
+	if err != nil {
+		return &reply, errors.New("Unknown error creating account")
+	}
+	addr := s.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(s.Wallet.Key))
+	logging.Wallet.Info("Created account", "name", in.Name, "address", addr)
+	reply.Address = addr
+	return &reply, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, in *pb.Empty) (*pb.Balance, error) {
+	var balance pb.Balance
+	if s.Wallet.Key == nil {
+		logging.Wallet.Warn("Need to create an account first")
+		return &balance, nil
+	}
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	balance.Balance = s.Blockchain.GetBalance(&s.Wallet.Key.PublicKey)
+	for _, utxo := range s.Blockchain.GetSpendableUTXOs(s.Blockchain.GetUTXOs(&s.Wallet.Key.PublicKey)) {
+		balance.Spendable += utxo.Value()
+	}
+	return &balance, nil
+}
+
+func (s *Server) GetHistory(ctx context.Context, in *pb.Page) (*pb.AddressHistory, error) {
+	if s.Wallet.Key == nil {
+		logging.Wallet.Warn("Need to create an account first")
+		return &pb.AddressHistory{}, nil
+	}
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	return s.getAddressHistory(chain.GetPubKeyBytes(s.Wallet.Key), nil, in), nil
+}
+
+func (s *Server) tryToConnectToPeers(nodeList []string) {
+	for _, node := range nodeList {
+		s.peerLock.RLock()
+		_, ok := s.peerList[node]
+		s.peerLock.RUnlock()
+		if ok {
+			continue
+		}
+		options := append([]grpc.DialOption{grpc.WithInsecure(), s.faults.DialOption(node),
+			grpc.WithChainUnaryInterceptor(s.metrics.interceptor())}, s.DialOptions...)
+		conn, err := grpc.Dial(p2p.GetPeerAddress(node, s.Blockchain.Params.Port), options...)
+		if err != nil {
+			logging.P2P.Warn("Failed to dial peer", "peer", node, "err", err)
+		} else {
+			client := pb.NewPeeringClient(conn)
+			ctx, _ := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			ack, err := client.Connect(ctx, &pb.Hello{Time: uint64(time.Now().Unix()),
+				Network: s.Blockchain.Params.Name, GenesisHash: s.Blockchain.Params.GenesisHash()})
+			if err == nil {
+				err = s.checkNetwork(ack.Network, ack.GenesisHash)
+			}
+			if err != nil {
+				// Seeds which are down are retried every peer check
+				logging.P2P.Debug("Not peering", "peer", node, "err", err)
+				conn.Close()
+			} else {
+				s.networkTime.AddSample(node, ack.Time)
+				// Save that connection, will send new transactions to peers to flood the network
+				outgoingIP, _ := p2p.GetOutgoingIP(s.ips, p2p.GetPeerHost(node))
+				logging.P2P.Info("New peer", "peer", node, "source", outgoingIP)
+				s.peerLock.Lock()
+				s.peerList[node] = p2p.Peer{Conn: conn, PeerIP: node, SourceIP: outgoingIP}
+				s.peerLock.Unlock()
+			}
+		}
+	}
+}
+
+// Copy of the peers, so relaying doesn't hold the lock while waiting on them
+func (s *Server) getPeers() []p2p.Peer {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+	var peers []p2p.Peer
+	for _, peer := range s.peerList {
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+func (s *Server) GetPeers(ctx context.Context, in *pb.Empty) (*pb.PeerList, error) {
+	var reply pb.PeerList
+	for _, peer := range s.getPeers() {
+		reply.Peers = append(reply.Peers, peer.PeerIP)
+	}
+	sort.Strings(reply.Peers)
+	return &reply, nil
+}
+
+// How many peers we can send to right now. Connections dropped while
+// idle are only redialed for the next message, so start redialing them
+func (s *Server) ConnectedPeers() int {
+	connected := 0
+	for _, peer := range s.getPeers() {
+		switch peer.Conn.GetState() {
+		case connectivity.Ready:
+			connected++
+		case connectivity.Idle:
+			peer.Conn.Connect()
+		}
+	}
+	return connected
+}
+
+// Peer with any of nodeList we aren't already, right now
+func (s *Server) AddPeers(nodeList []string) {
+	s.tryToConnectToPeers(nodeList)
+}
+
+// Always look for new peers in a separate goroutine
+// polling at regular intervals
+func (s *Server) ConnectToPeers(nodeList []string) {
+	ticker := time.NewTicker(time.Duration(s.config.PeerCheck) * time.Millisecond)
+	go func() {
+		for _ = range ticker.C {
+			s.tryToConnectToPeers(nodeList)
+		}
+	}()
+}
+
+// Server with the default config, which can't fail
+func NewServer() *Server {
+	server, _ := NewServerFromConfig(config.Default())
+	return server
+}
+
+func NewServerFromConfig(cfg *config.Config) (*Server, error) {
+	params, err := cfg.Params()
+	if err != nil {
+		return nil, err
+	}
+	// Don't need to initialize the wallet unless we were given a key
+	networkTime := p2p.NewNetworkTime()
+	var server Server = Server{
+		ips:         p2p.GetOurIPs(),
+		peerList:    make(map[string]p2p.Peer),
+		networkTime: networkTime,
+		MemPool:     mempool.MemPool{Transactions: make(map[string]*pb.Transaction), MinRelayFeeRate: mempool.MIN_RELAY_FEE_RATE},
+		Blockchain: chain.Blockchain{Blocks: make(map[string]*pb.Block),
+			TxIndex:          make(map[string]chain.TxIndex),
+			TipsOfChains:     make([]*pb.Block, 0),
+			NextBlockNum:     1,
+			Params:           params,
+			BlockReward:      params.BlockReward,
+			HalvingInterval:  params.HalvingInterval,
+			CoinbaseMaturity: params.CoinbaseMaturity,
+			Clock:            networkTime,
+			MaxFutureDrift:   chain.MAX_FUTURE_BLOCK_TIME},
+		miner:   newMiner(),
+		faults:  p2p.NewFaultInjector(),
+		DataDir: cfg.DataDir,
+		config:  cfg}
+	server.metrics = newNodeMetrics(&server)
+	if cfg.AddressIndex {
+		server.Blockchain.AddressIndex = chain.NewAddressIndex()
+	}
+	for _, faults := range cfg.Faults {
+		if err := server.faults.Set(faults); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.MiningKey != "" {
+		if err := server.Wallet.LoadKey(cfg.MiningKey); err != nil {
+			return nil, err
+		}
+	}
+	logging.Chain.Info("Starting chain", "network", params.Name, "target", hex.EncodeToString(params.PowLimit))
+	server.Blockchain.SetTarget(params.PowLimit)
+	server.Blockchain.AddGenesisBlock()
+	return &server, nil
+}
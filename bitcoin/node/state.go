@@ -0,0 +1,299 @@
+// Lookups of single blocks, transactions and addresses, and pages of the
+// chain, for clients which can't take a stream of everything e.g. the
+// REST gateway.
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+)
+
+const (
+	DEFAULT_PAGE_SIZE = 20
+	MAX_PAGE_SIZE     = 500
+)
+
+// Start and end of the page within n items
+func pageBounds(page *pb.Page, n int) (int, int) {
+	limit := DEFAULT_PAGE_SIZE
+	if page.GetLimit() != 0 {
+		limit = int(page.GetLimit())
+	}
+	if limit > MAX_PAGE_SIZE {
+		limit = MAX_PAGE_SIZE
+	}
+	start := n
+	if page.GetOffset() < uint64(n) {
+		start = int(page.GetOffset())
+	}
+	end := start + limit
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+func (s *Server) GetBlock(ctx context.Context, in *pb.BlockQuery) (*pb.Block, error) {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	if len(in.Hash) != 0 {
+		block, ok := s.Blockchain.Blocks[string(in.Hash)]
+		if !ok {
+			return nil, status.Error(codes.NotFound, fmt.Sprintf("No block %x", in.Hash))
+		}
+		return block, nil
+	}
+	block := s.Blockchain.GetAncestor(s.Blockchain.TipsOfChains[0], in.Height)
+	if block == nil || block.Header.Height != in.Height {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("No block at height %d", in.Height))
+	}
+	return block, nil
+}
+
+func (s *Server) ListBlocks(ctx context.Context, in *pb.Page) (*pb.BlockPage, error) {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	tip := s.Blockchain.TipsOfChains[0]
+	// Genesis is at height 1
+	total := int(tip.Header.Height)
+	start, end := pageBounds(in, total)
+	page := &pb.BlockPage{Total: uint64(total)}
+	block := s.Blockchain.GetAncestor(tip, tip.Header.Height-uint64(start))
+	for i := start; i < end && block != nil; i++ {
+		page.Blocks = append(page.Blocks, block)
+		block = s.Blockchain.GetPrevBlock(block)
+	}
+	return page, nil
+}
+
+func (s *Server) GetTransactionInfo(ctx context.Context, in *pb.TransactionQuery) (*pb.TransactionInfo, error) {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	if transaction, ok := s.MemPool.Transactions[string(in.TxID)]; ok {
+		return &pb.TransactionInfo{Transaction: transaction}, nil
+	}
+	idx, ok := s.Blockchain.TxIndex[string(in.TxID)]
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("No transaction %x", in.TxID))
+	}
+	block := s.Blockchain.Blocks[idx.BlockHash]
+	return &pb.TransactionInfo{
+		Transaction:   block.Transactions[idx.Index],
+		BlockHash:     []byte(idx.BlockHash),
+		Height:        block.Header.Height,
+		Confirmations: s.Blockchain.TipsOfChains[0].Header.Height - block.Header.Height + 1}, nil
+}
+
+// Key addresses, or script addresses e.g. multisig. One of the key or
+// script is set
+func (s *Server) parseAddress(address string) ([]byte, []byte, error) {
+	if chain.IsScriptAddress(address) {
+		script, err := chain.GetScriptFromAddress(address)
+		if err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, script, nil
+	}
+	pubKey, err := s.Blockchain.Params.GetPubKeyFromAddress(address)
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return pubKey, nil, nil
+}
+
+func paysTo(output *pb.TXO, pubKey []byte, script []byte) bool {
+	if len(pubKey) != 0 {
+		return len(output.LockingScript) == 0 && bytes.Equal(output.ReceiverPubKey, pubKey)
+	}
+	return bytes.Equal(output.LockingScript, script)
+}
+
+// From the address index if it's enabled, otherwise by scanning the chain
+func (s *Server) getAddressUTXOs(pubKey []byte, script []byte) []*chain.UTXO {
+	if s.Blockchain.AddressIndex == nil {
+		if len(pubKey) != 0 {
+			return s.Blockchain.GetUTXOs(chain.GetPublicKeyFromBytes(pubKey))
+		}
+		return s.Blockchain.GetScriptUTXOs(script)
+	}
+	var utxos []*chain.UTXO
+	for _, output := range s.Blockchain.AddressIndex.GetUnspent(chain.AddressKey(pubKey, script)) {
+		utxos = append(utxos, &chain.UTXO{Transaction: s.Blockchain.GetTransaction(output.TxID), Index: int(output.Index)})
+	}
+	return utxos
+}
+
+func (s *Server) GetAddressUTXOs(ctx context.Context, in *pb.AddressQuery) (*pb.UTXOPage, error) {
+	pubKey, script, err := s.parseAddress(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	utxos := s.getAddressUTXOs(pubKey, script)
+	converted := make([]*pb.UTXO, 0, len(utxos))
+	page := &pb.UTXOPage{Total: uint64(len(utxos))}
+	for _, utxo := range utxos {
+		txID := chain.GetTransactionHash(utxo.Transaction)
+		converted = append(converted, &pb.UTXO{
+			TxID:     txID,
+			Index:    uint64(utxo.Index),
+			Output:   utxo.Transaction.Vout[utxo.Index],
+			Height:   s.Blockchain.GetConfirmationHeight(txID),
+			Coinbase: chain.IsCoinbase(utxo.Transaction)})
+		page.Balance += utxo.Value()
+	}
+	// Blocks are kept in a map, so give the pages a stable order, newest first
+	sort.Slice(converted, func(i, j int) bool {
+		a, b := converted[i], converted[j]
+		if a.Height != b.Height {
+			return a.Height > b.Height
+		}
+		if c := bytes.Compare(a.TxID, b.TxID); c != 0 {
+			return c < 0
+		}
+		return a.Index < b.Index
+	})
+	start, end := pageBounds(in.Page, len(converted))
+	page.Utxos = converted[start:end]
+	return page, nil
+}
+
+// What a transaction paid to and spent from an address, nil if neither
+func (s *Server) addressTransaction(tx *pb.Transaction, pubKey []byte, script []byte) *pb.AddressTransaction {
+	entry := &pb.AddressTransaction{TxID: chain.GetTransactionHash(tx)}
+	for _, output := range tx.Vout {
+		if paysTo(output, pubKey, script) {
+			entry.Received += output.Value
+		}
+	}
+	for _, input := range tx.Vin {
+		spent := s.Blockchain.GetTransaction(input.TxID)
+		if spent == nil {
+			spent = s.MemPool.Transactions[string(input.TxID)]
+		}
+		if spent != nil && int(input.Index) < len(spent.Vout) && paysTo(spent.Vout[input.Index], pubKey, script) {
+			entry.Sent += spent.Vout[input.Index].Value
+		}
+	}
+	if entry.Received == 0 && entry.Sent == 0 {
+		return nil
+	}
+	return entry
+}
+
+// Where the block an entry's transaction confirmed in is
+func setBlock(entry *pb.AddressTransaction, block *pb.Block, tip *pb.Block) {
+	entry.Height = block.Header.Height
+	entry.BlockHash = chain.GetBlockHash(block)
+	entry.Confirmations = tip.Header.Height - block.Header.Height + 1
+	entry.Time = block.Header.TimeStamp
+}
+
+// Transactions in the chain which paid or spent from the address, newest
+// first. Scans the whole chain unless the address index is enabled
+func (s *Server) getConfirmedHistory(pubKey []byte, script []byte) []*pb.AddressTransaction {
+	if s.Blockchain.AddressIndex != nil {
+		return s.getIndexedHistory(pubKey, script)
+	}
+	var entries []*pb.AddressTransaction
+	tip := s.Blockchain.TipsOfChains[0]
+	for block := tip; block != nil; block = s.Blockchain.GetPrevBlock(block) {
+		for i := len(block.Transactions) - 1; i >= 0; i-- {
+			if entry := s.addressTransaction(block.Transactions[i], pubKey, script); entry != nil {
+				setBlock(entry, block, tip)
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+func (s *Server) getIndexedHistory(pubKey []byte, script []byte) []*pb.AddressTransaction {
+	var entries []*pb.AddressTransaction
+	byTxID := make(map[string]*pb.AddressTransaction)
+	entry := func(txID []byte) *pb.AddressTransaction {
+		if entry, ok := byTxID[string(txID)]; ok {
+			return entry
+		}
+		entry := &pb.AddressTransaction{TxID: txID}
+		byTxID[string(txID)] = entry
+		entries = append(entries, entry)
+		return entry
+	}
+	for _, output := range s.Blockchain.AddressIndex.GetOutputs(chain.AddressKey(pubKey, script)) {
+		entry(output.TxID).Received += output.Value
+		if output.SpentBy != nil {
+			entry(output.SpentBy).Sent += output.Value
+		}
+	}
+	tip := s.Blockchain.TipsOfChains[0]
+	for _, entry := range entries {
+		idx := s.Blockchain.TxIndex[string(entry.TxID)]
+		setBlock(entry, s.Blockchain.Blocks[idx.BlockHash], tip)
+	}
+	// Same order as walking back from the tip
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Height != entries[j].Height {
+			return entries[i].Height > entries[j].Height
+		}
+		return s.Blockchain.TxIndex[string(entries[i].TxID)].Index > s.Blockchain.TxIndex[string(entries[j].TxID)].Index
+	})
+	return entries
+}
+
+func (s *Server) getAddressHistory(pubKey []byte, script []byte, page *pb.Page) *pb.AddressHistory {
+	var entries []*pb.AddressTransaction
+	for _, tx := range s.MemPool.Transactions {
+		if entry := s.addressTransaction(tx, pubKey, script); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	// The mempool is a map, keep its order stable between pages
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].TxID, entries[j].TxID) < 0 })
+	entries = append(entries, s.getConfirmedHistory(pubKey, script)...)
+	history := &pb.AddressHistory{Total: uint64(len(entries))}
+	for _, entry := range entries {
+		history.Received += entry.Received
+		history.Sent += entry.Sent
+	}
+	start, end := pageBounds(page, len(entries))
+	history.Transactions = entries[start:end]
+	return history
+}
+
+func (s *Server) GetAddressHistory(ctx context.Context, in *pb.AddressQuery) (*pb.AddressHistory, error) {
+	pubKey, script, err := s.parseAddress(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	return s.getAddressHistory(pubKey, script, in.Page), nil
+}
+
+func (s *Server) GetAddressBalance(ctx context.Context, in *pb.AddressQuery) (*pb.AddressBalance, error) {
+	pubKey, script, err := s.parseAddress(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	balance := &pb.AddressBalance{}
+	for _, utxo := range s.getAddressUTXOs(pubKey, script) {
+		balance.Balance += utxo.Value()
+		balance.Utxos++
+	}
+	for _, entry := range s.getConfirmedHistory(pubKey, script) {
+		balance.Received += entry.Received
+		balance.Sent += entry.Sent
+		balance.Transactions++
+	}
+	return balance, nil
+}
@@ -0,0 +1,167 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestPageBounds(t *testing.T) {
+	var tests = []struct {
+		page       *pb.Page
+		n          int
+		start, end int
+	}{
+		{nil, 50, 0, DEFAULT_PAGE_SIZE},
+		{&pb.Page{Limit: 5}, 3, 0, 3},
+		{&pb.Page{Offset: 2, Limit: 5}, 10, 2, 7},
+		{&pb.Page{Offset: 12}, 10, 10, 10},
+		{&pb.Page{Limit: 10000}, 1000, 0, MAX_PAGE_SIZE},
+	}
+	for _, test := range tests {
+		if start, end := pageBounds(test.page, test.n); start != test.start || end != test.end {
+			t.Errorf("Page %v of %d is %d to %d", test.page, test.n, start, end)
+		}
+	}
+}
+
+func TestLookups(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Wallet.CreateKey()
+	blocks, err := s.Generate(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	page, err := s.ListBlocks(ctx, &pb.Page{Offset: 1, Limit: 2})
+	if err != nil || page.Total != 5 || len(page.Blocks) != 2 || page.Blocks[0] != blocks[2] || page.Blocks[1] != blocks[1] {
+		t.Errorf("Second and third newest blocks %v %v", page, err)
+	}
+	if block, err := s.GetBlock(ctx, &pb.BlockQuery{Height: 3}); err != nil || block != blocks[1] {
+		t.Errorf("Block at height 3 %v %v", block, err)
+	}
+	if _, err := s.GetBlock(ctx, &pb.BlockQuery{Height: 6}); status.Code(err) != codes.NotFound {
+		t.Errorf("Block past the tip should be not found, got %v", err)
+	}
+	if _, err := s.GetTransactionInfo(ctx, &pb.TransactionQuery{TxID: []byte("nope")}); status.Code(err) != codes.NotFound {
+		t.Errorf("Unknown transaction should be not found, got %v", err)
+	}
+	utxos, err := s.GetAddressUTXOs(ctx, &pb.AddressQuery{Address: s.Blockchain.Params.GetAddress(blocks[0].Transactions[0].Vout[0].ReceiverPubKey)})
+	if err != nil || utxos.Total != 4 || utxos.Utxos[0].Height != 5 || !utxos.Utxos[0].Coinbase {
+		t.Errorf("UTXOs of the miner %v %v", utxos, err)
+	}
+	history, err := s.GetAddressHistory(ctx, &pb.AddressQuery{Address: chain.GetScriptAddress([]byte{chain.OP_RETURN})})
+	if err != nil || history.Total != 0 {
+		t.Errorf("Nothing pays a data script, got %v %v", history, err)
+	}
+	// Mainnet addresses are digits, so they're hex half the time, but not scripts
+	mainnet := chain.MAINNET.GetAddress(chain.GetPubKeyBytes(s.Wallet.Key))
+	for _, address := range []string{"nope", mainnet, mainnet + "0", "script:nope"} {
+		if _, err := s.GetAddressHistory(ctx, &pb.AddressQuery{Address: address}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Invalid address %s should be an invalid argument, got %v", address, err)
+		}
+	}
+}
+
+// The index has to give the same answers as scanning the chain
+func TestAddressIndex(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Blockchain.AddressIndex = chain.NewAddressIndex()
+	s.Blockchain.CoinbaseMaturity = 1
+	s.Wallet.CreateKey()
+	if _, err := s.Generate(3); err != nil {
+		t.Fatal(err)
+	}
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ctx := context.Background()
+	send := func(value uint64) {
+		req := &pb.TransactionRequest{Value: value, ReceiverPubKey: chain.GetPubKeyBytes(receiverKey), Fee: 1}
+		if _, err := s.SendTransaction(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send(7)
+	s.Generate(1)
+	// Still in the mempool
+	send(3)
+	ours := s.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(s.Wallet.Key))
+	receiver := s.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(receiverKey))
+	index := s.Blockchain.AddressIndex
+	for _, address := range []string{ours, receiver} {
+		query := &pb.AddressQuery{Address: address, Page: &pb.Page{Limit: MAX_PAGE_SIZE}}
+		s.Blockchain.AddressIndex = index
+		history, _ := s.GetAddressHistory(ctx, query)
+		utxos, _ := s.GetAddressUTXOs(ctx, query)
+		balance, _ := s.GetAddressBalance(ctx, query)
+		s.Blockchain.AddressIndex = nil
+		scannedHistory, _ := s.GetAddressHistory(ctx, query)
+		scannedUTXOs, _ := s.GetAddressUTXOs(ctx, query)
+		scannedBalance, _ := s.GetAddressBalance(ctx, query)
+		if !proto.Equal(history, scannedHistory) || !proto.Equal(utxos, scannedUTXOs) || !proto.Equal(balance, scannedBalance) {
+			t.Errorf("Index and scan differ for %s\n%v\n%v\n%v\n%v", address, history, scannedHistory, balance, scannedBalance)
+		}
+	}
+	s.Blockchain.AddressIndex = index
+	balance, _ := s.GetAddressBalance(ctx, &pb.AddressQuery{Address: receiver})
+	if balance.Balance != 7 || balance.Utxos != 1 || balance.Received != 7 || balance.Transactions != 1 {
+		t.Errorf("Receiver only has the confirmed payment %v", balance)
+	}
+	history, _ := s.GetHistory(ctx, &pb.Page{})
+	if history.Total != 6 || history.Transactions[0].Height != 0 || history.Transactions[1].Sent == 0 || history.Transactions[1].Confirmations != 1 {
+		t.Errorf("Wallet history should be the unconfirmed payment, then the confirmed one and 4 coinbases %v", history)
+	}
+}
+
+// We only get the last block of a fork two deep. It takes our tip's place,
+// and lookups with or without the index agree on what's left. Our block
+// below the tip stays until blocks can be fetched from peers
+func TestAddressIndexFork(t *testing.T) {
+	s := newRegtestServer(t)
+	s.Blockchain.AddressIndex = chain.NewAddressIndex()
+	s.Wallet.CreateKey()
+	ours, err := s.Generate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := newRegtestServer(t)
+	other.Wallet.CreateKey()
+	theirs, err := other.Generate(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.acceptBlock(theirs[2], "") {
+		t.Fatal("Next block from the other fork should be taken")
+	}
+	ctx := context.Background()
+	index := s.Blockchain.AddressIndex
+	for _, key := range []*ecdsa.PrivateKey{s.Wallet.Key, other.Wallet.Key} {
+		query := &pb.AddressQuery{Address: s.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(key))}
+		s.Blockchain.AddressIndex = index
+		history, _ := s.GetAddressHistory(ctx, query)
+		utxos, _ := s.GetAddressUTXOs(ctx, query)
+		s.Blockchain.AddressIndex = nil
+		scannedHistory, _ := s.GetAddressHistory(ctx, query)
+		scannedUTXOs, _ := s.GetAddressUTXOs(ctx, query)
+		if !proto.Equal(history, scannedHistory) || !proto.Equal(utxos, scannedUTXOs) {
+			t.Errorf("Index and scan differ for %s\n%v\n%v\n%v\n%v", query.Address, history, scannedHistory, utxos, scannedUTXOs)
+		}
+		if history.Total != 1 || utxos.Total != 1 {
+			t.Errorf("Each side should have one block left %v %v", history, utxos)
+		}
+	}
+	s.Blockchain.AddressIndex = index
+	replaced := chain.GetTransactionHash(ours[1].Transactions[0])
+	if _, err := s.GetTransactionInfo(ctx, &pb.TransactionQuery{TxID: replaced}); status.Code(err) != codes.NotFound {
+		t.Errorf("Replaced tip's coinbase should be gone, got %v", err)
+	}
+	if chain := s.Blockchain.GetMainChain(); len(chain) != 3 || chain[1] != ours[0] {
+		t.Errorf("Chain should be the new tip, our first block and genesis, got %d blocks", len(chain))
+	}
+}
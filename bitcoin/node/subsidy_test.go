@@ -0,0 +1,55 @@
+package node
+
+import (
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"strings"
+	"testing"
+)
+
+// Coinbase outputs follow the schedule and can't be spent until mature
+func TestCoinbaseMaturity(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	s.Blockchain.HalvingInterval = 3
+	s.Blockchain.CoinbaseMaturity = 3
+	mineBlocks(s, t, 4)
+	for _, block := range s.Blockchain.Blocks {
+		if block.Header.Height > 1 && block.Transactions[0].Vout[0].Value != s.Blockchain.GetBlockSubsidy(block.Header.Height) {
+			t.Errorf("Coinbase at height %d is %d", block.Header.Height, block.Transactions[0].Vout[0].Value)
+		}
+	}
+	var coinbase *pb.Transaction
+	for _, block := range s.Blockchain.Blocks {
+		if block.Header.Height == 2 {
+			coinbase = block.Transactions[0]
+		}
+	}
+	spend := pb.Transaction{Vin: []*pb.TXI{&pb.TXI{TxID: chain.GetTransactionHash(coinbase)}},
+		Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(s.Wallet.Key), Value: 1}}}
+	wallet.SignTransaction(&spend, s.Wallet.Key)
+	if s.Blockchain.VerifyTransactionAt(&spend, 4, 0) || !s.Blockchain.VerifyTransactionAt(&spend, 5, 0) {
+		t.Error("Coinbase from height 2 should be spendable from height 5")
+	}
+	utxos := s.Blockchain.GetUTXOs(&s.Wallet.Key.PublicKey)
+	if spendable := s.Blockchain.GetSpendableUTXOs(utxos); len(spendable) != len(utxos)-2 {
+		t.Errorf("%d of %d UTXOs spendable, the last 2 coinbases should be immature", len(spendable), len(utxos))
+	}
+
+	// A block claiming more than the subsidy is rejected
+	height := uint64(s.Blockchain.NextBlockNum)
+	greedy := pb.Transaction{Height: height,
+		Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(s.Wallet.Key), Value: s.Blockchain.GetBlockSubsidy(height) + 1}}}
+	block := pb.Block{Header: &pb.BlockHeader{PrevBlockHash: chain.GetBlockHash(s.Blockchain.TipsOfChains[0]),
+		Height: height, TimeStamp: s.Blockchain.GetNextBlockTime(s.Blockchain.TipsOfChains[0]),
+		DifficultyTarget: chain.GetCompactTarget(s.Blockchain.Target)}, Transactions: []*pb.Transaction{&greedy}}
+	block.Header.MerkleRoot = chain.GetMerkleRoot(block.Transactions)
+	s.mineBlock(&block, make(chan struct{}))
+	if s.Blockchain.BlockIsValid(&block) {
+		t.Error("Coinbase claiming more than the subsidy should be invalid")
+	}
+}
@@ -0,0 +1,63 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+// Lock time and relative locks checked at the heights either side of unlocking
+func TestTimeLockedSpend(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 3)
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	// Can't go in the next block
+	lockTime := uint64(s.Blockchain.NextBlockNum)
+	req := pb.TransactionRequest{Value: 1, ReceiverPubKey: chain.GetPubKeyBytes(receiverKey), LockTime: lockTime}
+	if _, err := s.SendTransaction(context.Background(), &req); err == nil {
+		t.Error("Should not send a transaction locked past the next block")
+	}
+	psbt, err := s.CreateTransaction(context.Background(), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans := wallet.SignTransaction(psbt.Transaction, s.Wallet.Key)
+	if _, err = s.ReceiveTransaction(context.Background(), trans); err == nil {
+		t.Error("Mempool should reject a transaction which isn't final")
+	}
+	if s.Blockchain.VerifyTransactionAt(trans, lockTime, 0) || !s.Blockchain.VerifyTransactionAt(trans, lockTime+1, 0) {
+		t.Errorf("Lock time %d should be valid from the next height only", lockTime)
+	}
+
+	// Relative lock of 2 blocks from where the UTXO confirmed
+	utxo := s.Blockchain.GetUTXOs(&s.Wallet.Key.PublicKey)[0]
+	txID := chain.GetTransactionHash(utxo.Transaction)
+	confirmed := s.Blockchain.GetConfirmationHeight(txID)
+	spend := pb.Transaction{Vin: []*pb.TXI{&pb.TXI{TxID: txID, Index: uint64(utxo.Index), Sequence: 2}},
+		Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(receiverKey), Value: 1}}}
+	wallet.SignTransaction(&spend, s.Wallet.Key)
+	if s.Blockchain.VerifyTransactionAt(&spend, confirmed+1, 0) || !s.Blockchain.VerifyTransactionAt(&spend, confirmed+2, 0) {
+		t.Errorf("Relative lock should be valid from height %d only", confirmed+2)
+	}
+
+	// The miner holds a locked transaction back until it unlocks
+	s.MemPool.AddTransaction(trans)
+	mineBlocks(s, t, int(lockTime)+1)
+	if idx, ok := s.Blockchain.TxIndex[string(chain.GetTransactionHash(trans))]; !ok {
+		t.Error("Locked transaction never mined")
+	} else if height := s.Blockchain.Blocks[idx.BlockHash].Header.Height; height <= lockTime {
+		t.Errorf("Transaction locked until after %d mined at %d", lockTime, height)
+	}
+}
@@ -0,0 +1,39 @@
+package node
+
+import (
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"strings"
+	"testing"
+)
+
+func TestBlockTimeRules(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	mineBlocks(s, t, 3)
+	tip := s.Blockchain.TipsOfChains[0]
+	median := s.Blockchain.GetMedianTimePast(tip)
+	cases := []struct {
+		timeStamp uint64
+		valid     bool
+	}{
+		{median, false},
+		{median + 1, true},
+		{s.Blockchain.Clock.Now() + chain.MAX_FUTURE_BLOCK_TIME - 5, true},
+		{s.Blockchain.Clock.Now() + chain.MAX_FUTURE_BLOCK_TIME + 5, false},
+	}
+	for _, c := range cases {
+		coinbase := pb.Transaction{Height: tip.Header.Height + 1,
+			Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(s.Wallet.Key), Value: 1}}}
+		block := pb.Block{Header: &pb.BlockHeader{PrevBlockHash: chain.GetBlockHash(tip), Height: tip.Header.Height + 1,
+			TimeStamp: c.timeStamp, DifficultyTarget: chain.GetCompactTarget(s.Blockchain.Target)}, Transactions: []*pb.Transaction{&coinbase}}
+		block.Header.MerkleRoot = chain.GetMerkleRoot(block.Transactions)
+		s.mineBlock(&block, make(chan struct{}))
+		if s.Blockchain.BlockIsValid(&block) != c.valid {
+			t.Errorf("Block time %d with median %d should be valid %v", c.timeStamp, median, c.valid)
+		}
+	}
+}
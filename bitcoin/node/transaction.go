@@ -0,0 +1,308 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	"github.com/connorwstein/Blockchain/bitcoin/p2p"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+	"net"
+	"time"
+)
+
+// Build an unsigned transaction paying the requested outputs from UTXOs
+// owned by senderPubKey (or locked by in.SenderScript), either the ones listed in the request or ones
+// picked by coin selection
+func (s *Server) buildTransaction(in *pb.TransactionRequest, senderPubKey []byte) (*pb.Transaction, *wallet.CoinSelection, error) {
+	// Everyone we are paying, the single receiver is just another output
+	outputs := in.Outputs
+	if len(in.ReceiverPubKey) != 0 {
+		outputs = append(outputs, &pb.TXO{ReceiverPubKey: in.ReceiverPubKey, Value: in.Value})
+	}
+	if len(outputs) == 0 {
+		return nil, nil, errors.New("Need at least one receiver")
+	}
+	var amount uint64
+	for i, output := range outputs {
+		if len(output.ReceiverPubKey) == 0 && len(output.LockingScript) == 0 {
+			return nil, nil, errors.New(fmt.Sprintf("Output %d needs a receiver or a locking script", i))
+		}
+		// Data outputs can't be spent so there is no point giving them value
+		if output.Value == 0 && !chain.IsDataScript(output.LockingScript) {
+			return nil, nil, errors.New(fmt.Sprintf("Output %d needs a non zero value", i))
+		}
+		amount += output.Value
+	}
+	// Change goes back to wherever the coin came from
+	var utxos []*chain.UTXO
+	change := pb.TXO{ReceiverPubKey: senderPubKey}
+	if len(in.SenderScript) != 0 {
+		utxos = s.Blockchain.GetScriptUTXOs(in.SenderScript)
+		change = pb.TXO{LockingScript: in.SenderScript}
+	} else {
+		utxos = s.Blockchain.GetUTXOs(chain.GetPublicKeyFromBytes(senderPubKey))
+	}
+	var balance uint64
+	for _, utxo := range utxos {
+		balance += utxo.Value()
+	}
+	if balance < amount+in.Fee {
+		return nil, nil, errors.New(fmt.Sprintf("Not enough coin, balance is %d", balance))
+	}
+	// Find some UTXO we can use to cover the transaction and fee,
+	// skipping any already being spent by a transaction in our mempool
+	var available []*chain.UTXO
+	for _, utxo := range s.Blockchain.GetSpendableUTXOs(utxos) {
+		if !s.MemPool.IsSpent(utxo) {
+			available = append(available, utxo)
+		}
+	}
+	// A fixed fee is just part of the amount to cover
+	feeRate := in.FeeRate
+	if in.Fee != 0 {
+		feeRate = 0
+	}
+	var selection *wallet.CoinSelection
+	if len(in.Inputs) != 0 {
+		var inputs []*chain.UTXO
+		for _, txi := range in.Inputs {
+			var found *chain.UTXO
+			for _, utxo := range available {
+				if bytes.Equal(txi.TxID, chain.GetTransactionHash(utxo.Transaction)) && txi.Index == uint64(utxo.Index) {
+					found = utxo
+				}
+			}
+			if found == nil {
+				return nil, nil, errors.New(fmt.Sprintf("Input %x:%d is not an unspent output of the sender", txi.TxID, txi.Index))
+			}
+			inputs = append(inputs, found)
+		}
+		if selection = wallet.FinishSelection(inputs, amount+in.Fee, len(outputs), feeRate); selection == nil {
+			return nil, nil, wallet.ErrInsufficientFunds
+		}
+	} else {
+		selector, err := wallet.GetCoinSelector(in.Strategy)
+		if err != nil {
+			return nil, nil, err
+		}
+		if selection, err = selector.SelectCoins(available, amount+in.Fee, len(outputs), feeRate); err != nil {
+			return nil, nil, err
+		}
+	}
+	selection.Fee += in.Fee
+	// Add all input UTXOs
+	var trans pb.Transaction
+	trans.LockTime = in.LockTime
+	for i, utxo := range selection.Inputs {
+		var input pb.TXI
+		input.TxID = chain.GetTransactionHash(utxo.Transaction)
+		input.Index = uint64(utxo.Index)
+		if len(in.Inputs) != 0 {
+			// Explicit inputs can carry a relative lock
+			input.Sequence = in.Inputs[i].Sequence
+		}
+		trans.Vin = append(trans.Vin, &input)
+	}
+	if selection.Change != 0 {
+		// Pay the sender the change, unless told to send it elsewhere
+		change.Value = selection.Change
+		if len(in.ChangePubKey) != 0 {
+			change = pb.TXO{ReceiverPubKey: in.ChangePubKey, Value: selection.Change}
+		}
+		trans.Vout = append(trans.Vout, &change)
+	}
+	for _, output := range outputs {
+		trans.Vout = append(trans.Vout, &pb.TXO{ReceiverPubKey: output.ReceiverPubKey, Value: output.Value,
+			LockingScript: output.LockingScript})
+	}
+	return &trans, selection, nil
+}
+
+// Send this transaction to all the list of clients we are connected to
+// Need to include the source, so that the peer doesn't send it back to us
+func (s *Server) broadcastTransaction(trans *pb.Transaction) {
+	for _, myPeer := range s.getPeers() {
+		// Find which one of our IP addresses is in the same network as the peer
+		ipAddr, _ := net.ResolveIPAddr("ip", myPeer.SourceIP)
+		// This cast works because ipAddr is a pointer and the pointer to ipAddr does implement
+		// the Addr interface
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: ipAddr})
+		c := pb.NewTransactionsClient(myPeer.Conn)
+		_, err := c.ReceiveTransaction(ctx, trans)
+		logging.P2P.Debug("Sent transaction", logging.Tx(chain.GetTransactionHash(trans)), "peer", myPeer.PeerIP, "err", err)
+	}
+}
+
+// Run add holding the state lock, then send the transaction it put in our
+// mempool (if any) to our peers once unlocked
+func (s *Server) addAndBroadcast(add func() (*pb.Transaction, error)) error {
+	s.stateLock.Lock()
+	trans, err := add()
+	s.stateLock.Unlock()
+	if err != nil {
+		return err
+	}
+	if trans != nil {
+		s.broadcastTransaction(trans)
+	}
+	return nil
+}
+
+func (s *Server) SendTransaction(ctx context.Context, in *pb.TransactionRequest) (*pb.TransactionSent, error) {
+	var reply pb.TransactionSent
+	if s.Wallet.Key == nil {
+		return &reply, errors.New("Need to make an account first")
+	}
+	if len(in.SenderScript) != 0 {
+		// Might need other signers, so has to go through CreateTransaction
+		return &reply, errors.New("Spending from a script needs a partially signed transaction")
+	}
+	err := s.addAndBroadcast(func() (*pb.Transaction, error) {
+		trans, selection, err := s.buildTransaction(in, chain.GetPubKeyBytes(s.Wallet.Key))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Blockchain.CheckMempoolTimeLocks(trans); err != nil {
+			// Sign it with CreateTransaction and finalize once it unlocks
+			return nil, err
+		}
+		wallet.SignTransaction(trans, s.Wallet.Key)
+		// Explicit inputs could list the same output twice. The chain logs why at debug
+		if !s.Blockchain.VerifyTransaction(trans) {
+			return nil, errors.New("Built an invalid transaction, check the inputs")
+		}
+		if err := s.MemPool.CheckStandard(trans); err != nil {
+			return nil, err
+		}
+		if err := s.MemPool.CheckRelayFee(trans, s.Blockchain.GetTransactionFee(trans)); err != nil {
+			return nil, err
+		}
+		logging.Wallet.Info("Sending transaction", logging.Tx(chain.GetTransactionHash(trans)), "fee", selection.Fee)
+		s.MemPool.AddTransaction(trans)
+		s.metrics.setMempool(s.MemPool.Transactions)
+		reply.TxID = chain.GetTransactionHash(trans)
+		reply.Inputs = trans.Vin
+		reply.Fee = selection.Fee
+		reply.Change = selection.Change
+		return trans, nil
+	})
+	return &reply, err
+}
+
+func (s *Server) GetTransactions(in *pb.Empty, stream pb.State_GetTransactionsServer) error {
+	// Walk the mempool
+	s.stateLock.RLock()
+	transactions := make([]*pb.Transaction, 0, len(s.MemPool.Transactions))
+	for _, transaction := range s.MemPool.Transactions {
+		transactions = append(transactions, transaction)
+	}
+	s.stateLock.RUnlock()
+	for _, transaction := range transactions {
+		stream.Send(transaction)
+	}
+	return nil
+}
+
+// Need to verify a transaction before propagating. This ensures that invalid transactions
+// are dropped at the first node which receives it. Called holding the state lock
+func (s *Server) acceptTransaction(in *pb.Transaction) error {
+	defer s.metrics.txValidation.ObserveSince(time.Now())
+	reject := func(reason string, err error) error {
+		logging.Mempool.Info("Rejected transaction", logging.Tx(chain.GetTransactionHash(in)), "reason", reason, "err", err)
+		s.metrics.rejectTransaction(reason)
+		return err
+	}
+	if len(in.Vin) == 0 {
+		// Only miners can create coin and only inside a block
+		return reject(REJECT_COINBASE, errors.New("Dropping coinbase transaction outside of a block"))
+	}
+	if err := s.MemPool.CheckStandard(in); err != nil {
+		return reject(REJECT_NONSTANDARD, err)
+	}
+	if err := s.Blockchain.CheckMempoolTimeLocks(in); err != nil {
+		return reject(REJECT_TIMELOCK, err)
+	}
+	// The chain logs why at debug
+	if !s.Blockchain.VerifyTransaction(in) {
+		return reject(REJECT_INVALID, errors.New("Dropping invalid transaction"))
+	}
+	// First come first served, a miner can only take one of them
+	for i, txi := range in.Vin {
+		utxo := chain.UTXO{Transaction: s.Blockchain.GetTransaction(txi.TxID), Index: int(txi.Index)}
+		if s.MemPool.IsSpent(&utxo) {
+			return reject(REJECT_CONFLICT, errors.New(fmt.Sprintf("Input %d is already spent by a transaction in the mempool", i)))
+		}
+	}
+	if err := s.MemPool.CheckRelayFee(in, s.Blockchain.GetTransactionFee(in)); err != nil {
+		return reject(REJECT_FEE, err)
+	}
+	s.MemPool.AddTransaction(in)
+	s.metrics.setMempool(s.MemPool.Transactions)
+	return nil
+}
+
+func (s *Server) ReceiveTransaction(ctx context.Context, in *pb.Transaction) (*pb.Empty, error) {
+	var reply pb.Empty
+	senderIP := p2p.GetSenderIP(ctx)
+	s.metrics.received(in)
+	txID := chain.GetTransactionHash(in)
+	logging.P2P.Debug("Received transaction", logging.Tx(txID), "peer", senderIP)
+	s.stateLock.Lock()
+	_, duplicate := s.MemPool.Transactions[string(txID)]
+	var err error
+	if !duplicate {
+		err = s.acceptTransaction(in)
+	}
+	s.stateLock.Unlock()
+	if duplicate {
+		// Already relayed it, otherwise it would go round loops of peers forever
+		logging.Mempool.Debug("Already have transaction", logging.Tx(txID))
+		s.metrics.rejectTransaction(REJECT_DUPLICATE)
+		return &reply, nil
+	}
+	if err != nil {
+		return &reply, err
+	}
+	for _, myPeer := range s.getPeers() {
+		if senderIP == "" || myPeer.PeerIP == senderIP {
+			// Don't send back to the receiver
+			continue
+		}
+		ipAddr, _ := net.ResolveIPAddr("ip", myPeer.SourceIP)
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: ipAddr})
+		c := pb.NewTransactionsClient(myPeer.Conn)
+		_, err := c.ReceiveTransaction(ctx, in)
+		logging.P2P.Debug("Relayed transaction", logging.Tx(txID), "peer", myPeer.PeerIP, "err", err)
+	}
+	return &reply, nil
+}
+
+// Unlike ReceiveTransaction this comes from a client rather than a peer,
+// so it goes to every peer and says why it was rejected
+func (s *Server) BroadcastTransaction(ctx context.Context, in *pb.Transaction) (*pb.TransactionSent, error) {
+	var reply pb.TransactionSent
+	txID := chain.GetTransactionHash(in)
+	err := s.addAndBroadcast(func() (*pb.Transaction, error) {
+		if _, ok := s.Blockchain.TxIndex[string(txID)]; ok {
+			return nil, errors.New(fmt.Sprintf("Transaction %x is already confirmed", txID))
+		}
+		var broadcast *pb.Transaction
+		if _, ok := s.MemPool.Transactions[string(txID)]; !ok {
+			if err := s.acceptTransaction(in); err != nil {
+				return nil, err
+			}
+			logging.P2P.Info("Broadcasting transaction", logging.Tx(txID))
+			broadcast = in
+		}
+		reply.TxID = txID
+		reply.Inputs = in.Vin
+		reply.Fee = s.Blockchain.GetTransactionFee(in)
+		return broadcast, nil
+	})
+	return &reply, err
+}
@@ -0,0 +1,155 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"golang.org/x/net/context"
+	"strings"
+	"testing"
+)
+
+func TestVerifyTransaction(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	// As if we had mined some coin earlier
+	// Create a signed transaction then ensure that it verifies correctly
+	mint := pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(s.Wallet.Key), Value: chain.BLOCK_REWARD}
+	var txos []*pb.TXO
+	txos = append(txos, &mint)
+	trans := pb.Transaction{Vout: txos}
+	rInt, sInt, _ := ecdsa.Sign(rand.Reader, s.Wallet.Key, chain.GetTransactionHash(&trans))
+	// Returns two big ints
+	trans.Signature = chain.GetSignatureBytes(rInt, sInt)
+	if !s.Blockchain.VerifyTransaction(&trans) {
+		t.Fail()
+	}
+	// Mine a block so we have some money
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 2)
+	balance := s.Blockchain.GetBalance(&s.Wallet.Key.PublicKey)
+	inputUTXOs := wallet.GetUTXOsToCoverTransaction(s.Blockchain, s.Wallet.Key, chain.BLOCK_REWARD)
+	//     t.Log(balance)
+	//     t.Log(s.Blockchain.txIndex)
+	// Spend a valid amount
+	var vout []*pb.TXO
+	var vin []*pb.TXI
+	var txi pb.TXI
+	var txo pb.TXO
+	txi.TxID = chain.GetTransactionHash(inputUTXOs[0].Transaction)
+	txi.Index = uint64(inputUTXOs[0].Index)
+	// Just send all of it back to our selves for simplicity
+	txo.ReceiverPubKey = chain.GetPubKeyBytes(s.Wallet.Key)
+	txo.Value = chain.BLOCK_REWARD
+	vin = append(vin, &txi)
+	vout = append(vout, &txo)
+	var spend pb.Transaction
+	spend.Vin = vin
+	spend.Vout = vout
+	rInt, sInt, _ = ecdsa.Sign(rand.Reader, s.Wallet.Key, chain.GetTransactionHash(&spend))
+	// Returns two big ints
+	spend.Signature = chain.GetSignatureBytes(rInt, sInt)
+	_, err := s.ReceiveTransaction(context.Background(), &spend)
+	// Should acccept this transaction
+	if err != nil {
+		t.Log("Should have accepted")
+		t.Fail()
+	}
+	// Should not accept
+	spend.Vout[0].Value = uint64(2) * balance
+	_, err = s.ReceiveTransaction(context.Background(), &spend)
+	if err == nil {
+		t.Log("Should not have accepted")
+		t.Fail()
+	}
+}
+
+func TestSend(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	req := pb.TransactionRequest{Value: 100, ReceiverPubKey: chain.GetPubKeyBytes(receiverKey)}
+	// Should fail because we have no money
+	_, err := s.SendTransaction(context.Background(), &req)
+	if err == nil {
+		t.Error("Send test should have failed, no UTXO can cover that transaction")
+	}
+}
+
+// Pay a fee, which should end up back with us since we are also the miner
+func TestSendWithFee(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 3)
+	receiverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	req := pb.TransactionRequest{Value: 5, ReceiverPubKey: chain.GetPubKeyBytes(receiverKey), FeeRate: 50}
+	sent, err := s.SendTransaction(context.Background(), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent.Inputs) == 0 || sent.Fee == 0 {
+		t.Errorf("Expected inputs and a fee to be reported, got %d inputs fee %d", len(sent.Inputs), sent.Fee)
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	if recv := s.Blockchain.GetBalance(&receiverKey.PublicKey); recv != 5 {
+		t.Errorf("Receiver balance is %d should be 5", recv)
+	}
+	desiredBalance := chain.BLOCK_REWARD*(len(s.Blockchain.Blocks)-1) - 5
+	if balance := s.Blockchain.GetBalance(&s.Wallet.Key.PublicKey); int(balance) != desiredBalance {
+		t.Errorf("Balance is %d should be %d", balance, desiredBalance)
+	}
+}
+
+// Pay several receivers in one transaction with a fixed fee and change
+// sent to a separate address
+func TestBatchSend(t *testing.T) {
+	s := NewServer()
+	s.Wallet.CreateKey()
+	target, _ := hex.DecodeString(strings.Join([]string{"2", strings.Repeat("f", 19)}, ""))
+	s.Blockchain.SetTarget(target)
+	// Spend mining rewards straight away
+	s.Blockchain.CoinbaseMaturity = 1
+	mineBlocks(s, t, 4)
+	var receivers []*ecdsa.PrivateKey
+	var req pb.TransactionRequest
+	for i := 1; i <= 3; i++ {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		receivers = append(receivers, key)
+		req.Outputs = append(req.Outputs, &pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(key), Value: uint64(i)})
+	}
+	changeKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	req.ChangePubKey = chain.GetPubKeyBytes(changeKey)
+	req.Fee = 2
+	sent, err := s.SendTransaction(context.Background(), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Fee != 2 || len(s.MemPool.Transactions) != 1 {
+		t.Errorf("Expected one transaction paying a fee of 2, fee %d transactions %d", sent.Fee, len(s.MemPool.Transactions))
+	}
+	mineBlocks(s, t, s.Blockchain.NextBlockNum)
+	for i, key := range receivers {
+		if balance := s.Blockchain.GetBalance(&key.PublicKey); balance != uint64(i+1) {
+			t.Errorf("Receiver %d balance is %d should be %d", i, balance, i+1)
+		}
+	}
+	if balance := s.Blockchain.GetBalance(&changeKey.PublicKey); balance != sent.Change {
+		t.Errorf("Change address balance is %d should be %d", balance, sent.Change)
+	}
+	// Zero value outputs are rejected
+	req = pb.TransactionRequest{Outputs: []*pb.TXO{&pb.TXO{ReceiverPubKey: chain.GetPubKeyBytes(changeKey)}}}
+	if _, err = s.SendTransaction(context.Background(), &req); err == nil {
+		t.Error("Should not send a zero value output")
+	}
+}
@@ -0,0 +1,65 @@
+package nodetest
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"testing"
+	"time"
+)
+
+// Slow links with messages overtaking each other still end up with
+// everyone agreeing, and relaying each transaction once
+func TestLatency(t *testing.T) {
+	network := NewNetwork(t, Mesh(4))
+	for i := range network.Nodes {
+		network.SetFaults(i, -1, &pb.LinkFaults{Latency: 5, Jitter: 20})
+	}
+	network.Mine(0, 3)
+	if !network.WaitForHeight(4) || !network.WaitForConvergence() {
+		t.Fatal("Slow mesh didn't converge")
+	}
+	txID := network.Send(0, 2, 4)
+	if !network.WaitForTransaction(txID) {
+		t.Fatal("Transaction didn't reach everyone")
+	}
+	for _, n := range network.Nodes {
+		if mempool := n.Mempool(); len(mempool) != 1 {
+			t.Errorf("Node %d has %d transactions", n.Index, len(mempool))
+		}
+	}
+}
+
+func TestLoss(t *testing.T) {
+	network := NewNetwork(t, Line(3))
+	network.SetFaults(0, 1, &pb.LinkFaults{Loss: 1})
+	network.Mine(0, 1)
+	time.Sleep(100 * time.Millisecond)
+	for _, n := range network.Nodes[1:] {
+		if n.Height() != 1 {
+			t.Errorf("Node %d got a block over a link dropping everything", n.Index)
+		}
+	}
+	c := pb.NewAdminClient(network.Nodes[0].Client())
+	if _, err := c.ClearFaults(context.Background(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if faults, _ := c.GetFaults(context.Background(), &pb.Empty{}); len(faults.Links) != 0 {
+		t.Errorf("Faults left after clearing %v", faults.Links)
+	}
+}
+
+// A held back block arrives after the next one, node 1 should still end up
+// with both. Right now it drops the next one for being out of order, then
+// the held back one for being behind, and can't ask for them again
+func TestReorder(t *testing.T) {
+	t.Skip("no block sync yet")
+	network := NewNetwork(t, Line(2))
+	// Hold back only the first one
+	network.SetFaults(0, 1, &pb.LinkFaults{Reorder: 1})
+	network.Mine(0, 1)
+	network.SetFaults(0, 1, &pb.LinkFaults{})
+	network.Mine(0, 1)
+	if !network.WaitForHeight(3, 1) || !network.WaitForConvergence() {
+		t.Fatal("Node 1 never caught up after the held back block")
+	}
+}
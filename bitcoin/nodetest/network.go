@@ -0,0 +1,484 @@
+// Networks of nodes in one process for tests. Each node gets a made up IP
+// and serves gRPC on an in memory bufconn listener, so nodes peer, relay
+// and see who sent them what the same as on the docker network, just fast
+// enough to run in go test:
+//
+//	network := nodetest.NewNetwork(t, nodetest.Line(3))
+//	network.Mine(0, 2)
+//	if !network.WaitForConvergence() {
+//		t.Fatal("Blocks didn't propagate")
+//	}
+//
+// Links can be cut with Partition and restored with Heal, or made slow
+// and unreliable with SetFaults. Helpers look at nodes through their RPCs,
+// like the client would, so tests don't race the nodes' own goroutines.
+package nodetest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/config"
+	"github.com/connorwstein/Blockchain/bitcoin/node"
+	"github.com/connorwstein/Blockchain/bitcoin/p2p"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	BUFFER_SIZE     = 1 << 20
+	IP_PREFIX       = "10.0.0." // Node i is IP_PREFIX + i + 1
+	CLIENT_IP       = "127.0.0.1"
+	WAIT_TIMEOUT    = 10 * time.Second
+	POLL_INTERVAL   = 10 * time.Millisecond
+	RECONNECT_DELAY = 50 * time.Millisecond // Longest a dropped link waits before redialing
+)
+
+// Which nodes peer with each other, links go both ways
+type Topology struct {
+	Nodes int
+	Links [][2]int
+}
+
+// 0 - 1 - 2 ... n-1
+func Line(n int) Topology {
+	topology := Topology{Nodes: n}
+	for i := 0; i+1 < n; i++ {
+		topology.Links = append(topology.Links, [2]int{i, i + 1})
+	}
+	return topology
+}
+
+// A line with the ends joined
+func Ring(n int) Topology {
+	topology := Line(n)
+	if n > 2 {
+		topology.Links = append(topology.Links, [2]int{n - 1, 0})
+	}
+	return topology
+}
+
+// Everyone peers with node 0
+func Star(n int) Topology {
+	topology := Topology{Nodes: n}
+	for i := 1; i < n; i++ {
+		topology.Links = append(topology.Links, [2]int{0, i})
+	}
+	return topology
+}
+
+// Everyone peers with everyone
+func Mesh(n int) Topology {
+	topology := Topology{Nodes: n}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			topology.Links = append(topology.Links, [2]int{i, j})
+		}
+	}
+	return topology
+}
+
+type Node struct {
+	*node.Server
+	Index      int
+	IP         string
+	listener   *bufconn.Listener
+	grpcServer *grpc.Server
+	client     *grpc.ClientConn
+	t          testing.TB
+}
+
+type Network struct {
+	Nodes    []*Node
+	Topology Topology
+	Timeout  time.Duration // How long the WaitFor helpers wait
+	t        testing.TB
+	lock     sync.Mutex
+	cut      map[[2]int]bool       // Links which can't carry anything
+	conns    map[[2]int][]net.Conn // Open connections over each link
+}
+
+// Key for the link between two nodes, the same in both directions
+func link(a int, b int) [2]int {
+	if a > b {
+		return [2]int{b, a}
+	}
+	return [2]int{a, b}
+}
+
+// Regtest nodes which can spend what they mine after 1 block, each with
+// a wallet, peered according to topology
+func NewNetwork(t testing.TB, topology Topology) *Network {
+	network := &Network{
+		Topology: topology,
+		Timeout:  WAIT_TIMEOUT,
+		t:        t,
+		cut:      make(map[[2]int]bool),
+		conns:    make(map[[2]int][]net.Conn)}
+	for i := 0; i < topology.Nodes; i++ {
+		network.Nodes = append(network.Nodes, network.newNode(i))
+	}
+	t.Cleanup(network.Stop)
+	for _, l := range topology.Links {
+		a, b := network.Nodes[l[0]], network.Nodes[l[1]]
+		a.AddPeers([]string{b.IP})
+		b.AddPeers([]string{a.IP})
+	}
+	return network
+}
+
+func (network *Network) newNode(i int) *Node {
+	cfg := config.Default()
+	cfg.Network = chain.REGTEST.Name
+	cfg.MineSpeed = 0
+	cfg.CoinbaseMaturity = 1
+	server, err := node.NewServerFromConfig(cfg)
+	if err != nil {
+		network.t.Fatal(err)
+	}
+	if err := server.Wallet.CreateKey(); err != nil {
+		network.t.Fatal(err)
+	}
+	n := &Node{
+		Server:     server,
+		Index:      i,
+		IP:         fmt.Sprintf("%s%d", IP_PREFIX, i+1),
+		listener:   bufconn.Listen(BUFFER_SIZE),
+		grpcServer: grpc.NewServer(),
+		t:          network.t}
+	server.DialOptions = []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return network.dial(ctx, n.IP, address)
+		}),
+		// Come back quickly after a partition heals
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: POLL_INTERVAL, Multiplier: 1.6, MaxDelay: RECONNECT_DELAY},
+			MinConnectTimeout: time.Second})}
+	node.RegisterServices(n.grpcServer, server)
+	go n.grpcServer.Serve(listener{n.listener})
+	// Doesn't connect until the first RPC
+	n.client, err = grpc.Dial("bufconn", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			conn, err := n.listener.DialContext(ctx)
+			if err == nil {
+				_, err = conn.Write(net.ParseIP(CLIENT_IP).To4())
+			}
+			return conn, err
+		}))
+	if err != nil {
+		network.t.Fatal(err)
+	}
+	return n
+}
+
+func (network *Network) nodeAt(ip string) *Node {
+	for _, n := range network.Nodes {
+		if n.IP == ip {
+			return n
+		}
+	}
+	return nil
+}
+
+// Connect fromIP to the node listening at address, unless the link
+// between them is cut
+func (network *Network) dial(ctx context.Context, fromIP string, address string) (net.Conn, error) {
+	to := network.nodeAt(p2p.GetPeerHost(address))
+	if to == nil {
+		return nil, errors.New(fmt.Sprintf("No node at %s", address))
+	}
+	from := network.nodeAt(fromIP)
+	network.lock.Lock()
+	defer network.lock.Unlock()
+	if from != nil && network.cut[link(from.Index, to.Index)] {
+		return nil, errors.New(fmt.Sprintf("Link %s to %s is cut", fromIP, to.IP))
+	}
+	conn, err := to.listener.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Tell the other end who we are, bufconn addresses are all the same
+	if _, err := conn.Write(net.ParseIP(fromIP).To4()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if from != nil {
+		key := link(from.Index, to.Index)
+		network.conns[key] = append(network.conns[key], conn)
+	}
+	return conn, nil
+}
+
+// Gives accepted connections the IP of whoever dialed them
+type listener struct {
+	*bufconn.Listener
+}
+
+type ipConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (conn ipConn) RemoteAddr() net.Addr {
+	return conn.remote
+}
+
+func (l listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		ip := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			// Closed before saying who it was, wait for the next one
+			conn.Close()
+			continue
+		}
+		return ipConn{conn, &net.TCPAddr{IP: net.IP(ip)}}, nil
+	}
+}
+
+// Connection for calling a node's RPCs the way the client does
+func (n *Node) Client() *grpc.ClientConn {
+	return n.client
+}
+
+func (n *Node) miningStatus() *pb.MiningStatus {
+	status, err := pb.NewMinerClient(n.client).GetMiningStatus(context.Background(), &pb.Empty{})
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	return status
+}
+
+func (n *Node) TipHash() []byte {
+	return n.miningStatus().Tip
+}
+
+func (n *Node) Tip() *pb.Block {
+	block, err := pb.NewStateClient(n.client).GetBlock(context.Background(), &pb.BlockQuery{Hash: n.TipHash()})
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	return block
+}
+
+func (n *Node) Height() uint64 {
+	return n.miningStatus().Height
+}
+
+func (n *Node) Address() string {
+	return n.Blockchain.Params.GetAddress(chain.GetPubKeyBytes(n.Wallet.Key))
+}
+
+// In the mempool or a block
+func (n *Node) HasTransaction(txID []byte) bool {
+	_, err := pb.NewStateClient(n.client).GetTransactionInfo(context.Background(), &pb.TransactionQuery{TxID: txID})
+	if status.Code(err) == codes.NotFound {
+		return false
+	}
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	return true
+}
+
+// Unconfirmed transactions by ID
+func (n *Node) Mempool() map[string]*pb.Transaction {
+	stream, err := pb.NewStateClient(n.client).GetTransactions(context.Background(), &pb.Empty{})
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	mempool := make(map[string]*pb.Transaction)
+	for {
+		transaction, err := stream.Recv()
+		if err == io.EOF {
+			return mempool
+		}
+		if err != nil {
+			n.t.Fatal(err)
+		}
+		mempool[string(chain.GetTransactionHash(transaction))] = transaction
+	}
+}
+
+// What this node thinks address has confirmed
+func (n *Node) Balance(address string) uint64 {
+	balance, err := pb.NewStateClient(n.client).GetAddressBalance(context.Background(), &pb.AddressQuery{Address: address})
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	return balance.Balance
+}
+
+// Node i mines blocks straight away, relaying each to its peers
+func (network *Network) Mine(i int, blocks int) []*pb.Block {
+	mined, err := network.Nodes[i].Generate(blocks)
+	if err != nil {
+		network.t.Fatal(err)
+	}
+	return mined
+}
+
+// Node from pays node to amount, returning the transaction ID
+func (network *Network) Send(from int, to int, amount uint64) []byte {
+	req := pb.TransactionRequest{Value: amount, ReceiverPubKey: chain.GetPubKeyBytes(network.Nodes[to].Wallet.Key)}
+	sent, err := network.Nodes[from].SendTransaction(context.Background(), &req)
+	if err != nil {
+		network.t.Fatal(err)
+	}
+	return sent.TxID
+}
+
+// Faults on the messages node from sends node to, or every peer if to
+// is -1. Unlike Partition this only affects one direction
+func (network *Network) SetFaults(from int, to int, faults *pb.LinkFaults) {
+	faults.Peer = ""
+	if to >= 0 {
+		faults.Peer = network.Nodes[to].IP
+	}
+	if _, err := network.Nodes[from].SetFaults(context.Background(), faults); err != nil {
+		network.t.Fatal(err)
+	}
+}
+
+// Cut every link between nodes in different groups, nodes not in any
+// group are together in one more
+func (network *Network) Partition(groups ...[]int) {
+	group := make(map[int]int)
+	for g, nodes := range groups {
+		for _, i := range nodes {
+			group[i] = g + 1
+		}
+	}
+	network.lock.Lock()
+	defer network.lock.Unlock()
+	for i := range network.Nodes {
+		for j := i + 1; j < len(network.Nodes); j++ {
+			if group[i] == group[j] {
+				continue
+			}
+			key := link(i, j)
+			network.cut[key] = true
+			for _, conn := range network.conns[key] {
+				conn.Close()
+			}
+			delete(network.conns, key)
+		}
+	}
+}
+
+// Restore every link, returning once every node is connected to all its
+// peers again
+func (network *Network) Heal() {
+	network.lock.Lock()
+	network.cut = make(map[[2]int]bool)
+	network.lock.Unlock()
+	if !network.WaitForPeers() {
+		network.t.Fatal("Peers didn't reconnect after healing")
+	}
+}
+
+// Peers of node i in the topology
+func (network *Network) degree(i int) int {
+	peers := 0
+	for _, l := range network.Topology.Links {
+		if l[0] == i || l[1] == i {
+			peers++
+		}
+	}
+	return peers
+}
+
+// Poll until done or the network's Timeout, returns whether it got done
+func (network *Network) WaitFor(done func() bool) bool {
+	timeout := time.After(network.Timeout)
+	ticker := time.NewTicker(POLL_INTERVAL)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-timeout:
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// All the nodes, or just those given
+func (network *Network) pick(nodes []int) []*Node {
+	if len(nodes) == 0 {
+		return network.Nodes
+	}
+	var picked []*Node
+	for _, i := range nodes {
+		picked = append(picked, network.Nodes[i])
+	}
+	return picked
+}
+
+// Every node, or those given, has the same tip
+func (network *Network) WaitForConvergence(nodes ...int) bool {
+	picked := network.pick(nodes)
+	return network.WaitFor(func() bool {
+		tip := picked[0].TipHash()
+		for _, n := range picked[1:] {
+			if string(n.TipHash()) != string(tip) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (network *Network) WaitForHeight(height uint64, nodes ...int) bool {
+	return network.WaitFor(func() bool {
+		for _, n := range network.pick(nodes) {
+			if n.Height() < height {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (network *Network) WaitForTransaction(txID []byte, nodes ...int) bool {
+	return network.WaitFor(func() bool {
+		for _, n := range network.pick(nodes) {
+			if !n.HasTransaction(txID) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Every node has a working connection to each of its peers in the topology
+func (network *Network) WaitForPeers() bool {
+	return network.WaitFor(func() bool {
+		for i, n := range network.Nodes {
+			if n.ConnectedPeers() < network.degree(i) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (network *Network) Stop() {
+	for _, n := range network.Nodes {
+		n.client.Close()
+		n.grpcServer.Stop()
+	}
+}
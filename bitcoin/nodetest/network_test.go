@@ -0,0 +1,144 @@
+package nodetest
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+// Blocks and transactions hop along a line to the far end
+func TestPropagation(t *testing.T) {
+	network := NewNetwork(t, Line(4))
+	network.Mine(0, 3)
+	if !network.WaitForHeight(4) || !network.WaitForConvergence() {
+		t.Fatalf("Blocks didn't reach the end of the line, node 3 at height %d", network.Nodes[3].Height())
+	}
+	txID := network.Send(0, 3, 8)
+	if !network.WaitForTransaction(txID) {
+		t.Fatal("Transaction didn't reach every node")
+	}
+	// Whoever mines it, everyone ends up with it confirmed
+	network.Mine(3, 1)
+	if !network.WaitForConvergence() {
+		t.Fatal("Block from the end of the line didn't get back")
+	}
+	for _, n := range network.Nodes {
+		if _, ok := n.Mempool()[string(txID)]; ok {
+			t.Errorf("Node %d still has the mined transaction in its mempool", n.Index)
+		}
+	}
+	if balance := network.Nodes[0].Balance(network.Nodes[3].Address()); balance != 8+chain.BLOCK_REWARD {
+		t.Errorf("Node 0 thinks node 3 has %d", balance)
+	}
+}
+
+// Transactions going round a loop of peers stop once everyone has them
+func TestRingRelay(t *testing.T) {
+	network := NewNetwork(t, Ring(5))
+	network.Mine(2, 2)
+	if !network.WaitForConvergence() {
+		t.Fatal("Ring didn't converge")
+	}
+	txID := network.Send(2, 0, 3)
+	if !network.WaitForTransaction(txID) {
+		t.Fatal("Transaction didn't go round the ring")
+	}
+	for _, n := range network.Nodes {
+		if mempool := n.Mempool(); len(mempool) != 1 {
+			t.Errorf("Node %d has %d transactions", n.Index, len(mempool))
+		}
+	}
+}
+
+func TestPartition(t *testing.T) {
+	network := NewNetwork(t, Mesh(4))
+	network.Mine(0, 2)
+	if !network.WaitForConvergence() {
+		t.Fatal("Mesh didn't converge")
+	}
+	network.Partition([]int{0, 1}, []int{2, 3})
+	// Relaying is done by the time Send returns, so the other side has
+	// had its chance to hear about it
+	before := network.Send(0, 1, 5)
+	if !network.WaitForTransaction(before, 0, 1) {
+		t.Fatal("Transaction didn't cross the 0, 1 side")
+	}
+	for _, i := range []int{2, 3} {
+		if network.Nodes[i].HasTransaction(before) {
+			t.Errorf("Node %d got a transaction across the partition", i)
+		}
+	}
+	network.Heal()
+	after := network.Send(0, 2, 5)
+	if !network.WaitForTransaction(after) {
+		t.Fatal("Transaction didn't reach everyone after healing")
+	}
+	// The block from the 0, 1 side brings the other side the transaction it missed
+	network.Mine(1, 1)
+	if !network.WaitForConvergence() || !network.WaitForTransaction(before) {
+		t.Fatal("Block didn't reach everyone after healing")
+	}
+	if balance := network.Nodes[3].Balance(network.Nodes[2].Address()); balance != 5 {
+		t.Errorf("Node 3 thinks node 2 has %d", balance)
+	}
+}
+
+// Both sides of a partition mine their own blocks. A fork of the same
+// height is settled by the next block, which everyone takes since it's the
+// height they are looking for
+func TestFork(t *testing.T) {
+	network := NewNetwork(t, Mesh(4))
+	network.Mine(0, 1)
+	if !network.WaitForConvergence() {
+		t.Fatal("Mesh didn't converge")
+	}
+	network.Partition([]int{0, 1}, []int{2, 3})
+	network.Mine(0, 1)
+	network.Mine(2, 1)
+	if !network.WaitForConvergence(0, 1) || !network.WaitForConvergence(2, 3) {
+		t.Fatal("Sides of the partition didn't converge")
+	}
+	if string(network.Nodes[0].TipHash()) == string(network.Nodes[2].TipHash()) {
+		t.Fatal("Both sides mined the same block")
+	}
+	network.Heal()
+	mined := network.Mine(1, 1)
+	if !network.WaitForConvergence() || string(network.Nodes[3].TipHash()) != string(chain.GetBlockHash(mined[0])) {
+		t.Fatal("Next block didn't settle the fork")
+	}
+}
+
+// A side more than one block behind has to fetch the blocks it's missing
+// from the longer side. Nodes only take the next block by height and can't
+// ask peers for others, so it stays on its fork
+func TestForkTwoBehind(t *testing.T) {
+	t.Skip("no block sync yet")
+	network := NewNetwork(t, Mesh(4))
+	network.Mine(0, 1)
+	if !network.WaitForConvergence() {
+		t.Fatal("Mesh didn't converge")
+	}
+	network.Partition([]int{0, 1}, []int{2, 3})
+	network.Mine(0, 2)
+	network.Mine(2, 1)
+	network.Heal()
+	mined := network.Mine(0, 1)
+	if !network.WaitForConvergence() || string(network.Nodes[2].TipHash()) != string(chain.GetBlockHash(mined[0])) {
+		t.Fatal("Shorter side didn't move to the longer one")
+	}
+}
+
+// RPCs work over the in memory transport too
+func TestClient(t *testing.T) {
+	network := NewNetwork(t, Star(3))
+	c := pb.NewMinerClient(network.Nodes[1].Client())
+	reply, err := c.GenerateBlocks(context.Background(), &pb.GenerateRequest{Blocks: 2, Address: network.Nodes[2].Address()})
+	if err != nil || len(reply.Hashes) != 2 {
+		t.Fatalf("Generated %v %v", reply, err)
+	}
+	// Through the centre of the star
+	if !network.WaitForConvergence() {
+		t.Fatal("Generated blocks didn't propagate")
+	}
+}
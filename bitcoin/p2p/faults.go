@@ -0,0 +1,143 @@
+// Fault injection for testing propagation over a bad network. Every
+// message we send a peer goes through an interceptor which can delay,
+// drop or reorder it, or refuse it altogether to partition us from the
+// peer. Faults are per peer and can be changed while running.
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// How long a reordered message is held back, long enough for whatever
+// is sent next to overtake it
+const REORDER_DELAY = 100 * time.Millisecond
+
+// Relayed messages whose replies are ignored, so they can be delivered
+// later than they are sent
+var ONE_WAY_METHODS = map[string]bool{
+	"/protos.Blocks/ReceiveBlock":             true,
+	"/protos.Transactions/ReceiveTransaction": true,
+}
+
+type LinkFaults struct {
+	Peer    string // As in our peer list, empty for every peer without its own
+	Latency time.Duration
+	Jitter  time.Duration // Random extra latency up to this
+	Loss    float64       // Chance of each message being dropped
+	Reorder float64       // Chance of a relayed block or transaction being overtaken by later ones
+	Down    bool          // Partitioned, nothing gets through
+}
+
+func (faults LinkFaults) Check() error {
+	if faults.Loss < 0 || faults.Loss > 1 || faults.Reorder < 0 || faults.Reorder > 1 {
+		return errors.New(fmt.Sprintf("Loss and reorder are chances between 0 and 1, got %v and %v", faults.Loss, faults.Reorder))
+	}
+	if faults.Latency < 0 || faults.Jitter < 0 {
+		return errors.New("Latency and jitter can't be negative")
+	}
+	return nil
+}
+
+type FaultInjector struct {
+	mutex sync.Mutex
+	links map[string]LinkFaults
+	rand  *rand.Rand
+}
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{links: make(map[string]LinkFaults), rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Replaces any faults already on the link
+func (injector *FaultInjector) Set(faults LinkFaults) error {
+	if err := faults.Check(); err != nil {
+		return err
+	}
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	injector.links[faults.Peer] = faults
+	return nil
+}
+
+// Back to a perfect network
+func (injector *FaultInjector) Clear() {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	injector.links = make(map[string]LinkFaults)
+}
+
+// Faults on the link to peer
+func (injector *FaultInjector) Get(peer string) LinkFaults {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	if faults, ok := injector.links[peer]; ok {
+		return faults
+	}
+	faults := injector.links[""]
+	faults.Peer = peer
+	return faults
+}
+
+// Every link with its own faults, and the default if set
+func (injector *FaultInjector) List() []LinkFaults {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	var links []LinkFaults
+	for _, faults := range injector.links {
+		links = append(links, faults)
+	}
+	return links
+}
+
+func (injector *FaultInjector) chance(p float64) bool {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	return p > 0 && injector.rand.Float64() < p
+}
+
+func (injector *FaultInjector) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+	return time.Duration(injector.rand.Int63n(int64(max)))
+}
+
+// Applies the faults on the link to peer to every call made on it
+func (injector *FaultInjector) Interceptor(peer string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		faults := injector.Get(peer)
+		if faults.Down {
+			return errors.New(fmt.Sprintf("Link to %s is down", peer))
+		}
+		if injector.chance(faults.Loss) {
+			return errors.New(fmt.Sprintf("Dropped %s to %s", method, peer))
+		}
+		delay := faults.Latency + injector.jitter(faults.Jitter)
+		if ONE_WAY_METHODS[method] && injector.chance(faults.Reorder) {
+			// Nobody is waiting on the reply so send it in the background,
+			// the caller carries on and sends the next one first
+			reply = proto.Clone(reply.(proto.Message))
+			go func() {
+				time.Sleep(delay + REORDER_DELAY)
+				invoker(context.Background(), method, req, reply, cc, opts...)
+			}()
+			return nil
+		}
+		time.Sleep(delay)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Dial option putting the injector under a connection to peer
+func (injector *FaultInjector) DialOption(peer string) grpc.DialOption {
+	return grpc.WithUnaryInterceptor(injector.Interceptor(peer))
+}
@@ -0,0 +1,65 @@
+package p2p
+
+import (
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"testing"
+	"time"
+)
+
+// Calls the interceptor for peer with a fake connection, returning a
+// channel which gets the time the message was actually sent and its error
+func send(injector *FaultInjector, peer string, method string) (chan time.Time, error) {
+	sent := make(chan time.Time, 1)
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent <- time.Now()
+		return nil
+	}
+	err := injector.Interceptor(peer)(context.Background(), method, &pb.Block{}, &pb.Empty{}, nil, invoker)
+	return sent, err
+}
+
+func TestFaultInjector(t *testing.T) {
+	injector := NewFaultInjector()
+	if sent, err := send(injector, "a", "/protos.Blocks/ReceiveBlock"); err != nil || len(sent) != 1 {
+		t.Errorf("No faults should send straight away, got %v", err)
+	}
+	injector.Set(LinkFaults{Down: true})
+	injector.Set(LinkFaults{Peer: "b", Latency: 50 * time.Millisecond})
+	if sent, err := send(injector, "a", "/protos.Peering/Connect"); err == nil || len(sent) != 0 {
+		t.Error("Default faults should apply to peers without their own")
+	}
+	start := time.Now()
+	if sent, err := send(injector, "b", "/protos.Peering/Connect"); err != nil || len(sent) != 1 || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("Latency to b not applied, %v", err)
+	}
+	if faults := injector.Get("c"); !faults.Down || faults.Peer != "c" {
+		t.Errorf("Faults to c are %+v", faults)
+	}
+	injector.Clear()
+	injector.Set(LinkFaults{Loss: 1})
+	if sent, err := send(injector, "a", "/protos.Blocks/ReceiveBlock"); err == nil || len(sent) != 0 {
+		t.Error("Loss of 1 should drop everything")
+	}
+	// Held back relays return straight away and send later
+	injector.Set(LinkFaults{Reorder: 1})
+	start = time.Now()
+	sent, err := send(injector, "a", "/protos.Blocks/ReceiveBlock")
+	if err != nil || len(sent) != 0 {
+		t.Fatalf("Reordered block should be sent later, got %v", err)
+	}
+	if at := <-sent; at.Sub(start) < REORDER_DELAY {
+		t.Errorf("Reordered block sent after %v", at.Sub(start))
+	}
+	// Other calls need their reply so can't be reordered
+	if sent, err := send(injector, "a", "/protos.Peering/Connect"); err != nil || len(sent) != 1 {
+		t.Errorf("Connect should not be reordered, %v", err)
+	}
+	if err := injector.Set(LinkFaults{Loss: 1.5}); err == nil {
+		t.Error("Loss over 1 should be invalid")
+	}
+	if len(injector.List()) != 1 {
+		t.Errorf("Should only have the default faults, got %v", injector.List())
+	}
+}
@@ -0,0 +1,113 @@
+// Peer connections and working out which of our addresses a peer sees.
+package p2p
+
+import (
+	"bufio"
+	"errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"net"
+	"os"
+)
+
+type Peer struct {
+	Conn     *grpc.ClientConn
+	PeerIP   string
+	SourceIP string
+}
+
+func GetOutgoingIP(ourIPs []net.IPNet, peerIP string) (string, error) {
+	// Determine which one of our IPs is in the same network as the peer
+	ipPeer := net.ParseIP(peerIP)
+	for _, ip := range ourIPs {
+		if ip.Contains(ipPeer) {
+			return ip.IP.String(), nil
+		}
+	}
+	return "", errors.New("Can't find outgoing IP for peer")
+}
+
+func GetSenderIP(ctx context.Context) string {
+	var result string
+	peerIP, _ := peer.FromContext(ctx)
+	if peerIP == nil {
+		return ""
+	}
+	switch senderAddr := peerIP.Addr.(type) {
+	case *net.TCPAddr:
+		// Expected case
+		result = senderAddr.IP.String()
+	default:
+		result = ""
+	}
+	return result
+}
+
+func GetOurIPs() []net.IPNet {
+	var ips []net.IPNet
+	ifaces, _ := net.Interfaces()
+	// Remove our own address from the node list
+	for _, i := range ifaces {
+		// Ignore loopback interfaces
+		if i.Name == "lo" {
+			continue
+		}
+		addrs, _ := i.Addrs()
+		for _, a := range addrs {
+			switch v := a.(type) {
+			case *net.IPNet:
+				if v.IP.To4() != nil {
+					ips = append(ips, *v)
+				}
+			}
+		}
+	}
+	return ips
+}
+
+func RemoveOurIPs(ourIPs []net.IPNet, otherIPs []string) []string {
+	var result []string
+	for i := range otherIPs {
+		ours := false
+		for j := range ourIPs {
+			if ourIPs[j].IP.String() == otherIPs[i] {
+				ours = true
+			}
+		}
+		if !ours {
+			result = append(result, otherIPs[i])
+		}
+	}
+	return result
+}
+
+// Peers without a port are assumed to listen on the default one
+func GetPeerAddress(peer string, defaultPort string) string {
+	if _, _, err := net.SplitHostPort(peer); err == nil {
+		return peer
+	}
+	return net.JoinHostPort(peer, defaultPort)
+}
+
+func GetPeerHost(peer string) string {
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		return host
+	}
+	return peer
+}
+
+func GetNodeList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,58 @@
+// Network adjusted time. Blocks too far past it are rejected, so a node
+// with a slow clock would otherwise reject blocks everyone else accepts.
+package p2p
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/logging"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Peers further out than this probably have a broken clock
+const MAX_TIME_ADJUSTMENT = 70 * 60
+
+// Our clock corrected by the median offset of our peers' clocks,
+// sampled when we handshake with them
+type NetworkTime struct {
+	mutex   sync.Mutex
+	offsets map[string]int64 // Peer's clock minus ours in seconds
+}
+
+func NewNetworkTime() *NetworkTime {
+	return &NetworkTime{offsets: make(map[string]int64)}
+}
+
+func (networkTime *NetworkTime) AddSample(peer string, peerTime uint64) {
+	if peerTime == 0 {
+		// Peer didn't tell us its time
+		return
+	}
+	networkTime.mutex.Lock()
+	defer networkTime.mutex.Unlock()
+	networkTime.offsets[peer] = int64(peerTime) - time.Now().Unix()
+}
+
+// Median of the peer offsets, counting our own clock as an offset of 0
+func (networkTime *NetworkTime) GetOffset() int64 {
+	if networkTime == nil {
+		return 0
+	}
+	networkTime.mutex.Lock()
+	defer networkTime.mutex.Unlock()
+	offsets := []int64{0}
+	for _, offset := range networkTime.offsets {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	median := offsets[len(offsets)/2]
+	if median > MAX_TIME_ADJUSTMENT || median < -MAX_TIME_ADJUSTMENT {
+		logging.P2P.Warn("Peers' clocks are too far from ours to adjust, check the time is right", "offset", median)
+		return 0
+	}
+	return median
+}
+
+func (networkTime *NetworkTime) Now() uint64 {
+	return uint64(time.Now().Unix() + networkTime.GetOffset())
+}
@@ -0,0 +1,28 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNetworkTime(t *testing.T) {
+	var missing *NetworkTime
+	if missing.GetOffset() != 0 {
+		t.Error("No samples should mean no adjustment")
+	}
+	networkTime := NewNetworkTime()
+	now := uint64(time.Now().Unix())
+	networkTime.AddSample("a", now+100)
+	networkTime.AddSample("b", now+200)
+	networkTime.AddSample("c", now-50)
+	// Median of -50 0 100 200, allowing for the clock ticking
+	if offset := networkTime.GetOffset(); offset < 99 || offset > 100 {
+		t.Errorf("Offset is %d should be 100", offset)
+	}
+	for _, peer := range []string{"a", "b", "c", "d"} {
+		networkTime.AddSample(peer, now+MAX_TIME_ADJUSTMENT*2)
+	}
+	if offset := networkTime.GetOffset(); offset != 0 {
+		t.Errorf("Offset is %d, peers too far out should be ignored", offset)
+	}
+}
@@ -26,8 +26,14 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type TXI struct {
 	// Transaction hash containing UTXO
 	TxID []byte `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	// Only needed when the UTXO has a locking script, satisfies that script.
+	// Not part of the transaction hash since it holds the signatures
+	UnlockingScript []byte `protobuf:"bytes,2,opt,name=unlockingScript,proto3" json:"unlockingScript,omitempty"`
 	// Index within that transaction of UTXO
-	Index                uint64   `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	Index uint64 `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	// Relative lock, the UTXO can only be spent this many blocks after
+	// the block it confirmed in. 0 for none
+	Sequence             uint64   `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -37,7 +43,7 @@ func (m *TXI) Reset()         { *m = TXI{} }
 func (m *TXI) String() string { return proto.CompactTextString(m) }
 func (*TXI) ProtoMessage()    {}
 func (*TXI) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{0}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{0}
 }
 func (m *TXI) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TXI.Unmarshal(m, b)
@@ -64,6 +70,13 @@ func (m *TXI) GetTxID() []byte {
 	return nil
 }
 
+func (m *TXI) GetUnlockingScript() []byte {
+	if m != nil {
+		return m.UnlockingScript
+	}
+	return nil
+}
+
 func (m *TXI) GetIndex() uint64 {
 	if m != nil {
 		return m.Index
@@ -71,9 +84,19 @@ func (m *TXI) GetIndex() uint64 {
 	return 0
 }
 
+func (m *TXI) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
 type TXO struct {
+	// Either a receiver, who spends by signing the transaction,
+	// or a locking script
 	ReceiverPubKey       []byte   `protobuf:"bytes,1,opt,name=receiverPubKey,proto3" json:"receiverPubKey,omitempty"`
 	Value                uint64   `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	LockingScript        []byte   `protobuf:"bytes,3,opt,name=lockingScript,proto3" json:"lockingScript,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -83,7 +106,7 @@ func (m *TXO) Reset()         { *m = TXO{} }
 func (m *TXO) String() string { return proto.CompactTextString(m) }
 func (*TXO) ProtoMessage()    {}
 func (*TXO) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{1}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{1}
 }
 func (m *TXO) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TXO.Unmarshal(m, b)
@@ -117,6 +140,13 @@ func (m *TXO) GetValue() uint64 {
 	return 0
 }
 
+func (m *TXO) GetLockingScript() []byte {
+	if m != nil {
+		return m.LockingScript
+	}
+	return nil
+}
+
 type Transaction struct {
 	Vin       []*TXI `protobuf:"bytes,1,rep,name=vin,proto3" json:"vin,omitempty"`
 	Vout      []*TXO `protobuf:"bytes,3,rep,name=vout,proto3" json:"vout,omitempty"`
@@ -125,7 +155,10 @@ type Transaction struct {
 	// goes in the coinbase script arbirary data section but in general this
 	// resolves the issue of identical coinbase transactions for the same miner
 	// currently only used for coinbase transactions
-	Height               uint64   `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	Height uint64 `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	// Can't be mined until after this block height, or if at least
+	// 500000000 this unix time in seconds. 0 for no lock
+	LockTime             uint64   `protobuf:"varint,7,opt,name=lockTime,proto3" json:"lockTime,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -135,7 +168,7 @@ func (m *Transaction) Reset()         { *m = Transaction{} }
 func (m *Transaction) String() string { return proto.CompactTextString(m) }
 func (*Transaction) ProtoMessage()    {}
 func (*Transaction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{2}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{2}
 }
 func (m *Transaction) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Transaction.Unmarshal(m, b)
@@ -183,6 +216,13 @@ func (m *Transaction) GetHeight() uint64 {
 	return 0
 }
 
+func (m *Transaction) GetLockTime() uint64 {
+	if m != nil {
+		return m.LockTime
+	}
+	return 0
+}
+
 type BlockHeader struct {
 	PrevBlockHash []byte `protobuf:"bytes,1,opt,name=prevBlockHash,proto3" json:"prevBlockHash,omitempty"`
 	// Used to check whether a transaction is in the block
@@ -202,7 +242,7 @@ func (m *BlockHeader) Reset()         { *m = BlockHeader{} }
 func (m *BlockHeader) String() string { return proto.CompactTextString(m) }
 func (*BlockHeader) ProtoMessage()    {}
 func (*BlockHeader) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{3}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{3}
 }
 func (m *BlockHeader) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockHeader.Unmarshal(m, b)
@@ -276,7 +316,7 @@ func (m *Block) Reset()         { *m = Block{} }
 func (m *Block) String() string { return proto.CompactTextString(m) }
 func (*Block) ProtoMessage()    {}
 func (*Block) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{4}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{4}
 }
 func (m *Block) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Block.Unmarshal(m, b)
@@ -320,7 +360,7 @@ func (m *Empty) Reset()         { *m = Empty{} }
 func (m *Empty) String() string { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()    {}
 func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{5}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{5}
 }
 func (m *Empty) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Empty.Unmarshal(m, b)
@@ -340,7 +380,12 @@ func (m *Empty) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
+// Both sides send their clock in the handshake, seconds from epoch
 type Hello struct {
+	Time uint64 `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	// Nodes on different networks refuse to peer
+	Network              string   `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	GenesisHash          []byte   `protobuf:"bytes,3,opt,name=genesis_hash,json=genesisHash,proto3" json:"genesisHash,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -350,7 +395,7 @@ func (m *Hello) Reset()         { *m = Hello{} }
 func (m *Hello) String() string { return proto.CompactTextString(m) }
 func (*Hello) ProtoMessage()    {}
 func (*Hello) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{6}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{6}
 }
 func (m *Hello) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Hello.Unmarshal(m, b)
@@ -370,7 +415,32 @@ func (m *Hello) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Hello proto.InternalMessageInfo
 
+func (m *Hello) GetTime() uint64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Hello) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *Hello) GetGenesisHash() []byte {
+	if m != nil {
+		return m.GenesisHash
+	}
+	return nil
+}
+
 type Ack struct {
+	Time uint64 `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	// Nodes on different networks refuse to peer
+	Network              string   `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	GenesisHash          []byte   `protobuf:"bytes,3,opt,name=genesis_hash,json=genesisHash,proto3" json:"genesisHash,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -380,7 +450,7 @@ func (m *Ack) Reset()         { *m = Ack{} }
 func (m *Ack) String() string { return proto.CompactTextString(m) }
 func (*Ack) ProtoMessage()    {}
 func (*Ack) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{7}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{7}
 }
 func (m *Ack) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Ack.Unmarshal(m, b)
@@ -400,9 +470,51 @@ func (m *Ack) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Ack proto.InternalMessageInfo
 
+func (m *Ack) GetTime() uint64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Ack) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *Ack) GetGenesisHash() []byte {
+	if m != nil {
+		return m.GenesisHash
+	}
+	return nil
+}
+
 type TransactionRequest struct {
-	ReceiverPubKey       []byte   `protobuf:"bytes,1,opt,name=receiverPubKey,proto3" json:"receiverPubKey,omitempty"`
-	Value                uint64   `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Single recipient, kept for simple payments
+	ReceiverPubKey []byte `protobuf:"bytes,1,opt,name=receiverPubKey,proto3" json:"receiverPubKey,omitempty"`
+	Value          uint64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Coin per 1000 bytes of transaction paid to the miner
+	FeeRate uint64 `protobuf:"varint,3,opt,name=feeRate,proto3" json:"feeRate,omitempty"`
+	// Coin selection strategy: bnb (default), largest, smallest or random
+	Strategy string `protobuf:"bytes,4,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	// Batch payment, every recipient is paid in the same transaction
+	Outputs []*TXO `protobuf:"bytes,5,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	// Where change goes, our own key if empty
+	ChangePubKey []byte `protobuf:"bytes,6,opt,name=changePubKey,proto3" json:"changePubKey,omitempty"`
+	// Absolute fee, overrides feeRate when set
+	Fee uint64 `protobuf:"varint,7,opt,name=fee,proto3" json:"fee,omitempty"`
+	// Whose UTXOs fund the transaction, our own key if empty. Only used when
+	// creating an unsigned transaction, lets keys held elsewhere spend
+	SenderPubKey []byte `protobuf:"bytes,8,opt,name=senderPubKey,proto3" json:"senderPubKey,omitempty"`
+	// Spend exactly these UTXOs instead of running coin selection
+	Inputs []*TXI `protobuf:"bytes,9,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	// Fund from outputs locked by this script (e.g. a multisig address)
+	// instead of a sender key, change goes back to the same script
+	SenderScript []byte `protobuf:"bytes,10,opt,name=senderScript,proto3" json:"senderScript,omitempty"`
+	// Absolute lock time of the transaction, see Transaction.lockTime
+	LockTime             uint64   `protobuf:"varint,11,opt,name=lockTime,proto3" json:"lockTime,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -412,7 +524,7 @@ func (m *TransactionRequest) Reset()         { *m = TransactionRequest{} }
 func (m *TransactionRequest) String() string { return proto.CompactTextString(m) }
 func (*TransactionRequest) ProtoMessage()    {}
 func (*TransactionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{8}
+	return fileDescriptor_coin_4577121ffd2361e8, []int{8}
 }
 func (m *TransactionRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TransactionRequest.Unmarshal(m, b)
@@ -446,194 +558,1946 @@ func (m *TransactionRequest) GetValue() uint64 {
 	return 0
 }
 
-type Account struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *TransactionRequest) GetFeeRate() uint64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
 }
 
-func (m *Account) Reset()         { *m = Account{} }
-func (m *Account) String() string { return proto.CompactTextString(m) }
-func (*Account) ProtoMessage()    {}
-func (*Account) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{9}
+func (m *TransactionRequest) GetStrategy() string {
+	if m != nil {
+		return m.Strategy
+	}
+	return ""
 }
-func (m *Account) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Account.Unmarshal(m, b)
+
+func (m *TransactionRequest) GetOutputs() []*TXO {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
 }
-func (m *Account) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Account.Marshal(b, m, deterministic)
+
+func (m *TransactionRequest) GetChangePubKey() []byte {
+	if m != nil {
+		return m.ChangePubKey
+	}
+	return nil
 }
-func (dst *Account) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Account.Merge(dst, src)
+
+func (m *TransactionRequest) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
 }
-func (m *Account) XXX_Size() int {
-	return xxx_messageInfo_Account.Size(m)
+
+func (m *TransactionRequest) GetSenderPubKey() []byte {
+	if m != nil {
+		return m.SenderPubKey
+	}
+	return nil
 }
-func (m *Account) XXX_DiscardUnknown() {
-	xxx_messageInfo_Account.DiscardUnknown(m)
+
+func (m *TransactionRequest) GetInputs() []*TXI {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
 }
 
-var xxx_messageInfo_Account proto.InternalMessageInfo
+func (m *TransactionRequest) GetSenderScript() []byte {
+	if m != nil {
+		return m.SenderScript
+	}
+	return nil
+}
 
-func (m *Account) GetName() string {
+func (m *TransactionRequest) GetLockTime() uint64 {
 	if m != nil {
-		return m.Name
+		return m.LockTime
 	}
-	return ""
+	return 0
 }
 
-type AccountCreated struct {
-	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+type TransactionSent struct {
+	TxID []byte `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	// UTXOs chosen by coin selection to fund the transaction
+	Inputs               []*TXI   `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Fee                  uint64   `protobuf:"varint,3,opt,name=fee,proto3" json:"fee,omitempty"`
+	Change               uint64   `protobuf:"varint,4,opt,name=change,proto3" json:"change,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *AccountCreated) Reset()         { *m = AccountCreated{} }
-func (m *AccountCreated) String() string { return proto.CompactTextString(m) }
-func (*AccountCreated) ProtoMessage()    {}
-func (*AccountCreated) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{10}
+func (m *TransactionSent) Reset()         { *m = TransactionSent{} }
+func (m *TransactionSent) String() string { return proto.CompactTextString(m) }
+func (*TransactionSent) ProtoMessage()    {}
+func (*TransactionSent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{9}
 }
-func (m *AccountCreated) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_AccountCreated.Unmarshal(m, b)
+func (m *TransactionSent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionSent.Unmarshal(m, b)
 }
-func (m *AccountCreated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_AccountCreated.Marshal(b, m, deterministic)
+func (m *TransactionSent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionSent.Marshal(b, m, deterministic)
 }
-func (dst *AccountCreated) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_AccountCreated.Merge(dst, src)
+func (dst *TransactionSent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionSent.Merge(dst, src)
 }
-func (m *AccountCreated) XXX_Size() int {
-	return xxx_messageInfo_AccountCreated.Size(m)
+func (m *TransactionSent) XXX_Size() int {
+	return xxx_messageInfo_TransactionSent.Size(m)
 }
-func (m *AccountCreated) XXX_DiscardUnknown() {
-	xxx_messageInfo_AccountCreated.DiscardUnknown(m)
+func (m *TransactionSent) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionSent.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_AccountCreated proto.InternalMessageInfo
+var xxx_messageInfo_TransactionSent proto.InternalMessageInfo
 
-func (m *AccountCreated) GetAddress() string {
+func (m *TransactionSent) GetTxID() []byte {
 	if m != nil {
-		return m.Address
+		return m.TxID
 	}
-	return ""
+	return nil
 }
 
-type Balance struct {
-	Balance              uint64   `protobuf:"varint,1,opt,name=balance,proto3" json:"balance,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *TransactionSent) GetInputs() []*TXI {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
 }
 
-func (m *Balance) Reset()         { *m = Balance{} }
-func (m *Balance) String() string { return proto.CompactTextString(m) }
-func (*Balance) ProtoMessage()    {}
-func (*Balance) Descriptor() ([]byte, []int) {
-	return fileDescriptor_coin_13d4211dc95ee0ca, []int{11}
+func (m *TransactionSent) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
 }
-func (m *Balance) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Balance.Unmarshal(m, b)
+
+func (m *TransactionSent) GetChange() uint64 {
+	if m != nil {
+		return m.Change
+	}
+	return 0
 }
-func (m *Balance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Balance.Marshal(b, m, deterministic)
+
+// A transaction waiting for signatures from keys which may not be on this
+// node, similar to bitcoin's PSBT (BIP 174)
+type PartialTransaction struct {
+	Transaction *Transaction `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// Outputs being spent, in the same order as transaction.vin, so a
+	// signer can check what it is signing without a copy of the chain
+	Spending             []*TXO              `protobuf:"bytes,2,rep,name=spending,proto3" json:"spending,omitempty"`
+	Signatures           []*PartialSignature `protobuf:"bytes,3,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
-func (dst *Balance) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Balance.Merge(dst, src)
+
+func (m *PartialTransaction) Reset()         { *m = PartialTransaction{} }
+func (m *PartialTransaction) String() string { return proto.CompactTextString(m) }
+func (*PartialTransaction) ProtoMessage()    {}
+func (*PartialTransaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{10}
 }
-func (m *Balance) XXX_Size() int {
-	return xxx_messageInfo_Balance.Size(m)
+func (m *PartialTransaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PartialTransaction.Unmarshal(m, b)
 }
-func (m *Balance) XXX_DiscardUnknown() {
-	xxx_messageInfo_Balance.DiscardUnknown(m)
+func (m *PartialTransaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PartialTransaction.Marshal(b, m, deterministic)
+}
+func (dst *PartialTransaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PartialTransaction.Merge(dst, src)
+}
+func (m *PartialTransaction) XXX_Size() int {
+	return xxx_messageInfo_PartialTransaction.Size(m)
+}
+func (m *PartialTransaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_PartialTransaction.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Balance proto.InternalMessageInfo
+var xxx_messageInfo_PartialTransaction proto.InternalMessageInfo
 
-func (m *Balance) GetBalance() uint64 {
+func (m *PartialTransaction) GetTransaction() *Transaction {
 	if m != nil {
-		return m.Balance
+		return m.Transaction
 	}
-	return 0
+	return nil
 }
 
-func init() {
-	proto.RegisterType((*TXI)(nil), "protos.TXI")
-	proto.RegisterType((*TXO)(nil), "protos.TXO")
-	proto.RegisterType((*Transaction)(nil), "protos.Transaction")
-	proto.RegisterType((*BlockHeader)(nil), "protos.BlockHeader")
-	proto.RegisterType((*Block)(nil), "protos.Block")
-	proto.RegisterType((*Empty)(nil), "protos.Empty")
-	proto.RegisterType((*Hello)(nil), "protos.Hello")
-	proto.RegisterType((*Ack)(nil), "protos.Ack")
-	proto.RegisterType((*TransactionRequest)(nil), "protos.TransactionRequest")
-	proto.RegisterType((*Account)(nil), "protos.Account")
-	proto.RegisterType((*AccountCreated)(nil), "protos.AccountCreated")
-	proto.RegisterType((*Balance)(nil), "protos.Balance")
+func (m *PartialTransaction) GetSpending() []*TXO {
+	if m != nil {
+		return m.Spending
+	}
+	return nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *PartialTransaction) GetSignatures() []*PartialSignature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
 
-// PeeringClient is the client API for Peering service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type PeeringClient interface {
-	// Could add version exchange during peer connection
-	Connect(ctx context.Context, in *Hello, opts ...grpc.CallOption) (*Ack, error)
+type PartialSignature struct {
+	PubKey []byte `protobuf:"bytes,1,opt,name=pubKey,proto3" json:"pubKey,omitempty"`
+	// Signature of the transaction hash
+	Signature            []byte   `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type peeringClient struct {
-	cc *grpc.ClientConn
+func (m *PartialSignature) Reset()         { *m = PartialSignature{} }
+func (m *PartialSignature) String() string { return proto.CompactTextString(m) }
+func (*PartialSignature) ProtoMessage()    {}
+func (*PartialSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{11}
+}
+func (m *PartialSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PartialSignature.Unmarshal(m, b)
+}
+func (m *PartialSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PartialSignature.Marshal(b, m, deterministic)
+}
+func (dst *PartialSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PartialSignature.Merge(dst, src)
+}
+func (m *PartialSignature) XXX_Size() int {
+	return xxx_messageInfo_PartialSignature.Size(m)
+}
+func (m *PartialSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_PartialSignature.DiscardUnknown(m)
 }
 
-func NewPeeringClient(cc *grpc.ClientConn) PeeringClient {
-	return &peeringClient{cc}
+var xxx_messageInfo_PartialSignature proto.InternalMessageInfo
+
+func (m *PartialSignature) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
 }
 
-func (c *peeringClient) Connect(ctx context.Context, in *Hello, opts ...grpc.CallOption) (*Ack, error) {
-	out := new(Ack)
-	err := c.cc.Invoke(ctx, "/protos.Peering/Connect", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *PartialSignature) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
 	}
-	return out, nil
+	return nil
 }
 
-// PeeringServer is the server API for Peering service.
-type PeeringServer interface {
-	// Could add version exchange during peer connection
-	Connect(context.Context, *Hello) (*Ack, error)
+type PartialTransactions struct {
+	Transactions         []*PartialTransaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
-func RegisterPeeringServer(s *grpc.Server, srv PeeringServer) {
-	s.RegisterService(&_Peering_serviceDesc, srv)
+func (m *PartialTransactions) Reset()         { *m = PartialTransactions{} }
+func (m *PartialTransactions) String() string { return proto.CompactTextString(m) }
+func (*PartialTransactions) ProtoMessage()    {}
+func (*PartialTransactions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{12}
+}
+func (m *PartialTransactions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PartialTransactions.Unmarshal(m, b)
+}
+func (m *PartialTransactions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PartialTransactions.Marshal(b, m, deterministic)
+}
+func (dst *PartialTransactions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PartialTransactions.Merge(dst, src)
+}
+func (m *PartialTransactions) XXX_Size() int {
+	return xxx_messageInfo_PartialTransactions.Size(m)
+}
+func (m *PartialTransactions) XXX_DiscardUnknown() {
+	xxx_messageInfo_PartialTransactions.DiscardUnknown(m)
 }
 
-func _Peering_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Hello)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(PeeringServer).Connect(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/protos.Peering/Connect",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PeeringServer).Connect(ctx, req.(*Hello))
+var xxx_messageInfo_PartialTransactions proto.InternalMessageInfo
+
+func (m *PartialTransactions) GetTransactions() []*PartialTransaction {
+	if m != nil {
+		return m.Transactions
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
+}
+
+type PeerList struct {
+	Peers                []string `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeerList) Reset()         { *m = PeerList{} }
+func (m *PeerList) String() string { return proto.CompactTextString(m) }
+func (*PeerList) ProtoMessage()    {}
+func (*PeerList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{13}
+}
+func (m *PeerList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerList.Unmarshal(m, b)
+}
+func (m *PeerList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerList.Marshal(b, m, deterministic)
+}
+func (dst *PeerList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerList.Merge(dst, src)
+}
+func (m *PeerList) XXX_Size() int {
+	return xxx_messageInfo_PeerList.Size(m)
+}
+func (m *PeerList) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerList proto.InternalMessageInfo
+
+func (m *PeerList) GetPeers() []string {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+// Newest first, offset counts back from the tip. A limit of 0 means the
+// default page size
+type Page struct {
+	Offset               uint64   `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit                uint32   `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Page) Reset()         { *m = Page{} }
+func (m *Page) String() string { return proto.CompactTextString(m) }
+func (*Page) ProtoMessage()    {}
+func (*Page) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{14}
+}
+func (m *Page) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Page.Unmarshal(m, b)
+}
+func (m *Page) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Page.Marshal(b, m, deterministic)
+}
+func (dst *Page) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Page.Merge(dst, src)
+}
+func (m *Page) XXX_Size() int {
+	return xxx_messageInfo_Page.Size(m)
+}
+func (m *Page) XXX_DiscardUnknown() {
+	xxx_messageInfo_Page.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Page proto.InternalMessageInfo
+
+func (m *Page) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *Page) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type BlockPage struct {
+	Blocks []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// Blocks in the whole chain
+	Total                uint64   `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockPage) Reset()         { *m = BlockPage{} }
+func (m *BlockPage) String() string { return proto.CompactTextString(m) }
+func (*BlockPage) ProtoMessage()    {}
+func (*BlockPage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{15}
+}
+func (m *BlockPage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockPage.Unmarshal(m, b)
+}
+func (m *BlockPage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockPage.Marshal(b, m, deterministic)
+}
+func (dst *BlockPage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockPage.Merge(dst, src)
+}
+func (m *BlockPage) XXX_Size() int {
+	return xxx_messageInfo_BlockPage.Size(m)
+}
+func (m *BlockPage) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockPage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockPage proto.InternalMessageInfo
+
+func (m *BlockPage) GetBlocks() []*Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *BlockPage) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+// By hash, or by height if the hash is empty
+type BlockQuery struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Height               uint64   `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockQuery) Reset()         { *m = BlockQuery{} }
+func (m *BlockQuery) String() string { return proto.CompactTextString(m) }
+func (*BlockQuery) ProtoMessage()    {}
+func (*BlockQuery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{16}
+}
+func (m *BlockQuery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockQuery.Unmarshal(m, b)
+}
+func (m *BlockQuery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockQuery.Marshal(b, m, deterministic)
+}
+func (dst *BlockQuery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockQuery.Merge(dst, src)
+}
+func (m *BlockQuery) XXX_Size() int {
+	return xxx_messageInfo_BlockQuery.Size(m)
+}
+func (m *BlockQuery) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockQuery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockQuery proto.InternalMessageInfo
+
+func (m *BlockQuery) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *BlockQuery) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type TransactionQuery struct {
+	TxID                 []byte   `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionQuery) Reset()         { *m = TransactionQuery{} }
+func (m *TransactionQuery) String() string { return proto.CompactTextString(m) }
+func (*TransactionQuery) ProtoMessage()    {}
+func (*TransactionQuery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{17}
+}
+func (m *TransactionQuery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionQuery.Unmarshal(m, b)
+}
+func (m *TransactionQuery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionQuery.Marshal(b, m, deterministic)
+}
+func (dst *TransactionQuery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionQuery.Merge(dst, src)
+}
+func (m *TransactionQuery) XXX_Size() int {
+	return xxx_messageInfo_TransactionQuery.Size(m)
+}
+func (m *TransactionQuery) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionQuery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionQuery proto.InternalMessageInfo
+
+func (m *TransactionQuery) GetTxID() []byte {
+	if m != nil {
+		return m.TxID
+	}
+	return nil
+}
+
+// Where a transaction is, blockHash is empty while it's in the mempool
+type TransactionInfo struct {
+	Transaction          *Transaction `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	BlockHash            []byte       `protobuf:"bytes,2,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	Height               uint64       `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Confirmations        uint64       `protobuf:"varint,4,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *TransactionInfo) Reset()         { *m = TransactionInfo{} }
+func (m *TransactionInfo) String() string { return proto.CompactTextString(m) }
+func (*TransactionInfo) ProtoMessage()    {}
+func (*TransactionInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{18}
+}
+func (m *TransactionInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionInfo.Unmarshal(m, b)
+}
+func (m *TransactionInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionInfo.Marshal(b, m, deterministic)
+}
+func (dst *TransactionInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionInfo.Merge(dst, src)
+}
+func (m *TransactionInfo) XXX_Size() int {
+	return xxx_messageInfo_TransactionInfo.Size(m)
+}
+func (m *TransactionInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionInfo proto.InternalMessageInfo
+
+func (m *TransactionInfo) GetTransaction() *Transaction {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *TransactionInfo) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *TransactionInfo) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *TransactionInfo) GetConfirmations() uint64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+type AddressQuery struct {
+	// A key's address, or script: then the hex locking script e.g. a multisig address
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Page                 *Page    `protobuf:"bytes,2,opt,name=page,proto3" json:"page,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddressQuery) Reset()         { *m = AddressQuery{} }
+func (m *AddressQuery) String() string { return proto.CompactTextString(m) }
+func (*AddressQuery) ProtoMessage()    {}
+func (*AddressQuery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{19}
+}
+func (m *AddressQuery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressQuery.Unmarshal(m, b)
+}
+func (m *AddressQuery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressQuery.Marshal(b, m, deterministic)
+}
+func (dst *AddressQuery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressQuery.Merge(dst, src)
+}
+func (m *AddressQuery) XXX_Size() int {
+	return xxx_messageInfo_AddressQuery.Size(m)
+}
+func (m *AddressQuery) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressQuery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressQuery proto.InternalMessageInfo
+
+func (m *AddressQuery) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *AddressQuery) GetPage() *Page {
+	if m != nil {
+		return m.Page
+	}
+	return nil
+}
+
+type UTXO struct {
+	TxID   []byte `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	Index  uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Output *TXO   `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
+	// Block the output confirmed in
+	Height               uint64   `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Coinbase             bool     `protobuf:"varint,5,opt,name=coinbase,proto3" json:"coinbase,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UTXO) Reset()         { *m = UTXO{} }
+func (m *UTXO) String() string { return proto.CompactTextString(m) }
+func (*UTXO) ProtoMessage()    {}
+func (*UTXO) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{20}
+}
+func (m *UTXO) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UTXO.Unmarshal(m, b)
+}
+func (m *UTXO) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UTXO.Marshal(b, m, deterministic)
+}
+func (dst *UTXO) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UTXO.Merge(dst, src)
+}
+func (m *UTXO) XXX_Size() int {
+	return xxx_messageInfo_UTXO.Size(m)
+}
+func (m *UTXO) XXX_DiscardUnknown() {
+	xxx_messageInfo_UTXO.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UTXO proto.InternalMessageInfo
+
+func (m *UTXO) GetTxID() []byte {
+	if m != nil {
+		return m.TxID
+	}
+	return nil
+}
+
+func (m *UTXO) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *UTXO) GetOutput() *TXO {
+	if m != nil {
+		return m.Output
+	}
+	return nil
+}
+
+func (m *UTXO) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *UTXO) GetCoinbase() bool {
+	if m != nil {
+		return m.Coinbase
+	}
+	return false
+}
+
+type UTXOPage struct {
+	Utxos []*UTXO `protobuf:"bytes,1,rep,name=utxos,proto3" json:"utxos,omitempty"`
+	// Unspent outputs of the address
+	Total                uint64   `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Balance              uint64   `protobuf:"varint,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UTXOPage) Reset()         { *m = UTXOPage{} }
+func (m *UTXOPage) String() string { return proto.CompactTextString(m) }
+func (*UTXOPage) ProtoMessage()    {}
+func (*UTXOPage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{21}
+}
+func (m *UTXOPage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UTXOPage.Unmarshal(m, b)
+}
+func (m *UTXOPage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UTXOPage.Marshal(b, m, deterministic)
+}
+func (dst *UTXOPage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UTXOPage.Merge(dst, src)
+}
+func (m *UTXOPage) XXX_Size() int {
+	return xxx_messageInfo_UTXOPage.Size(m)
+}
+func (m *UTXOPage) XXX_DiscardUnknown() {
+	xxx_messageInfo_UTXOPage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UTXOPage proto.InternalMessageInfo
+
+func (m *UTXOPage) GetUtxos() []*UTXO {
+	if m != nil {
+		return m.Utxos
+	}
+	return nil
+}
+
+func (m *UTXOPage) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *UTXOPage) GetBalance() uint64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+// A transaction which paid or spent from an address
+type AddressTransaction struct {
+	TxID []byte `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	// 0 while in the mempool
+	Height        uint64 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	BlockHash     []byte `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	Confirmations uint64 `protobuf:"varint,4,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	// Block time, seconds from epoch
+	Time uint64 `protobuf:"varint,5,opt,name=time,proto3" json:"time,omitempty"`
+	// Paid to the address and spent from it by this transaction
+	Received             uint64   `protobuf:"varint,6,opt,name=received,proto3" json:"received,omitempty"`
+	Sent                 uint64   `protobuf:"varint,7,opt,name=sent,proto3" json:"sent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddressTransaction) Reset()         { *m = AddressTransaction{} }
+func (m *AddressTransaction) String() string { return proto.CompactTextString(m) }
+func (*AddressTransaction) ProtoMessage()    {}
+func (*AddressTransaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{22}
+}
+func (m *AddressTransaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressTransaction.Unmarshal(m, b)
+}
+func (m *AddressTransaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressTransaction.Marshal(b, m, deterministic)
+}
+func (dst *AddressTransaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressTransaction.Merge(dst, src)
+}
+func (m *AddressTransaction) XXX_Size() int {
+	return xxx_messageInfo_AddressTransaction.Size(m)
+}
+func (m *AddressTransaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressTransaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressTransaction proto.InternalMessageInfo
+
+func (m *AddressTransaction) GetTxID() []byte {
+	if m != nil {
+		return m.TxID
+	}
+	return nil
+}
+
+func (m *AddressTransaction) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *AddressTransaction) GetConfirmations() uint64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetTime() uint64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetReceived() uint64 {
+	if m != nil {
+		return m.Received
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetSent() uint64 {
+	if m != nil {
+		return m.Sent
+	}
+	return 0
+}
+
+type AddressHistory struct {
+	Transactions []*AddressTransaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Total        uint64                `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Over the whole history, not just this page
+	Received             uint64   `protobuf:"varint,3,opt,name=received,proto3" json:"received,omitempty"`
+	Sent                 uint64   `protobuf:"varint,4,opt,name=sent,proto3" json:"sent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddressHistory) Reset()         { *m = AddressHistory{} }
+func (m *AddressHistory) String() string { return proto.CompactTextString(m) }
+func (*AddressHistory) ProtoMessage()    {}
+func (*AddressHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{23}
+}
+func (m *AddressHistory) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressHistory.Unmarshal(m, b)
+}
+func (m *AddressHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressHistory.Marshal(b, m, deterministic)
+}
+func (dst *AddressHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressHistory.Merge(dst, src)
+}
+func (m *AddressHistory) XXX_Size() int {
+	return xxx_messageInfo_AddressHistory.Size(m)
+}
+func (m *AddressHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressHistory proto.InternalMessageInfo
+
+func (m *AddressHistory) GetTransactions() []*AddressTransaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+func (m *AddressHistory) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *AddressHistory) GetReceived() uint64 {
+	if m != nil {
+		return m.Received
+	}
+	return 0
+}
+
+func (m *AddressHistory) GetSent() uint64 {
+	if m != nil {
+		return m.Sent
+	}
+	return 0
+}
+
+// Of an address's confirmed transactions
+type AddressBalance struct {
+	Balance uint64 `protobuf:"varint,1,opt,name=balance,proto3" json:"balance,omitempty"`
+	// Unspent outputs making up the balance
+	Utxos                uint64   `protobuf:"varint,2,opt,name=utxos,proto3" json:"utxos,omitempty"`
+	Received             uint64   `protobuf:"varint,3,opt,name=received,proto3" json:"received,omitempty"`
+	Sent                 uint64   `protobuf:"varint,4,opt,name=sent,proto3" json:"sent,omitempty"`
+	Transactions         uint64   `protobuf:"varint,5,opt,name=transactions,proto3" json:"transactions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddressBalance) Reset()         { *m = AddressBalance{} }
+func (m *AddressBalance) String() string { return proto.CompactTextString(m) }
+func (*AddressBalance) ProtoMessage()    {}
+func (*AddressBalance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{24}
+}
+func (m *AddressBalance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressBalance.Unmarshal(m, b)
+}
+func (m *AddressBalance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressBalance.Marshal(b, m, deterministic)
+}
+func (dst *AddressBalance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressBalance.Merge(dst, src)
+}
+func (m *AddressBalance) XXX_Size() int {
+	return xxx_messageInfo_AddressBalance.Size(m)
+}
+func (m *AddressBalance) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressBalance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressBalance proto.InternalMessageInfo
+
+func (m *AddressBalance) GetBalance() uint64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+func (m *AddressBalance) GetUtxos() uint64 {
+	if m != nil {
+		return m.Utxos
+	}
+	return 0
+}
+
+func (m *AddressBalance) GetReceived() uint64 {
+	if m != nil {
+		return m.Received
+	}
+	return 0
+}
+
+func (m *AddressBalance) GetSent() uint64 {
+	if m != nil {
+		return m.Sent
+	}
+	return 0
+}
+
+func (m *AddressBalance) GetTransactions() uint64 {
+	if m != nil {
+		return m.Transactions
+	}
+	return 0
+}
+
+type Account struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Account) Reset()         { *m = Account{} }
+func (m *Account) String() string { return proto.CompactTextString(m) }
+func (*Account) ProtoMessage()    {}
+func (*Account) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{25}
+}
+func (m *Account) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Account.Unmarshal(m, b)
+}
+func (m *Account) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Account.Marshal(b, m, deterministic)
+}
+func (dst *Account) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Account.Merge(dst, src)
+}
+func (m *Account) XXX_Size() int {
+	return xxx_messageInfo_Account.Size(m)
+}
+func (m *Account) XXX_DiscardUnknown() {
+	xxx_messageInfo_Account.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Account proto.InternalMessageInfo
+
+func (m *Account) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type AccountCreated struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AccountCreated) Reset()         { *m = AccountCreated{} }
+func (m *AccountCreated) String() string { return proto.CompactTextString(m) }
+func (*AccountCreated) ProtoMessage()    {}
+func (*AccountCreated) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{26}
+}
+func (m *AccountCreated) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AccountCreated.Unmarshal(m, b)
+}
+func (m *AccountCreated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AccountCreated.Marshal(b, m, deterministic)
+}
+func (dst *AccountCreated) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AccountCreated.Merge(dst, src)
+}
+func (m *AccountCreated) XXX_Size() int {
+	return xxx_messageInfo_AccountCreated.Size(m)
+}
+func (m *AccountCreated) XXX_DiscardUnknown() {
+	xxx_messageInfo_AccountCreated.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AccountCreated proto.InternalMessageInfo
+
+func (m *AccountCreated) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type Balance struct {
+	Balance uint64 `protobuf:"varint,1,opt,name=balance,proto3" json:"balance,omitempty"`
+	// Excludes coinbase outputs which haven't matured yet
+	Spendable            uint64   `protobuf:"varint,2,opt,name=spendable,proto3" json:"spendable,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Balance) Reset()         { *m = Balance{} }
+func (m *Balance) String() string { return proto.CompactTextString(m) }
+func (*Balance) ProtoMessage()    {}
+func (*Balance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{27}
+}
+func (m *Balance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Balance.Unmarshal(m, b)
+}
+func (m *Balance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Balance.Marshal(b, m, deterministic)
+}
+func (dst *Balance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Balance.Merge(dst, src)
+}
+func (m *Balance) XXX_Size() int {
+	return xxx_messageInfo_Balance.Size(m)
+}
+func (m *Balance) XXX_DiscardUnknown() {
+	xxx_messageInfo_Balance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Balance proto.InternalMessageInfo
+
+func (m *Balance) GetBalance() uint64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+func (m *Balance) GetSpendable() uint64 {
+	if m != nil {
+		return m.Spendable
+	}
+	return 0
+}
+
+type MultisigRequest struct {
+	// Signatures needed out of pubKeys
+	Required             uint32   `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"`
+	PubKeys              [][]byte `protobuf:"bytes,2,rep,name=pubKeys,proto3" json:"pubKeys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MultisigRequest) Reset()         { *m = MultisigRequest{} }
+func (m *MultisigRequest) String() string { return proto.CompactTextString(m) }
+func (*MultisigRequest) ProtoMessage()    {}
+func (*MultisigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{28}
+}
+func (m *MultisigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultisigRequest.Unmarshal(m, b)
+}
+func (m *MultisigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultisigRequest.Marshal(b, m, deterministic)
+}
+func (dst *MultisigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultisigRequest.Merge(dst, src)
+}
+func (m *MultisigRequest) XXX_Size() int {
+	return xxx_messageInfo_MultisigRequest.Size(m)
+}
+func (m *MultisigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultisigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultisigRequest proto.InternalMessageInfo
+
+func (m *MultisigRequest) GetRequired() uint32 {
+	if m != nil {
+		return m.Required
+	}
+	return 0
+}
+
+func (m *MultisigRequest) GetPubKeys() [][]byte {
+	if m != nil {
+		return m.PubKeys
+	}
+	return nil
+}
+
+type MultisigAddress struct {
+	// Hex of the locking script, pay to it with send -script
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	LockingScript        []byte   `protobuf:"bytes,2,opt,name=lockingScript,proto3" json:"lockingScript,omitempty"`
+	Required             uint32   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	PubKeys              [][]byte `protobuf:"bytes,4,rep,name=pubKeys,proto3" json:"pubKeys,omitempty"`
+	Balance              uint64   `protobuf:"varint,5,opt,name=balance,proto3" json:"balance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MultisigAddress) Reset()         { *m = MultisigAddress{} }
+func (m *MultisigAddress) String() string { return proto.CompactTextString(m) }
+func (*MultisigAddress) ProtoMessage()    {}
+func (*MultisigAddress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{29}
+}
+func (m *MultisigAddress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultisigAddress.Unmarshal(m, b)
+}
+func (m *MultisigAddress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultisigAddress.Marshal(b, m, deterministic)
+}
+func (dst *MultisigAddress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultisigAddress.Merge(dst, src)
+}
+func (m *MultisigAddress) XXX_Size() int {
+	return xxx_messageInfo_MultisigAddress.Size(m)
+}
+func (m *MultisigAddress) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultisigAddress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultisigAddress proto.InternalMessageInfo
+
+func (m *MultisigAddress) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *MultisigAddress) GetLockingScript() []byte {
+	if m != nil {
+		return m.LockingScript
+	}
+	return nil
+}
+
+func (m *MultisigAddress) GetRequired() uint32 {
+	if m != nil {
+		return m.Required
+	}
+	return 0
+}
+
+func (m *MultisigAddress) GetPubKeys() [][]byte {
+	if m != nil {
+		return m.PubKeys
+	}
+	return nil
+}
+
+func (m *MultisigAddress) GetBalance() uint64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+// Hash time locked contract, pays the recipient if they reveal the
+// preimage of hash, or the refunder after lockTime. Used for atomic
+// swaps between chains
+type HTLCRequest struct {
+	RecipientPubKey []byte `protobuf:"bytes,1,opt,name=recipientPubKey,proto3" json:"recipientPubKey,omitempty"`
+	Value           uint64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	// SHA256 of the 32 byte secret
+	Hash []byte `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Blocks from now until the refunder can take the coin back
+	LockBlocks           uint64   `protobuf:"varint,4,opt,name=lockBlocks,proto3" json:"lockBlocks,omitempty"`
+	FeeRate              uint64   `protobuf:"varint,5,opt,name=feeRate,proto3" json:"feeRate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLCRequest) Reset()         { *m = HTLCRequest{} }
+func (m *HTLCRequest) String() string { return proto.CompactTextString(m) }
+func (*HTLCRequest) ProtoMessage()    {}
+func (*HTLCRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{30}
+}
+func (m *HTLCRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLCRequest.Unmarshal(m, b)
+}
+func (m *HTLCRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLCRequest.Marshal(b, m, deterministic)
+}
+func (dst *HTLCRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLCRequest.Merge(dst, src)
+}
+func (m *HTLCRequest) XXX_Size() int {
+	return xxx_messageInfo_HTLCRequest.Size(m)
+}
+func (m *HTLCRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLCRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLCRequest proto.InternalMessageInfo
+
+func (m *HTLCRequest) GetRecipientPubKey() []byte {
+	if m != nil {
+		return m.RecipientPubKey
+	}
+	return nil
+}
+
+func (m *HTLCRequest) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *HTLCRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *HTLCRequest) GetLockBlocks() uint64 {
+	if m != nil {
+		return m.LockBlocks
+	}
+	return 0
+}
+
+func (m *HTLCRequest) GetFeeRate() uint64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+type HTLC struct {
+	// Output holding the contract
+	TxID            []byte `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	Index           uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	LockingScript   []byte `protobuf:"bytes,3,opt,name=lockingScript,proto3" json:"lockingScript,omitempty"`
+	Hash            []byte `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`
+	RecipientPubKey []byte `protobuf:"bytes,5,opt,name=recipientPubKey,proto3" json:"recipientPubKey,omitempty"`
+	RefundPubKey    []byte `protobuf:"bytes,6,opt,name=refundPubKey,proto3" json:"refundPubKey,omitempty"`
+	// Height the refund is possible after
+	LockTime uint64 `protobuf:"varint,7,opt,name=lockTime,proto3" json:"lockTime,omitempty"`
+	Value    uint64 `protobuf:"varint,8,opt,name=value,proto3" json:"value,omitempty"`
+	// Transaction which spent the contract, if any
+	SpentBy []byte `protobuf:"bytes,9,opt,name=spentBy,proto3" json:"spentBy,omitempty"`
+	// Secret revealed by a redeem, if any
+	Preimage             []byte   `protobuf:"bytes,10,opt,name=preimage,proto3" json:"preimage,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLC) Reset()         { *m = HTLC{} }
+func (m *HTLC) String() string { return proto.CompactTextString(m) }
+func (*HTLC) ProtoMessage()    {}
+func (*HTLC) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{31}
+}
+func (m *HTLC) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLC.Unmarshal(m, b)
+}
+func (m *HTLC) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLC.Marshal(b, m, deterministic)
+}
+func (dst *HTLC) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLC.Merge(dst, src)
+}
+func (m *HTLC) XXX_Size() int {
+	return xxx_messageInfo_HTLC.Size(m)
+}
+func (m *HTLC) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLC.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLC proto.InternalMessageInfo
+
+func (m *HTLC) GetTxID() []byte {
+	if m != nil {
+		return m.TxID
+	}
+	return nil
+}
+
+func (m *HTLC) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *HTLC) GetLockingScript() []byte {
+	if m != nil {
+		return m.LockingScript
+	}
+	return nil
+}
+
+func (m *HTLC) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *HTLC) GetRecipientPubKey() []byte {
+	if m != nil {
+		return m.RecipientPubKey
+	}
+	return nil
+}
+
+func (m *HTLC) GetRefundPubKey() []byte {
+	if m != nil {
+		return m.RefundPubKey
+	}
+	return nil
+}
+
+func (m *HTLC) GetLockTime() uint64 {
+	if m != nil {
+		return m.LockTime
+	}
+	return 0
+}
+
+func (m *HTLC) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *HTLC) GetSpentBy() []byte {
+	if m != nil {
+		return m.SpentBy
+	}
+	return nil
+}
+
+func (m *HTLC) GetPreimage() []byte {
+	if m != nil {
+		return m.Preimage
+	}
+	return nil
+}
+
+type HTLCSpend struct {
+	TxID  []byte `protobuf:"bytes,1,opt,name=txID,proto3" json:"txID,omitempty"`
+	Index uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Secret, only for redeeming
+	Preimage             []byte   `protobuf:"bytes,3,opt,name=preimage,proto3" json:"preimage,omitempty"`
+	Fee                  uint64   `protobuf:"varint,4,opt,name=fee,proto3" json:"fee,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLCSpend) Reset()         { *m = HTLCSpend{} }
+func (m *HTLCSpend) String() string { return proto.CompactTextString(m) }
+func (*HTLCSpend) ProtoMessage()    {}
+func (*HTLCSpend) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{32}
+}
+func (m *HTLCSpend) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLCSpend.Unmarshal(m, b)
+}
+func (m *HTLCSpend) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLCSpend.Marshal(b, m, deterministic)
+}
+func (dst *HTLCSpend) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLCSpend.Merge(dst, src)
+}
+func (m *HTLCSpend) XXX_Size() int {
+	return xxx_messageInfo_HTLCSpend.Size(m)
+}
+func (m *HTLCSpend) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLCSpend.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLCSpend proto.InternalMessageInfo
+
+func (m *HTLCSpend) GetTxID() []byte {
+	if m != nil {
+		return m.TxID
+	}
+	return nil
+}
+
+func (m *HTLCSpend) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *HTLCSpend) GetPreimage() []byte {
+	if m != nil {
+		return m.Preimage
+	}
+	return nil
+}
+
+func (m *HTLCSpend) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+type GenerateRequest struct {
+	Blocks uint32 `protobuf:"varint,1,opt,name=blocks,proto3" json:"blocks,omitempty"`
+	// Pays the node's wallet if empty
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GenerateRequest) Reset()         { *m = GenerateRequest{} }
+func (m *GenerateRequest) String() string { return proto.CompactTextString(m) }
+func (*GenerateRequest) ProtoMessage()    {}
+func (*GenerateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{33}
+}
+func (m *GenerateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GenerateRequest.Unmarshal(m, b)
+}
+func (m *GenerateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GenerateRequest.Marshal(b, m, deterministic)
+}
+func (dst *GenerateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GenerateRequest.Merge(dst, src)
+}
+func (m *GenerateRequest) XXX_Size() int {
+	return xxx_messageInfo_GenerateRequest.Size(m)
+}
+func (m *GenerateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GenerateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GenerateRequest proto.InternalMessageInfo
+
+func (m *GenerateRequest) GetBlocks() uint32 {
+	if m != nil {
+		return m.Blocks
+	}
+	return 0
+}
+
+func (m *GenerateRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type GeneratedBlocks struct {
+	Hashes               [][]byte `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GeneratedBlocks) Reset()         { *m = GeneratedBlocks{} }
+func (m *GeneratedBlocks) String() string { return proto.CompactTextString(m) }
+func (*GeneratedBlocks) ProtoMessage()    {}
+func (*GeneratedBlocks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{34}
+}
+func (m *GeneratedBlocks) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GeneratedBlocks.Unmarshal(m, b)
+}
+func (m *GeneratedBlocks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GeneratedBlocks.Marshal(b, m, deterministic)
+}
+func (dst *GeneratedBlocks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GeneratedBlocks.Merge(dst, src)
+}
+func (m *GeneratedBlocks) XXX_Size() int {
+	return xxx_messageInfo_GeneratedBlocks.Size(m)
+}
+func (m *GeneratedBlocks) XXX_DiscardUnknown() {
+	xxx_messageInfo_GeneratedBlocks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GeneratedBlocks proto.InternalMessageInfo
+
+func (m *GeneratedBlocks) GetHashes() [][]byte {
+	if m != nil {
+		return m.Hashes
+	}
+	return nil
+}
+
+type MiningStatus struct {
+	// stopped, running, stopping or generating
+	State string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	// Since mining last started
+	BlocksMined          uint64   `protobuf:"varint,2,opt,name=blocksMined,proto3" json:"blocksMined,omitempty"`
+	StartTime            uint64   `protobuf:"varint,3,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	Height               uint64   `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Tip                  []byte   `protobuf:"bytes,5,opt,name=tip,proto3" json:"tip,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MiningStatus) Reset()         { *m = MiningStatus{} }
+func (m *MiningStatus) String() string { return proto.CompactTextString(m) }
+func (*MiningStatus) ProtoMessage()    {}
+func (*MiningStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{35}
+}
+func (m *MiningStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MiningStatus.Unmarshal(m, b)
+}
+func (m *MiningStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MiningStatus.Marshal(b, m, deterministic)
+}
+func (dst *MiningStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MiningStatus.Merge(dst, src)
+}
+func (m *MiningStatus) XXX_Size() int {
+	return xxx_messageInfo_MiningStatus.Size(m)
+}
+func (m *MiningStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_MiningStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MiningStatus proto.InternalMessageInfo
+
+func (m *MiningStatus) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *MiningStatus) GetBlocksMined() uint64 {
+	if m != nil {
+		return m.BlocksMined
+	}
+	return 0
+}
+
+func (m *MiningStatus) GetStartTime() uint64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *MiningStatus) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *MiningStatus) GetTip() []byte {
+	if m != nil {
+		return m.Tip
+	}
+	return nil
+}
+
+// Faults injected into the messages a node sends a peer, for testing
+type LinkFaults struct {
+	// As in the node's peer list, empty for every peer without its own
+	Peer string `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	// Milliseconds
+	Latency uint64 `protobuf:"varint,2,opt,name=latency,proto3" json:"latency,omitempty"`
+	// Random extra latency up to this
+	Jitter uint64 `protobuf:"varint,3,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	// Chance of each message being dropped
+	Loss float64 `protobuf:"fixed64,4,opt,name=loss,proto3" json:"loss,omitempty"`
+	// Chance of a relayed block or transaction being overtaken by later ones
+	Reorder float64 `protobuf:"fixed64,5,opt,name=reorder,proto3" json:"reorder,omitempty"`
+	// Partitioned, nothing gets through
+	Down                 bool     `protobuf:"varint,6,opt,name=down,proto3" json:"down,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LinkFaults) Reset()         { *m = LinkFaults{} }
+func (m *LinkFaults) String() string { return proto.CompactTextString(m) }
+func (*LinkFaults) ProtoMessage()    {}
+func (*LinkFaults) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{36}
+}
+func (m *LinkFaults) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LinkFaults.Unmarshal(m, b)
+}
+func (m *LinkFaults) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LinkFaults.Marshal(b, m, deterministic)
+}
+func (dst *LinkFaults) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LinkFaults.Merge(dst, src)
+}
+func (m *LinkFaults) XXX_Size() int {
+	return xxx_messageInfo_LinkFaults.Size(m)
+}
+func (m *LinkFaults) XXX_DiscardUnknown() {
+	xxx_messageInfo_LinkFaults.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LinkFaults proto.InternalMessageInfo
+
+func (m *LinkFaults) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *LinkFaults) GetLatency() uint64 {
+	if m != nil {
+		return m.Latency
+	}
+	return 0
+}
+
+func (m *LinkFaults) GetJitter() uint64 {
+	if m != nil {
+		return m.Jitter
+	}
+	return 0
+}
+
+func (m *LinkFaults) GetLoss() float64 {
+	if m != nil {
+		return m.Loss
+	}
+	return 0
+}
+
+func (m *LinkFaults) GetReorder() float64 {
+	if m != nil {
+		return m.Reorder
+	}
+	return 0
+}
+
+func (m *LinkFaults) GetDown() bool {
+	if m != nil {
+		return m.Down
+	}
+	return false
+}
+
+type FaultsList struct {
+	Links                []*LinkFaults `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *FaultsList) Reset()         { *m = FaultsList{} }
+func (m *FaultsList) String() string { return proto.CompactTextString(m) }
+func (*FaultsList) ProtoMessage()    {}
+func (*FaultsList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{37}
+}
+func (m *FaultsList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FaultsList.Unmarshal(m, b)
+}
+func (m *FaultsList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FaultsList.Marshal(b, m, deterministic)
+}
+func (dst *FaultsList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FaultsList.Merge(dst, src)
+}
+func (m *FaultsList) XXX_Size() int {
+	return xxx_messageInfo_FaultsList.Size(m)
+}
+func (m *FaultsList) XXX_DiscardUnknown() {
+	xxx_messageInfo_FaultsList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FaultsList proto.InternalMessageInfo
+
+func (m *FaultsList) GetLinks() []*LinkFaults {
+	if m != nil {
+		return m.Links
+	}
+	return nil
+}
+
+type LogLevel struct {
+	// e.g. chain or p2p, empty for every subsystem
+	Subsystem string `protobuf:"bytes,1,opt,name=subsystem,proto3" json:"subsystem,omitempty"`
+	// debug, info, warn or error
+	Level                string   `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogLevel) Reset()         { *m = LogLevel{} }
+func (m *LogLevel) String() string { return proto.CompactTextString(m) }
+func (*LogLevel) ProtoMessage()    {}
+func (*LogLevel) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{38}
+}
+func (m *LogLevel) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLevel.Unmarshal(m, b)
+}
+func (m *LogLevel) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLevel.Marshal(b, m, deterministic)
+}
+func (dst *LogLevel) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLevel.Merge(dst, src)
+}
+func (m *LogLevel) XXX_Size() int {
+	return xxx_messageInfo_LogLevel.Size(m)
+}
+func (m *LogLevel) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLevel.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLevel proto.InternalMessageInfo
+
+func (m *LogLevel) GetSubsystem() string {
+	if m != nil {
+		return m.Subsystem
+	}
+	return ""
+}
+
+func (m *LogLevel) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type LogLevels struct {
+	Levels               []*LogLevel `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *LogLevels) Reset()         { *m = LogLevels{} }
+func (m *LogLevels) String() string { return proto.CompactTextString(m) }
+func (*LogLevels) ProtoMessage()    {}
+func (*LogLevels) Descriptor() ([]byte, []int) {
+	return fileDescriptor_coin_4577121ffd2361e8, []int{39}
+}
+func (m *LogLevels) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLevels.Unmarshal(m, b)
+}
+func (m *LogLevels) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLevels.Marshal(b, m, deterministic)
+}
+func (dst *LogLevels) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLevels.Merge(dst, src)
+}
+func (m *LogLevels) XXX_Size() int {
+	return xxx_messageInfo_LogLevels.Size(m)
+}
+func (m *LogLevels) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLevels.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLevels proto.InternalMessageInfo
+
+func (m *LogLevels) GetLevels() []*LogLevel {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*TXI)(nil), "protos.TXI")
+	proto.RegisterType((*TXO)(nil), "protos.TXO")
+	proto.RegisterType((*Transaction)(nil), "protos.Transaction")
+	proto.RegisterType((*BlockHeader)(nil), "protos.BlockHeader")
+	proto.RegisterType((*Block)(nil), "protos.Block")
+	proto.RegisterType((*Empty)(nil), "protos.Empty")
+	proto.RegisterType((*Hello)(nil), "protos.Hello")
+	proto.RegisterType((*Ack)(nil), "protos.Ack")
+	proto.RegisterType((*TransactionRequest)(nil), "protos.TransactionRequest")
+	proto.RegisterType((*TransactionSent)(nil), "protos.TransactionSent")
+	proto.RegisterType((*PartialTransaction)(nil), "protos.PartialTransaction")
+	proto.RegisterType((*PartialSignature)(nil), "protos.PartialSignature")
+	proto.RegisterType((*PartialTransactions)(nil), "protos.PartialTransactions")
+	proto.RegisterType((*PeerList)(nil), "protos.PeerList")
+	proto.RegisterType((*Page)(nil), "protos.Page")
+	proto.RegisterType((*BlockPage)(nil), "protos.BlockPage")
+	proto.RegisterType((*BlockQuery)(nil), "protos.BlockQuery")
+	proto.RegisterType((*TransactionQuery)(nil), "protos.TransactionQuery")
+	proto.RegisterType((*TransactionInfo)(nil), "protos.TransactionInfo")
+	proto.RegisterType((*AddressQuery)(nil), "protos.AddressQuery")
+	proto.RegisterType((*UTXO)(nil), "protos.UTXO")
+	proto.RegisterType((*UTXOPage)(nil), "protos.UTXOPage")
+	proto.RegisterType((*AddressTransaction)(nil), "protos.AddressTransaction")
+	proto.RegisterType((*AddressHistory)(nil), "protos.AddressHistory")
+	proto.RegisterType((*AddressBalance)(nil), "protos.AddressBalance")
+	proto.RegisterType((*Account)(nil), "protos.Account")
+	proto.RegisterType((*AccountCreated)(nil), "protos.AccountCreated")
+	proto.RegisterType((*Balance)(nil), "protos.Balance")
+	proto.RegisterType((*MultisigRequest)(nil), "protos.MultisigRequest")
+	proto.RegisterType((*MultisigAddress)(nil), "protos.MultisigAddress")
+	proto.RegisterType((*HTLCRequest)(nil), "protos.HTLCRequest")
+	proto.RegisterType((*HTLC)(nil), "protos.HTLC")
+	proto.RegisterType((*HTLCSpend)(nil), "protos.HTLCSpend")
+	proto.RegisterType((*GenerateRequest)(nil), "protos.GenerateRequest")
+	proto.RegisterType((*GeneratedBlocks)(nil), "protos.GeneratedBlocks")
+	proto.RegisterType((*MiningStatus)(nil), "protos.MiningStatus")
+	proto.RegisterType((*LinkFaults)(nil), "protos.LinkFaults")
+	proto.RegisterType((*FaultsList)(nil), "protos.FaultsList")
+	proto.RegisterType((*LogLevel)(nil), "protos.LogLevel")
+	proto.RegisterType((*LogLevels)(nil), "protos.LogLevels")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// PeeringClient is the client API for Peering service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PeeringClient interface {
+	// Could add version exchange during peer connection
+	Connect(ctx context.Context, in *Hello, opts ...grpc.CallOption) (*Ack, error)
+	// Who we are connected to
+	GetPeers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeerList, error)
+}
+
+type peeringClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewPeeringClient(cc *grpc.ClientConn) PeeringClient {
+	return &peeringClient{cc}
+}
+
+func (c *peeringClient) Connect(ctx context.Context, in *Hello, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, "/protos.Peering/Connect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peeringClient) GetPeers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeerList, error) {
+	out := new(PeerList)
+	err := c.cc.Invoke(ctx, "/protos.Peering/GetPeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PeeringServer is the server API for Peering service.
+type PeeringServer interface {
+	// Could add version exchange during peer connection
+	Connect(context.Context, *Hello) (*Ack, error)
+	// Who we are connected to
+	GetPeers(context.Context, *Empty) (*PeerList, error)
+}
+
+func RegisterPeeringServer(s *grpc.Server, srv PeeringServer) {
+	s.RegisterService(&_Peering_serviceDesc, srv)
+}
+
+func _Peering_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Hello)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeeringServer).Connect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Peering/Connect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeeringServer).Connect(ctx, req.(*Hello))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Peering_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeeringServer).GetPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Peering/GetPeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeeringServer).GetPeers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
 var _Peering_serviceDesc = grpc.ServiceDesc{
@@ -641,231 +2505,785 @@ var _Peering_serviceDesc = grpc.ServiceDesc{
 	HandlerType: (*PeeringServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Connect",
-			Handler:    _Peering_Connect_Handler,
+			MethodName: "Connect",
+			Handler:    _Peering_Connect_Handler,
+		},
+		{
+			MethodName: "GetPeers",
+			Handler:    _Peering_GetPeers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "coin.proto",
+}
+
+// TransactionsClient is the client API for Transactions service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TransactionsClient interface {
+	ReceiveTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Empty, error)
+	SendTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionSent, error)
+	// Build a transaction without signing it
+	CreateTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*PartialTransaction, error)
+	// Add a signature from this node's wallet key
+	SignTransaction(ctx context.Context, in *PartialTransaction, opts ...grpc.CallOption) (*PartialTransaction, error)
+	// Merge signatures collected separately for the same transaction
+	CombineTransactions(ctx context.Context, in *PartialTransactions, opts ...grpc.CallOption) (*PartialTransaction, error)
+	// Check every input is signed then broadcast
+	FinalizeTransaction(ctx context.Context, in *PartialTransaction, opts ...grpc.CallOption) (*TransactionSent, error)
+	// Submit a transaction signed elsewhere, checked like one from a peer
+	// then relayed to every peer
+	BroadcastTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*TransactionSent, error)
+}
+
+type transactionsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTransactionsClient(cc *grpc.ClientConn) TransactionsClient {
+	return &transactionsClient{cc}
+}
+
+func (c *transactionsClient) ReceiveTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/ReceiveTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionsClient) SendTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionSent, error) {
+	out := new(TransactionSent)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/SendTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionsClient) CreateTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*PartialTransaction, error) {
+	out := new(PartialTransaction)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/CreateTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionsClient) SignTransaction(ctx context.Context, in *PartialTransaction, opts ...grpc.CallOption) (*PartialTransaction, error) {
+	out := new(PartialTransaction)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/SignTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionsClient) CombineTransactions(ctx context.Context, in *PartialTransactions, opts ...grpc.CallOption) (*PartialTransaction, error) {
+	out := new(PartialTransaction)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/CombineTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionsClient) FinalizeTransaction(ctx context.Context, in *PartialTransaction, opts ...grpc.CallOption) (*TransactionSent, error) {
+	out := new(TransactionSent)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/FinalizeTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionsClient) BroadcastTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*TransactionSent, error) {
+	out := new(TransactionSent)
+	err := c.cc.Invoke(ctx, "/protos.Transactions/BroadcastTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransactionsServer is the server API for Transactions service.
+type TransactionsServer interface {
+	ReceiveTransaction(context.Context, *Transaction) (*Empty, error)
+	SendTransaction(context.Context, *TransactionRequest) (*TransactionSent, error)
+	// Build a transaction without signing it
+	CreateTransaction(context.Context, *TransactionRequest) (*PartialTransaction, error)
+	// Add a signature from this node's wallet key
+	SignTransaction(context.Context, *PartialTransaction) (*PartialTransaction, error)
+	// Merge signatures collected separately for the same transaction
+	CombineTransactions(context.Context, *PartialTransactions) (*PartialTransaction, error)
+	// Check every input is signed then broadcast
+	FinalizeTransaction(context.Context, *PartialTransaction) (*TransactionSent, error)
+	// Submit a transaction signed elsewhere, checked like one from a peer
+	// then relayed to every peer
+	BroadcastTransaction(context.Context, *Transaction) (*TransactionSent, error)
+}
+
+func RegisterTransactionsServer(s *grpc.Server, srv TransactionsServer) {
+	s.RegisterService(&_Transactions_serviceDesc, srv)
+}
+
+func _Transactions_ReceiveTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Transaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).ReceiveTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/ReceiveTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).ReceiveTransaction(ctx, req.(*Transaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactions_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).SendTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/SendTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).SendTransaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactions_CreateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).CreateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/CreateTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).CreateTransaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactions_SignTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PartialTransaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).SignTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/SignTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).SignTransaction(ctx, req.(*PartialTransaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactions_CombineTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PartialTransactions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).CombineTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/CombineTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).CombineTransactions(ctx, req.(*PartialTransactions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactions_FinalizeTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PartialTransaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).FinalizeTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/FinalizeTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).FinalizeTransaction(ctx, req.(*PartialTransaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactions_BroadcastTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Transaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionsServer).BroadcastTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Transactions/BroadcastTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionsServer).BroadcastTransaction(ctx, req.(*Transaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Transactions_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Transactions",
+	HandlerType: (*TransactionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReceiveTransaction",
+			Handler:    _Transactions_ReceiveTransaction_Handler,
+		},
+		{
+			MethodName: "SendTransaction",
+			Handler:    _Transactions_SendTransaction_Handler,
+		},
+		{
+			MethodName: "CreateTransaction",
+			Handler:    _Transactions_CreateTransaction_Handler,
+		},
+		{
+			MethodName: "SignTransaction",
+			Handler:    _Transactions_SignTransaction_Handler,
+		},
+		{
+			MethodName: "CombineTransactions",
+			Handler:    _Transactions_CombineTransactions_Handler,
+		},
+		{
+			MethodName: "FinalizeTransaction",
+			Handler:    _Transactions_FinalizeTransaction_Handler,
+		},
+		{
+			MethodName: "BroadcastTransaction",
+			Handler:    _Transactions_BroadcastTransaction_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "coin.proto",
+}
+
+// BlocksClient is the client API for Blocks service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type BlocksClient interface {
+	ReceiveBlock(ctx context.Context, in *Block, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type blocksClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBlocksClient(cc *grpc.ClientConn) BlocksClient {
+	return &blocksClient{cc}
+}
+
+func (c *blocksClient) ReceiveBlock(ctx context.Context, in *Block, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/protos.Blocks/ReceiveBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BlocksServer is the server API for Blocks service.
+type BlocksServer interface {
+	ReceiveBlock(context.Context, *Block) (*Empty, error)
+}
+
+func RegisterBlocksServer(s *grpc.Server, srv BlocksServer) {
+	s.RegisterService(&_Blocks_serviceDesc, srv)
+}
+
+func _Blocks_ReceiveBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Block)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlocksServer).ReceiveBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Blocks/ReceiveBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlocksServer).ReceiveBlock(ctx, req.(*Block))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Blocks_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Blocks",
+	HandlerType: (*BlocksServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReceiveBlock",
+			Handler:    _Blocks_ReceiveBlock_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "coin.proto",
 }
 
-// TransactionsClient is the client API for Transactions service.
+// StateClient is the client API for State service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type TransactionsClient interface {
-	ReceiveTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Empty, error)
-	SendTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*Empty, error)
+type StateClient interface {
+	// Could be a huge number of blocks and transactions
+	// lets use a stream
+	GetTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetTransactionsClient, error)
+	GetBlocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetBlocksClient, error)
+	// Single lookups and pages of the chain, for the REST gateway
+	GetBlock(ctx context.Context, in *BlockQuery, opts ...grpc.CallOption) (*Block, error)
+	ListBlocks(ctx context.Context, in *Page, opts ...grpc.CallOption) (*BlockPage, error)
+	// Confirmed or in the mempool
+	GetTransactionInfo(ctx context.Context, in *TransactionQuery, opts ...grpc.CallOption) (*TransactionInfo, error)
+	// Only our chain back from the tip. Without block sync a fork two or more
+	// deep only replaces our tip, our blocks below it still count
+	GetAddressUTXOs(ctx context.Context, in *AddressQuery, opts ...grpc.CallOption) (*UTXOPage, error)
+	// Newest first, starting with the mempool
+	GetAddressHistory(ctx context.Context, in *AddressQuery, opts ...grpc.CallOption) (*AddressHistory, error)
+	GetAddressBalance(ctx context.Context, in *AddressQuery, opts ...grpc.CallOption) (*AddressBalance, error)
 }
 
-type transactionsClient struct {
+type stateClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewTransactionsClient(cc *grpc.ClientConn) TransactionsClient {
-	return &transactionsClient{cc}
+func NewStateClient(cc *grpc.ClientConn) StateClient {
+	return &stateClient{cc}
 }
 
-func (c *transactionsClient) ReceiveTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := c.cc.Invoke(ctx, "/protos.Transactions/ReceiveTransaction", in, out, opts...)
+func (c *stateClient) GetTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_State_serviceDesc.Streams[0], "/protos.State/GetTransactions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &stateGetTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type State_GetTransactionsClient interface {
+	Recv() (*Transaction, error)
+	grpc.ClientStream
+}
+
+type stateGetTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *stateGetTransactionsClient) Recv() (*Transaction, error) {
+	m := new(Transaction)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *stateClient) GetBlocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_State_serviceDesc.Streams[1], "/protos.State/GetBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &stateGetBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type State_GetBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type stateGetBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *stateGetBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *stateClient) GetBlock(ctx context.Context, in *BlockQuery, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/protos.State/GetBlock", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *transactionsClient) SendTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := c.cc.Invoke(ctx, "/protos.Transactions/SendTransaction", in, out, opts...)
+func (c *stateClient) ListBlocks(ctx context.Context, in *Page, opts ...grpc.CallOption) (*BlockPage, error) {
+	out := new(BlockPage)
+	err := c.cc.Invoke(ctx, "/protos.State/ListBlocks", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// TransactionsServer is the server API for Transactions service.
-type TransactionsServer interface {
-	ReceiveTransaction(context.Context, *Transaction) (*Empty, error)
-	SendTransaction(context.Context, *TransactionRequest) (*Empty, error)
+func (c *stateClient) GetTransactionInfo(ctx context.Context, in *TransactionQuery, opts ...grpc.CallOption) (*TransactionInfo, error) {
+	out := new(TransactionInfo)
+	err := c.cc.Invoke(ctx, "/protos.State/GetTransactionInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func RegisterTransactionsServer(s *grpc.Server, srv TransactionsServer) {
-	s.RegisterService(&_Transactions_serviceDesc, srv)
+func (c *stateClient) GetAddressUTXOs(ctx context.Context, in *AddressQuery, opts ...grpc.CallOption) (*UTXOPage, error) {
+	out := new(UTXOPage)
+	err := c.cc.Invoke(ctx, "/protos.State/GetAddressUTXOs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func _Transactions_ReceiveTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Transaction)
+func (c *stateClient) GetAddressHistory(ctx context.Context, in *AddressQuery, opts ...grpc.CallOption) (*AddressHistory, error) {
+	out := new(AddressHistory)
+	err := c.cc.Invoke(ctx, "/protos.State/GetAddressHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateClient) GetAddressBalance(ctx context.Context, in *AddressQuery, opts ...grpc.CallOption) (*AddressBalance, error) {
+	out := new(AddressBalance)
+	err := c.cc.Invoke(ctx, "/protos.State/GetAddressBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StateServer is the server API for State service.
+type StateServer interface {
+	// Could be a huge number of blocks and transactions
+	// lets use a stream
+	GetTransactions(*Empty, State_GetTransactionsServer) error
+	GetBlocks(*Empty, State_GetBlocksServer) error
+	// Single lookups and pages of the chain, for the REST gateway
+	GetBlock(context.Context, *BlockQuery) (*Block, error)
+	ListBlocks(context.Context, *Page) (*BlockPage, error)
+	// Confirmed or in the mempool
+	GetTransactionInfo(context.Context, *TransactionQuery) (*TransactionInfo, error)
+	// Only our chain back from the tip. Without block sync a fork two or more
+	// deep only replaces our tip, our blocks below it still count
+	GetAddressUTXOs(context.Context, *AddressQuery) (*UTXOPage, error)
+	// Newest first, starting with the mempool
+	GetAddressHistory(context.Context, *AddressQuery) (*AddressHistory, error)
+	GetAddressBalance(context.Context, *AddressQuery) (*AddressBalance, error)
+}
+
+func RegisterStateServer(s *grpc.Server, srv StateServer) {
+	s.RegisterService(&_State_serviceDesc, srv)
+}
+
+func _State_GetTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StateServer).GetTransactions(m, &stateGetTransactionsServer{stream})
+}
+
+type State_GetTransactionsServer interface {
+	Send(*Transaction) error
+	grpc.ServerStream
+}
+
+type stateGetTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *stateGetTransactionsServer) Send(m *Transaction) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _State_GetBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StateServer).GetBlocks(m, &stateGetBlocksServer{stream})
+}
+
+type State_GetBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type stateGetBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *stateGetBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _State_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockQuery)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TransactionsServer).ReceiveTransaction(ctx, in)
+		return srv.(StateServer).GetBlock(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/protos.Transactions/ReceiveTransaction",
+		FullMethod: "/protos.State/GetBlock",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionsServer).ReceiveTransaction(ctx, req.(*Transaction))
+		return srv.(StateServer).GetBlock(ctx, req.(*BlockQuery))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Transactions_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TransactionRequest)
+func _State_ListBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Page)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TransactionsServer).SendTransaction(ctx, in)
+		return srv.(StateServer).ListBlocks(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/protos.Transactions/SendTransaction",
+		FullMethod: "/protos.State/ListBlocks",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionsServer).SendTransaction(ctx, req.(*TransactionRequest))
+		return srv.(StateServer).ListBlocks(ctx, req.(*Page))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Transactions_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "protos.Transactions",
-	HandlerType: (*TransactionsServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "ReceiveTransaction",
-			Handler:    _Transactions_ReceiveTransaction_Handler,
-		},
-		{
-			MethodName: "SendTransaction",
-			Handler:    _Transactions_SendTransaction_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "coin.proto",
-}
-
-// BlocksClient is the client API for Blocks service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type BlocksClient interface {
-	ReceiveBlock(ctx context.Context, in *Block, opts ...grpc.CallOption) (*Empty, error)
-}
-
-type blocksClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewBlocksClient(cc *grpc.ClientConn) BlocksClient {
-	return &blocksClient{cc}
+func _State_GetTransactionInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServer).GetTransactionInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.State/GetTransactionInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServer).GetTransactionInfo(ctx, req.(*TransactionQuery))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *blocksClient) ReceiveBlock(ctx context.Context, in *Block, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := c.cc.Invoke(ctx, "/protos.Blocks/ReceiveBlock", in, out, opts...)
-	if err != nil {
+func _State_GetAddressUTXOs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressQuery)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-// BlocksServer is the server API for Blocks service.
-type BlocksServer interface {
-	ReceiveBlock(context.Context, *Block) (*Empty, error)
+	if interceptor == nil {
+		return srv.(StateServer).GetAddressUTXOs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.State/GetAddressUTXOs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServer).GetAddressUTXOs(ctx, req.(*AddressQuery))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterBlocksServer(s *grpc.Server, srv BlocksServer) {
-	s.RegisterService(&_Blocks_serviceDesc, srv)
+func _State_GetAddressHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServer).GetAddressHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.State/GetAddressHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServer).GetAddressHistory(ctx, req.(*AddressQuery))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Blocks_ReceiveBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Block)
+func _State_GetAddressBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressQuery)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(BlocksServer).ReceiveBlock(ctx, in)
+		return srv.(StateServer).GetAddressBalance(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/protos.Blocks/ReceiveBlock",
+		FullMethod: "/protos.State/GetAddressBalance",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BlocksServer).ReceiveBlock(ctx, req.(*Block))
+		return srv.(StateServer).GetAddressBalance(ctx, req.(*AddressQuery))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Blocks_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "protos.Blocks",
-	HandlerType: (*BlocksServer)(nil),
+var _State_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.State",
+	HandlerType: (*StateServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "ReceiveBlock",
-			Handler:    _Blocks_ReceiveBlock_Handler,
+			MethodName: "GetBlock",
+			Handler:    _State_GetBlock_Handler,
+		},
+		{
+			MethodName: "ListBlocks",
+			Handler:    _State_ListBlocks_Handler,
+		},
+		{
+			MethodName: "GetTransactionInfo",
+			Handler:    _State_GetTransactionInfo_Handler,
+		},
+		{
+			MethodName: "GetAddressUTXOs",
+			Handler:    _State_GetAddressUTXOs_Handler,
+		},
+		{
+			MethodName: "GetAddressHistory",
+			Handler:    _State_GetAddressHistory_Handler,
+		},
+		{
+			MethodName: "GetAddressBalance",
+			Handler:    _State_GetAddressBalance_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetTransactions",
+			Handler:       _State_GetTransactions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetBlocks",
+			Handler:       _State_GetBlocks_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "coin.proto",
 }
 
-// StateClient is the client API for State service.
+// WalletClient is the client API for Wallet service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type StateClient interface {
-	// Could be a huge number of blocks and transactions
-	// lets use a stream
-	GetTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetTransactionsClient, error)
-	GetBlocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetBlocksClient, error)
+type WalletClient interface {
+	NewAccount(ctx context.Context, in *Account, opts ...grpc.CallOption) (*AccountCreated, error)
+	GetBalance(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Balance, error)
+	GetAddress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AccountCreated, error)
+	// Make an M of N address and watch it for coin
+	CreateMultisig(ctx context.Context, in *MultisigRequest, opts ...grpc.CallOption) (*MultisigAddress, error)
+	GetMultisigAddresses(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Wallet_GetMultisigAddressesClient, error)
+	// Payments to and from the wallet's key, newest first starting with
+	// the mempool
+	GetHistory(ctx context.Context, in *Page, opts ...grpc.CallOption) (*AddressHistory, error)
 }
 
-type stateClient struct {
+type walletClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewStateClient(cc *grpc.ClientConn) StateClient {
-	return &stateClient{cc}
+func NewWalletClient(cc *grpc.ClientConn) WalletClient {
+	return &walletClient{cc}
 }
 
-func (c *stateClient) GetTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetTransactionsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_State_serviceDesc.Streams[0], "/protos.State/GetTransactions", opts...)
+func (c *walletClient) NewAccount(ctx context.Context, in *Account, opts ...grpc.CallOption) (*AccountCreated, error) {
+	out := new(AccountCreated)
+	err := c.cc.Invoke(ctx, "/protos.Wallet/NewAccount", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &stateGetTransactionsClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+	return out, nil
 }
 
-type State_GetTransactionsClient interface {
-	Recv() (*Transaction, error)
-	grpc.ClientStream
+func (c *walletClient) GetBalance(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/protos.Wallet/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type stateGetTransactionsClient struct {
-	grpc.ClientStream
+func (c *walletClient) GetAddress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AccountCreated, error) {
+	out := new(AccountCreated)
+	err := c.cc.Invoke(ctx, "/protos.Wallet/GetAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *stateGetTransactionsClient) Recv() (*Transaction, error) {
-	m := new(Transaction)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *walletClient) CreateMultisig(ctx context.Context, in *MultisigRequest, opts ...grpc.CallOption) (*MultisigAddress, error) {
+	out := new(MultisigAddress)
+	err := c.cc.Invoke(ctx, "/protos.Wallet/CreateMultisig", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *stateClient) GetBlocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (State_GetBlocksClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_State_serviceDesc.Streams[1], "/protos.State/GetBlocks", opts...)
+func (c *walletClient) GetMultisigAddresses(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Wallet_GetMultisigAddressesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Wallet_serviceDesc.Streams[0], "/protos.Wallet/GetMultisigAddresses", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &stateGetBlocksClient{stream}
+	x := &walletGetMultisigAddressesClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -875,220 +3293,360 @@ func (c *stateClient) GetBlocks(ctx context.Context, in *Empty, opts ...grpc.Cal
 	return x, nil
 }
 
-type State_GetBlocksClient interface {
-	Recv() (*Block, error)
+type Wallet_GetMultisigAddressesClient interface {
+	Recv() (*MultisigAddress, error)
 	grpc.ClientStream
 }
 
-type stateGetBlocksClient struct {
+type walletGetMultisigAddressesClient struct {
 	grpc.ClientStream
 }
 
-func (x *stateGetBlocksClient) Recv() (*Block, error) {
-	m := new(Block)
+func (x *walletGetMultisigAddressesClient) Recv() (*MultisigAddress, error) {
+	m := new(MultisigAddress)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-// StateServer is the server API for State service.
-type StateServer interface {
-	// Could be a huge number of blocks and transactions
-	// lets use a stream
-	GetTransactions(*Empty, State_GetTransactionsServer) error
-	GetBlocks(*Empty, State_GetBlocksServer) error
+func (c *walletClient) GetHistory(ctx context.Context, in *Page, opts ...grpc.CallOption) (*AddressHistory, error) {
+	out := new(AddressHistory)
+	err := c.cc.Invoke(ctx, "/protos.Wallet/GetHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func RegisterStateServer(s *grpc.Server, srv StateServer) {
-	s.RegisterService(&_State_serviceDesc, srv)
+// WalletServer is the server API for Wallet service.
+type WalletServer interface {
+	NewAccount(context.Context, *Account) (*AccountCreated, error)
+	GetBalance(context.Context, *Empty) (*Balance, error)
+	GetAddress(context.Context, *Empty) (*AccountCreated, error)
+	// Make an M of N address and watch it for coin
+	CreateMultisig(context.Context, *MultisigRequest) (*MultisigAddress, error)
+	GetMultisigAddresses(*Empty, Wallet_GetMultisigAddressesServer) error
+	// Payments to and from the wallet's key, newest first starting with
+	// the mempool
+	GetHistory(context.Context, *Page) (*AddressHistory, error)
 }
 
-func _State_GetTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func RegisterWalletServer(s *grpc.Server, srv WalletServer) {
+	s.RegisterService(&_Wallet_serviceDesc, srv)
+}
+
+func _Wallet_NewAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Account)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(StateServer).GetTransactions(m, &stateGetTransactionsServer{stream})
+	if interceptor == nil {
+		return srv.(WalletServer).NewAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Wallet/NewAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).NewAccount(ctx, req.(*Account))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type State_GetTransactionsServer interface {
-	Send(*Transaction) error
-	grpc.ServerStream
+func _Wallet_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Wallet/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).GetBalance(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type stateGetTransactionsServer struct {
-	grpc.ServerStream
+func _Wallet_GetAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).GetAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Wallet/GetAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).GetAddress(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *stateGetTransactionsServer) Send(m *Transaction) error {
-	return x.ServerStream.SendMsg(m)
+func _Wallet_CreateMultisig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultisigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).CreateMultisig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Wallet/CreateMultisig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).CreateMultisig(ctx, req.(*MultisigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _State_GetBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+func _Wallet_GetMultisigAddresses_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(Empty)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(StateServer).GetBlocks(m, &stateGetBlocksServer{stream})
+	return srv.(WalletServer).GetMultisigAddresses(m, &walletGetMultisigAddressesServer{stream})
 }
 
-type State_GetBlocksServer interface {
-	Send(*Block) error
+type Wallet_GetMultisigAddressesServer interface {
+	Send(*MultisigAddress) error
 	grpc.ServerStream
 }
 
-type stateGetBlocksServer struct {
+type walletGetMultisigAddressesServer struct {
 	grpc.ServerStream
 }
 
-func (x *stateGetBlocksServer) Send(m *Block) error {
+func (x *walletGetMultisigAddressesServer) Send(m *MultisigAddress) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-var _State_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "protos.State",
-	HandlerType: (*StateServer)(nil),
-	Methods:     []grpc.MethodDesc{},
-	Streams: []grpc.StreamDesc{
+func _Wallet_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Page)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Wallet/GetHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).GetHistory(ctx, req.(*Page))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Wallet_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Wallet",
+	HandlerType: (*WalletServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NewAccount",
+			Handler:    _Wallet_NewAccount_Handler,
+		},
 		{
-			StreamName:    "GetTransactions",
-			Handler:       _State_GetTransactions_Handler,
-			ServerStreams: true,
+			MethodName: "GetBalance",
+			Handler:    _Wallet_GetBalance_Handler,
 		},
 		{
-			StreamName:    "GetBlocks",
-			Handler:       _State_GetBlocks_Handler,
+			MethodName: "GetAddress",
+			Handler:    _Wallet_GetAddress_Handler,
+		},
+		{
+			MethodName: "CreateMultisig",
+			Handler:    _Wallet_CreateMultisig_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _Wallet_GetHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetMultisigAddresses",
+			Handler:       _Wallet_GetMultisigAddresses_Handler,
 			ServerStreams: true,
 		},
 	},
 	Metadata: "coin.proto",
 }
 
-// WalletClient is the client API for Wallet service.
+// SwapsClient is the client API for Swaps service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type WalletClient interface {
-	NewAccount(ctx context.Context, in *Account, opts ...grpc.CallOption) (*AccountCreated, error)
-	GetBalance(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Balance, error)
-	GetAddress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AccountCreated, error)
+type SwapsClient interface {
+	// Lock coin from our wallet in a new contract
+	CreateHTLC(ctx context.Context, in *HTLCRequest, opts ...grpc.CallOption) (*HTLC, error)
+	// Claim the coin as recipient by revealing the secret
+	RedeemHTLC(ctx context.Context, in *HTLCSpend, opts ...grpc.CallOption) (*TransactionSent, error)
+	// Take the coin back as refunder once the lock time has passed
+	RefundHTLC(ctx context.Context, in *HTLCSpend, opts ...grpc.CallOption) (*TransactionSent, error)
+	// Look up a contract, including the secret if it has been redeemed
+	GetHTLC(ctx context.Context, in *HTLCSpend, opts ...grpc.CallOption) (*HTLC, error)
+}
+
+type swapsClient struct {
+	cc *grpc.ClientConn
 }
 
-type walletClient struct {
-	cc *grpc.ClientConn
+func NewSwapsClient(cc *grpc.ClientConn) SwapsClient {
+	return &swapsClient{cc}
 }
 
-func NewWalletClient(cc *grpc.ClientConn) WalletClient {
-	return &walletClient{cc}
+func (c *swapsClient) CreateHTLC(ctx context.Context, in *HTLCRequest, opts ...grpc.CallOption) (*HTLC, error) {
+	out := new(HTLC)
+	err := c.cc.Invoke(ctx, "/protos.Swaps/CreateHTLC", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *walletClient) NewAccount(ctx context.Context, in *Account, opts ...grpc.CallOption) (*AccountCreated, error) {
-	out := new(AccountCreated)
-	err := c.cc.Invoke(ctx, "/protos.Wallet/NewAccount", in, out, opts...)
+func (c *swapsClient) RedeemHTLC(ctx context.Context, in *HTLCSpend, opts ...grpc.CallOption) (*TransactionSent, error) {
+	out := new(TransactionSent)
+	err := c.cc.Invoke(ctx, "/protos.Swaps/RedeemHTLC", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *walletClient) GetBalance(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Balance, error) {
-	out := new(Balance)
-	err := c.cc.Invoke(ctx, "/protos.Wallet/GetBalance", in, out, opts...)
+func (c *swapsClient) RefundHTLC(ctx context.Context, in *HTLCSpend, opts ...grpc.CallOption) (*TransactionSent, error) {
+	out := new(TransactionSent)
+	err := c.cc.Invoke(ctx, "/protos.Swaps/RefundHTLC", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *walletClient) GetAddress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AccountCreated, error) {
-	out := new(AccountCreated)
-	err := c.cc.Invoke(ctx, "/protos.Wallet/GetAddress", in, out, opts...)
+func (c *swapsClient) GetHTLC(ctx context.Context, in *HTLCSpend, opts ...grpc.CallOption) (*HTLC, error) {
+	out := new(HTLC)
+	err := c.cc.Invoke(ctx, "/protos.Swaps/GetHTLC", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// WalletServer is the server API for Wallet service.
-type WalletServer interface {
-	NewAccount(context.Context, *Account) (*AccountCreated, error)
-	GetBalance(context.Context, *Empty) (*Balance, error)
-	GetAddress(context.Context, *Empty) (*AccountCreated, error)
+// SwapsServer is the server API for Swaps service.
+type SwapsServer interface {
+	// Lock coin from our wallet in a new contract
+	CreateHTLC(context.Context, *HTLCRequest) (*HTLC, error)
+	// Claim the coin as recipient by revealing the secret
+	RedeemHTLC(context.Context, *HTLCSpend) (*TransactionSent, error)
+	// Take the coin back as refunder once the lock time has passed
+	RefundHTLC(context.Context, *HTLCSpend) (*TransactionSent, error)
+	// Look up a contract, including the secret if it has been redeemed
+	GetHTLC(context.Context, *HTLCSpend) (*HTLC, error)
 }
 
-func RegisterWalletServer(s *grpc.Server, srv WalletServer) {
-	s.RegisterService(&_Wallet_serviceDesc, srv)
+func RegisterSwapsServer(s *grpc.Server, srv SwapsServer) {
+	s.RegisterService(&_Swaps_serviceDesc, srv)
 }
 
-func _Wallet_NewAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Account)
+func _Swaps_CreateHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HTLCRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServer).NewAccount(ctx, in)
+		return srv.(SwapsServer).CreateHTLC(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/protos.Wallet/NewAccount",
+		FullMethod: "/protos.Swaps/CreateHTLC",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServer).NewAccount(ctx, req.(*Account))
+		return srv.(SwapsServer).CreateHTLC(ctx, req.(*HTLCRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Wallet_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+func _Swaps_RedeemHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HTLCSpend)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServer).GetBalance(ctx, in)
+		return srv.(SwapsServer).RedeemHTLC(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/protos.Wallet/GetBalance",
+		FullMethod: "/protos.Swaps/RedeemHTLC",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServer).GetBalance(ctx, req.(*Empty))
+		return srv.(SwapsServer).RedeemHTLC(ctx, req.(*HTLCSpend))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Wallet_GetAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+func _Swaps_RefundHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HTLCSpend)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletServer).GetAddress(ctx, in)
+		return srv.(SwapsServer).RefundHTLC(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/protos.Wallet/GetAddress",
+		FullMethod: "/protos.Swaps/RefundHTLC",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletServer).GetAddress(ctx, req.(*Empty))
+		return srv.(SwapsServer).RefundHTLC(ctx, req.(*HTLCSpend))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Wallet_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "protos.Wallet",
-	HandlerType: (*WalletServer)(nil),
+func _Swaps_GetHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HTLCSpend)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapsServer).GetHTLC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Swaps/GetHTLC",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapsServer).GetHTLC(ctx, req.(*HTLCSpend))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Swaps_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Swaps",
+	HandlerType: (*SwapsServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "NewAccount",
-			Handler:    _Wallet_NewAccount_Handler,
+			MethodName: "CreateHTLC",
+			Handler:    _Swaps_CreateHTLC_Handler,
 		},
 		{
-			MethodName: "GetBalance",
-			Handler:    _Wallet_GetBalance_Handler,
+			MethodName: "RedeemHTLC",
+			Handler:    _Swaps_RedeemHTLC_Handler,
 		},
 		{
-			MethodName: "GetAddress",
-			Handler:    _Wallet_GetAddress_Handler,
+			MethodName: "RefundHTLC",
+			Handler:    _Swaps_RefundHTLC_Handler,
+		},
+		{
+			MethodName: "GetHTLC",
+			Handler:    _Swaps_GetHTLC_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -1100,7 +3658,11 @@ var _Wallet_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type MinerClient interface {
 	StartMining(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Returns once the miner has stopped
 	StopMining(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	GetMiningStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MiningStatus, error)
+	// Mine exactly that many blocks straight away, returning their hashes
+	GenerateBlocks(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GeneratedBlocks, error)
 }
 
 type minerClient struct {
@@ -1129,10 +3691,32 @@ func (c *minerClient) StopMining(ctx context.Context, in *Empty, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *minerClient) GetMiningStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MiningStatus, error) {
+	out := new(MiningStatus)
+	err := c.cc.Invoke(ctx, "/protos.Miner/GetMiningStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *minerClient) GenerateBlocks(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GeneratedBlocks, error) {
+	out := new(GeneratedBlocks)
+	err := c.cc.Invoke(ctx, "/protos.Miner/GenerateBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MinerServer is the server API for Miner service.
 type MinerServer interface {
 	StartMining(context.Context, *Empty) (*Empty, error)
+	// Returns once the miner has stopped
 	StopMining(context.Context, *Empty) (*Empty, error)
+	GetMiningStatus(context.Context, *Empty) (*MiningStatus, error)
+	// Mine exactly that many blocks straight away, returning their hashes
+	GenerateBlocks(context.Context, *GenerateRequest) (*GeneratedBlocks, error)
 }
 
 func RegisterMinerServer(s *grpc.Server, srv MinerServer) {
@@ -1175,6 +3759,42 @@ func _Miner_StopMining_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Miner_GetMiningStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MinerServer).GetMiningStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Miner/GetMiningStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MinerServer).GetMiningStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Miner_GenerateBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MinerServer).GenerateBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Miner/GenerateBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MinerServer).GenerateBlocks(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Miner_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Miner",
 	HandlerType: (*MinerServer)(nil),
@@ -1187,54 +3807,358 @@ var _Miner_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopMining",
 			Handler:    _Miner_StopMining_Handler,
 		},
+		{
+			MethodName: "GetMiningStatus",
+			Handler:    _Miner_GetMiningStatus_Handler,
+		},
+		{
+			MethodName: "GenerateBlocks",
+			Handler:    _Miner_GenerateBlocks_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "coin.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	// Replaces any faults already on the link
+	SetFaults(ctx context.Context, in *LinkFaults, opts ...grpc.CallOption) (*Empty, error)
+	// Back to a perfect network
+	ClearFaults(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	GetFaults(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*FaultsList, error)
+	// Takes effect straight away, returns every subsystem's level
+	SetLogLevel(ctx context.Context, in *LogLevel, opts ...grpc.CallOption) (*LogLevels, error)
+	GetLogLevels(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LogLevels, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) SetFaults(ctx context.Context, in *LinkFaults, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/protos.Admin/SetFaults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ClearFaults(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/protos.Admin/ClearFaults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetFaults(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*FaultsList, error) {
+	out := new(FaultsList)
+	err := c.cc.Invoke(ctx, "/protos.Admin/GetFaults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetLogLevel(ctx context.Context, in *LogLevel, opts ...grpc.CallOption) (*LogLevels, error) {
+	out := new(LogLevels)
+	err := c.cc.Invoke(ctx, "/protos.Admin/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetLogLevels(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LogLevels, error) {
+	out := new(LogLevels)
+	err := c.cc.Invoke(ctx, "/protos.Admin/GetLogLevels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	// Replaces any faults already on the link
+	SetFaults(context.Context, *LinkFaults) (*Empty, error)
+	// Back to a perfect network
+	ClearFaults(context.Context, *Empty) (*Empty, error)
+	GetFaults(context.Context, *Empty) (*FaultsList, error)
+	// Takes effect straight away, returns every subsystem's level
+	SetLogLevel(context.Context, *LogLevel) (*LogLevels, error)
+	GetLogLevels(context.Context, *Empty) (*LogLevels, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_SetFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkFaults)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/SetFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetFaults(ctx, req.(*LinkFaults))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ClearFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ClearFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/ClearFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ClearFaults(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetFaults(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogLevel)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetLogLevel(ctx, req.(*LogLevel))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetLogLevels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetLogLevels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetLogLevels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetLogLevels(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetFaults",
+			Handler:    _Admin_SetFaults_Handler,
+		},
+		{
+			MethodName: "ClearFaults",
+			Handler:    _Admin_ClearFaults_Handler,
+		},
+		{
+			MethodName: "GetFaults",
+			Handler:    _Admin_GetFaults_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _Admin_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "GetLogLevels",
+			Handler:    _Admin_GetLogLevels_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "coin.proto",
 }
 
-func init() { proto.RegisterFile("coin.proto", fileDescriptor_coin_13d4211dc95ee0ca) }
-
-var fileDescriptor_coin_13d4211dc95ee0ca = []byte{
-	// 656 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x41, 0x6f, 0xd3, 0x4c,
-	0x10, 0x8d, 0x9b, 0x38, 0x56, 0x27, 0x49, 0xfb, 0x69, 0x3e, 0x54, 0x59, 0x11, 0x85, 0xca, 0x20,
-	0xa8, 0x0a, 0x0d, 0xc8, 0x08, 0x15, 0x38, 0x20, 0xb5, 0x05, 0xb5, 0x15, 0x2a, 0xad, 0x9c, 0x48,
-	0xf4, 0xba, 0xb5, 0xa7, 0xa9, 0x55, 0x67, 0x37, 0x5d, 0xaf, 0x43, 0x7b, 0xe6, 0xc6, 0x2f, 0xe1,
-	0xbf, 0xf0, 0xa7, 0xd0, 0xae, 0x9d, 0xc4, 0x49, 0x1b, 0x09, 0x89, 0x93, 0x77, 0xde, 0xbe, 0xd9,
-	0x99, 0xf7, 0x66, 0x64, 0x80, 0x50, 0xc4, 0xbc, 0x33, 0x94, 0x42, 0x09, 0xac, 0x9b, 0x4f, 0xea,
-	0xbd, 0x82, 0x6a, 0xef, 0xec, 0x08, 0x11, 0x6a, 0xea, 0xe6, 0xe8, 0x93, 0x6b, 0x6d, 0x58, 0x9b,
-	0xcd, 0xc0, 0x9c, 0xf1, 0x01, 0xd8, 0x31, 0x8f, 0xe8, 0xc6, 0xad, 0x6e, 0x58, 0x9b, 0xb5, 0x20,
-	0x0f, 0xbc, 0x7d, 0x9d, 0x70, 0x82, 0xcf, 0x60, 0x45, 0x52, 0x48, 0xf1, 0x88, 0xe4, 0x69, 0x76,
-	0xfe, 0x85, 0x6e, 0x8b, 0xd4, 0x39, 0x54, 0x3f, 0x32, 0x62, 0x49, 0x46, 0xee, 0x52, 0xfe, 0x88,
-	0x09, 0xbc, 0x1f, 0x16, 0x34, 0x7a, 0x92, 0xf1, 0x94, 0x85, 0x2a, 0x16, 0x1c, 0xd7, 0xa1, 0x3a,
-	0x8a, 0xb9, 0x6b, 0x6d, 0x54, 0x37, 0x1b, 0x7e, 0x23, 0x6f, 0x31, 0xed, 0xf4, 0xce, 0x8e, 0x02,
-	0x8d, 0xe3, 0x63, 0xa8, 0x8d, 0x44, 0xa6, 0xdc, 0xea, 0xfc, 0xfd, 0x49, 0x60, 0x2e, 0xf0, 0x21,
-	0x2c, 0xa7, 0x71, 0x9f, 0x33, 0x95, 0x49, 0x72, 0x6b, 0xa6, 0x91, 0x29, 0x80, 0x6b, 0x50, 0xbf,
-	0xa4, 0xb8, 0x7f, 0xa9, 0xdc, 0xba, 0x69, 0xa2, 0x88, 0xbc, 0xdf, 0x16, 0x34, 0xf6, 0x12, 0x11,
-	0x5e, 0x1d, 0x12, 0x8b, 0x48, 0xe2, 0x53, 0x68, 0x0d, 0x25, 0x8d, 0x72, 0x88, 0xa5, 0x97, 0x85,
-	0xa4, 0x59, 0x10, 0x1f, 0x01, 0x0c, 0x48, 0x5e, 0x25, 0x14, 0x08, 0xa1, 0x8c, 0xac, 0x66, 0x50,
-	0x42, 0x74, 0x2f, 0x2a, 0x1e, 0x50, 0x57, 0xb1, 0xc1, 0xb0, 0xb0, 0x6e, 0x0a, 0xe0, 0x16, 0xfc,
-	0x17, 0xc5, 0x17, 0x17, 0x71, 0x98, 0x25, 0xea, 0xb6, 0xc7, 0x64, 0x9f, 0x94, 0x69, 0xb8, 0x15,
-	0xdc, 0xc1, 0xb5, 0x77, 0x5c, 0xf0, 0x90, 0x5c, 0xdb, 0x10, 0xf2, 0x60, 0xa1, 0x9a, 0x01, 0xd8,
-	0xa6, 0x49, 0x7c, 0xa1, 0x09, 0x5a, 0x90, 0xe9, 0xbf, 0xe1, 0xff, 0x3f, 0xf6, 0xab, 0xa4, 0x35,
-	0x28, 0x28, 0xb8, 0x03, 0x4d, 0x35, 0x1d, 0x44, 0xea, 0x2e, 0x19, 0x8b, 0x27, 0x29, 0xa5, 0x21,
-	0x05, 0x33, 0x44, 0xcf, 0x01, 0xfb, 0xf3, 0x60, 0xa8, 0x6e, 0xf5, 0xe1, 0x90, 0x92, 0x44, 0x78,
-	0x36, 0x54, 0x77, 0xc3, 0x2b, 0x2f, 0x00, 0x2c, 0x67, 0xd1, 0x75, 0x46, 0xa9, 0xfa, 0xc7, 0x7d,
-	0x59, 0x07, 0x67, 0x37, 0x0c, 0x45, 0xc6, 0x95, 0xde, 0x54, 0xce, 0x06, 0x64, 0xd2, 0x97, 0x03,
-	0x73, 0xf6, 0xb6, 0x60, 0xa5, 0xb8, 0xde, 0x97, 0xc4, 0x14, 0x45, 0xe8, 0x82, 0xc3, 0xa2, 0x48,
-	0x52, 0x9a, 0x16, 0xc4, 0x71, 0xe8, 0x3d, 0x01, 0x67, 0x8f, 0x25, 0x4c, 0x3b, 0xe9, 0x82, 0x73,
-	0x9e, 0x1f, 0x0d, 0xa9, 0x16, 0x8c, 0x43, 0xdf, 0x07, 0xe7, 0x94, 0x48, 0xc6, 0xbc, 0x8f, 0xcf,
-	0xc1, 0xd9, 0x17, 0x9c, 0x53, 0xa8, 0xb0, 0x35, 0x76, 0xc5, 0xe8, 0x6d, 0x4f, 0xf6, 0x50, 0xab,
-	0xae, 0xf8, 0x3f, 0x2d, 0x68, 0x96, 0x84, 0xa7, 0xf8, 0x01, 0x30, 0xc8, 0xc5, 0x95, 0x57, 0xfd,
-	0x3e, 0x6b, 0xdb, 0x93, 0x97, 0x73, 0x4b, 0x2b, 0xf8, 0x11, 0x56, 0xbb, 0xc4, 0xa3, 0x72, 0x62,
-	0xfb, 0xbe, 0x99, 0xe4, 0xee, 0xde, 0xc9, 0xf7, 0xdf, 0x41, 0xdd, 0x4c, 0x3b, 0xc5, 0x0e, 0x34,
-	0x8b, 0x2e, 0xf2, 0xed, 0x68, 0xcd, 0x6c, 0xc3, 0xdd, 0xcc, 0x6b, 0xb0, 0xbb, 0x8a, 0x29, 0xc2,
-	0xf7, 0xb0, 0x7a, 0x40, 0x6a, 0x46, 0xd1, 0x2c, 0xb9, 0x7d, 0x9f, 0x14, 0xaf, 0xf2, 0xda, 0xc2,
-	0x6d, 0x58, 0x3e, 0x20, 0x55, 0x34, 0x30, 0x97, 0x34, 0x5b, 0x5f, 0xd3, 0xfd, 0x5f, 0x16, 0xd4,
-	0xbf, 0xb1, 0x24, 0x21, 0x85, 0x3b, 0x00, 0x5f, 0xe9, 0xfb, 0x78, 0xd6, 0xab, 0x53, 0x87, 0x0d,
-	0xd0, 0x5e, 0x9b, 0x03, 0x8a, 0x71, 0x7b, 0x15, 0xec, 0x00, 0xe8, 0x92, 0xc5, 0x64, 0xe7, 0x6a,
-	0x4e, 0xde, 0x29, 0xee, 0xbd, 0x0a, 0xbe, 0x35, 0xfc, 0xdd, 0x7c, 0x29, 0xe6, 0xf9, 0x0b, 0xcb,
-	0xf8, 0x11, 0xd8, 0xc7, 0x31, 0x27, 0x89, 0xdb, 0xd0, 0xe8, 0x2a, 0x26, 0xd5, 0x71, 0xcc, 0xf5,
-	0x96, 0x2c, 0x12, 0x39, 0x9e, 0xe7, 0x4b, 0x80, 0xae, 0x12, 0xc3, 0xbf, 0x63, 0x9f, 0xe7, 0x3f,
-	0xe7, 0x37, 0x7f, 0x02, 0x00, 0x00, 0xff, 0xff, 0x06, 0xd0, 0xa4, 0xe9, 0xb1, 0x05, 0x00, 0x00,
+func init() { proto.RegisterFile("coin.proto", fileDescriptor_coin_4577121ffd2361e8) }
+
+var fileDescriptor_coin_4577121ffd2361e8 = []byte{
+	// 2159 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0x5b, 0x6f, 0x23, 0x49,
+	0xf5, 0x77, 0xfb, 0xee, 0x63, 0x67, 0x92, 0xa9, 0x19, 0xcd, 0xdf, 0xf2, 0x7f, 0x77, 0x09, 0xb5,
+	0xb7, 0xb0, 0xec, 0xcc, 0x0e, 0x66, 0x86, 0x1d, 0x16, 0x69, 0xa5, 0x24, 0xcb, 0x24, 0x61, 0x33,
+	0x4c, 0x68, 0x67, 0x61, 0x9e, 0x40, 0x15, 0xfb, 0xc4, 0x29, 0xd2, 0xee, 0xf6, 0x76, 0x57, 0x67,
+	0x26, 0x3c, 0xf3, 0xc2, 0x0b, 0x4f, 0x08, 0x21, 0xf1, 0x00, 0x48, 0x3c, 0xf3, 0x39, 0x90, 0xf8,
+	0x12, 0x3c, 0x80, 0xc4, 0x13, 0x9f, 0x01, 0xd5, 0xad, 0xbb, 0xba, 0x6d, 0x27, 0x99, 0x85, 0x27,
+	0xf7, 0x39, 0x55, 0xe7, 0xd4, 0xb9, 0xd7, 0xaf, 0x64, 0x80, 0x71, 0xc4, 0xc3, 0x07, 0xf3, 0x38,
+	0x12, 0x11, 0x69, 0xaa, 0x9f, 0x84, 0xa6, 0x50, 0x3b, 0x7e, 0x71, 0x40, 0x08, 0xd4, 0xc5, 0xab,
+	0x83, 0xcf, 0xfa, 0xde, 0xa6, 0xb7, 0xd5, 0xf3, 0xd5, 0x37, 0xd9, 0x82, 0xf5, 0x34, 0x0c, 0xa2,
+	0xf1, 0x39, 0x0f, 0xa7, 0xa3, 0x71, 0xcc, 0xe7, 0xa2, 0x5f, 0x55, 0xcb, 0x65, 0x36, 0xb9, 0x0b,
+	0x0d, 0x1e, 0x4e, 0xf0, 0x55, 0xbf, 0xb6, 0xe9, 0x6d, 0xd5, 0x7d, 0x4d, 0x90, 0x01, 0xb4, 0x13,
+	0xfc, 0x32, 0xc5, 0x70, 0x8c, 0xfd, 0xba, 0x5a, 0xc8, 0x68, 0xca, 0xe5, 0xb1, 0xcf, 0xc9, 0x7b,
+	0x70, 0x2b, 0xc6, 0x31, 0xf2, 0x0b, 0x8c, 0x8f, 0xd2, 0x93, 0xcf, 0xf1, 0xd2, 0x18, 0x50, 0xe2,
+	0xca, 0x03, 0x2e, 0x58, 0x90, 0xa2, 0x32, 0xa0, 0xee, 0x6b, 0x82, 0xbc, 0x03, 0x6b, 0x45, 0xf3,
+	0x6a, 0x4a, 0xb8, 0xc8, 0xa4, 0x7f, 0xf0, 0xa0, 0x7b, 0x1c, 0xb3, 0x30, 0x61, 0x63, 0xc1, 0xa3,
+	0x90, 0xbc, 0x09, 0xb5, 0x0b, 0x1e, 0xf6, 0xbd, 0xcd, 0xda, 0x56, 0x77, 0xd8, 0xd5, 0xe1, 0x48,
+	0x1e, 0x1c, 0xbf, 0x38, 0xf0, 0x25, 0x9f, 0x7c, 0x0d, 0xea, 0x17, 0x51, 0x2a, 0x75, 0x95, 0xd6,
+	0x9f, 0xfb, 0x6a, 0x81, 0xbc, 0x01, 0x9d, 0x84, 0x4f, 0x43, 0x26, 0xd2, 0x58, 0xfb, 0xd5, 0xf3,
+	0x73, 0x06, 0xb9, 0x07, 0xcd, 0x33, 0xe4, 0xd3, 0x33, 0xd1, 0x6f, 0x2a, 0x53, 0x0d, 0x25, 0x83,
+	0x21, 0xcd, 0x3a, 0xe6, 0x33, 0xec, 0xb7, 0x74, 0x30, 0x2c, 0x4d, 0xff, 0xe6, 0x41, 0x77, 0x47,
+	0x52, 0xfb, 0xc8, 0x26, 0x18, 0x4b, 0xbf, 0xe6, 0x31, 0x5e, 0x68, 0x16, 0x4b, 0xce, 0x4c, 0x50,
+	0x8a, 0x4c, 0xf2, 0x16, 0xc0, 0x0c, 0xe3, 0xf3, 0x00, 0xfd, 0x28, 0xb2, 0x99, 0x71, 0x38, 0xd2,
+	0x4e, 0xc1, 0x67, 0x38, 0x12, 0x6c, 0x36, 0x37, 0x89, 0xc9, 0x19, 0xe4, 0x03, 0xd8, 0x98, 0xf0,
+	0xd3, 0x53, 0x3e, 0x4e, 0x03, 0x71, 0x79, 0xcc, 0xe2, 0x29, 0x0a, 0xe5, 0xcc, 0x9a, 0xbf, 0xc0,
+	0x97, 0xd1, 0x0f, 0x23, 0x99, 0xc5, 0x86, 0xda, 0xa0, 0x89, 0x55, 0x9e, 0xd2, 0x19, 0x34, 0x94,
+	0x91, 0xe4, 0x9b, 0x72, 0x83, 0x74, 0x48, 0xd9, 0xdf, 0x1d, 0xde, 0xb1, 0xb1, 0x74, 0x7c, 0xf5,
+	0xcd, 0x16, 0xf2, 0x31, 0xf4, 0x44, 0x9e, 0xa4, 0xa4, 0x5f, 0xdd, 0xac, 0xb9, 0x22, 0x4e, 0x02,
+	0xfd, 0xc2, 0x46, 0xda, 0x82, 0xc6, 0xf7, 0x67, 0x73, 0x71, 0x49, 0x5f, 0x40, 0x63, 0x1f, 0x83,
+	0x20, 0x52, 0xb5, 0x2c, 0xc3, 0xec, 0x29, 0xb3, 0xd4, 0x37, 0xe9, 0x43, 0x2b, 0x44, 0xf1, 0x32,
+	0x8a, 0xcf, 0x55, 0xa4, 0x3a, 0xbe, 0x25, 0xc9, 0xd7, 0xa1, 0x37, 0xc5, 0x10, 0x13, 0x9e, 0xfc,
+	0xec, 0x4c, 0xc6, 0x5a, 0xd7, 0x50, 0xd7, 0xf0, 0x64, 0xa4, 0xe9, 0x8f, 0xa1, 0xb6, 0x3d, 0x3e,
+	0xff, 0xdf, 0xeb, 0xfd, 0x77, 0x15, 0x88, 0xeb, 0x98, 0x6c, 0x8e, 0x44, 0xfc, 0x97, 0x4d, 0xd1,
+	0x87, 0xd6, 0x29, 0xa2, 0xcf, 0x04, 0x9a, 0xa4, 0x5b, 0x52, 0xf5, 0xa3, 0x88, 0x99, 0xc0, 0xe9,
+	0xa5, 0x4a, 0x75, 0xc7, 0xcf, 0x68, 0xf2, 0x2e, 0xb4, 0xa2, 0x54, 0xcc, 0x53, 0x91, 0xf4, 0x1b,
+	0x8b, 0x85, 0x6f, 0xd7, 0x08, 0x85, 0xde, 0xf8, 0x8c, 0x85, 0x53, 0x34, 0x86, 0x35, 0x95, 0x61,
+	0x05, 0x1e, 0xd9, 0x80, 0xda, 0x29, 0xda, 0x22, 0x97, 0x9f, 0x52, 0x2a, 0xc1, 0x70, 0x92, 0xb9,
+	0xd3, 0xd6, 0x52, 0x2e, 0x8f, 0xbc, 0x0d, 0x4d, 0x1e, 0xaa, 0xf3, 0x3b, 0x8b, 0x8d, 0x69, 0x96,
+	0x72, 0x45, 0xa6, 0xdf, 0xc1, 0x55, 0xa4, 0x79, 0x85, 0x46, 0xeb, 0x96, 0x1a, 0x4d, 0xc0, 0xba,
+	0x13, 0xef, 0x11, 0x86, 0x62, 0xe9, 0xe0, 0xcb, 0x6d, 0xa9, 0xae, 0xb6, 0xc5, 0xb8, 0x59, 0xcb,
+	0xdd, 0xbc, 0x07, 0x4d, 0x1d, 0x08, 0x33, 0xed, 0x0c, 0x45, 0xff, 0xe2, 0x01, 0x39, 0x62, 0xb1,
+	0xe0, 0x2c, 0x70, 0xe7, 0xd0, 0x63, 0xe8, 0x3a, 0x85, 0x5c, 0xee, 0x11, 0xb7, 0x2e, 0xdc, 0x7d,
+	0xe4, 0x7d, 0x68, 0x27, 0x73, 0x0c, 0x27, 0x3c, 0x9c, 0x2e, 0x9a, 0xf7, 0xdc, 0xcf, 0x16, 0xc9,
+	0x13, 0x80, 0x6c, 0x2c, 0x25, 0x66, 0x9c, 0xf5, 0xed, 0x56, 0x63, 0xcf, 0xc8, 0x6e, 0xf0, 0x9d,
+	0xbd, 0x74, 0x1f, 0x36, 0xca, 0xeb, 0xd2, 0xb9, 0xb9, 0x5b, 0x8c, 0x86, 0x2a, 0x4e, 0xc3, 0x6a,
+	0x69, 0x1a, 0xd2, 0x2f, 0xe0, 0xce, 0xa2, 0xe7, 0x09, 0xf9, 0xb4, 0xd4, 0xec, 0x7a, 0x16, 0x0f,
+	0x4a, 0xc6, 0xad, 0xee, 0xf9, 0x4d, 0x68, 0x1f, 0x21, 0xc6, 0x87, 0x3c, 0x51, 0xc3, 0x69, 0x8e,
+	0x18, 0x6b, 0x25, 0x1d, 0x5f, 0x13, 0xf4, 0x11, 0xd4, 0x8f, 0xd8, 0x54, 0x99, 0x1d, 0x9d, 0x9e,
+	0x26, 0x28, 0x4c, 0xd7, 0x1a, 0x4a, 0x4a, 0x05, 0x7c, 0xc6, 0xf5, 0xdc, 0x5c, 0xf3, 0x35, 0x41,
+	0xf7, 0xa1, 0xa3, 0x66, 0x93, 0x12, 0x7d, 0x17, 0x9a, 0x27, 0x92, 0xb0, 0xe6, 0xad, 0x15, 0xc6,
+	0x97, 0x6f, 0x16, 0xa5, 0x26, 0x11, 0x09, 0x16, 0xd8, 0x2e, 0x54, 0x04, 0x7d, 0x02, 0xa0, 0xb6,
+	0xfd, 0x28, 0xc5, 0xf8, 0x52, 0x16, 0xd9, 0x59, 0x3e, 0xc7, 0xd5, 0xb7, 0x33, 0x3e, 0xab, 0x85,
+	0xf1, 0xf9, 0x1e, 0x6c, 0x38, 0x8e, 0x67, 0xf2, 0xe5, 0x22, 0xa5, 0x7f, 0xf6, 0x0a, 0xc5, 0x7c,
+	0x10, 0x9e, 0x46, 0x5f, 0xb5, 0xa4, 0xde, 0x80, 0xce, 0x49, 0x76, 0xd7, 0x98, 0x1c, 0x66, 0x0c,
+	0xc7, 0xd0, 0x5a, 0xe1, 0x46, 0x7b, 0x07, 0xd6, 0xc6, 0x51, 0x78, 0xca, 0xe3, 0x19, 0xd3, 0x59,
+	0xd4, 0x55, 0x5f, 0x64, 0xd2, 0x1f, 0x40, 0x6f, 0x7b, 0x32, 0x89, 0x31, 0x49, 0xb4, 0x2b, 0x7d,
+	0x68, 0x31, 0x4d, 0x2b, 0xf3, 0x3a, 0xbe, 0x25, 0xc9, 0x26, 0xd4, 0xe7, 0x6c, 0xaa, 0x8b, 0xa8,
+	0x3b, 0xec, 0xe5, 0xc5, 0x30, 0x45, 0x5f, 0xad, 0xd0, 0x5f, 0x79, 0x50, 0xff, 0x42, 0xc2, 0x86,
+	0x65, 0x4d, 0x9b, 0x61, 0x90, 0xaa, 0x8b, 0x41, 0xde, 0x86, 0xa6, 0x9e, 0x5d, 0xca, 0xf8, 0x52,
+	0xaf, 0x98, 0x25, 0xc7, 0xc3, 0x7a, 0xf9, 0xce, 0x96, 0x88, 0xe9, 0x84, 0x25, 0xfa, 0xea, 0x6b,
+	0xfb, 0x19, 0x4d, 0x7f, 0x0a, 0x6d, 0x69, 0x8a, 0xaa, 0x14, 0x0a, 0x8d, 0x54, 0xbc, 0x8a, 0x6c,
+	0xa1, 0x64, 0xa6, 0xcb, 0x0d, 0xbe, 0x5e, 0x5a, 0x5e, 0x26, 0x32, 0x1a, 0x27, 0x2c, 0x60, 0xf2,
+	0x6e, 0x35, 0xc3, 0xda, 0x90, 0xf4, 0xaf, 0x1e, 0x10, 0x13, 0x38, 0x77, 0x68, 0x2c, 0xf3, 0x7c,
+	0x45, 0x25, 0x15, 0xd3, 0x5a, 0x2b, 0xa7, 0xf5, 0x46, 0xe9, 0xcb, 0xee, 0xbc, 0x86, 0x73, 0xe7,
+	0x0d, 0xa0, 0x6d, 0x6e, 0xa2, 0x89, 0xb9, 0xfa, 0x33, 0x5a, 0xee, 0x4f, 0x30, 0x14, 0x66, 0xfa,
+	0xab, 0x6f, 0xfa, 0x5b, 0x0f, 0x6e, 0x19, 0x57, 0xf6, 0x79, 0x22, 0xa2, 0xf8, 0xf2, 0xba, 0x01,
+	0xb0, 0xe8, 0x78, 0x71, 0x00, 0xac, 0x88, 0xa6, 0x6b, 0x58, 0x6d, 0x85, 0x61, 0x75, 0xc7, 0xb0,
+	0xdf, 0xe4, 0x86, 0xed, 0xe8, 0xb0, 0xbb, 0x09, 0xf1, 0x0a, 0x09, 0x91, 0x47, 0xea, 0x24, 0x9b,
+	0x23, 0x15, 0xf1, 0xba, 0x47, 0xca, 0x1b, 0xac, 0xe0, 0xb8, 0x8e, 0x6b, 0x81, 0x47, 0xdf, 0x84,
+	0xd6, 0xf6, 0x78, 0x1c, 0xa5, 0xfa, 0x76, 0x0a, 0x99, 0x81, 0x1c, 0x1d, 0x5f, 0x7d, 0xd3, 0x0f,
+	0xe0, 0x96, 0x59, 0xde, 0x8d, 0x91, 0x09, 0x9c, 0xac, 0xee, 0x29, 0xba, 0x0d, 0xad, 0xeb, 0x3d,
+	0x93, 0x23, 0x5c, 0x5e, 0x1a, 0xec, 0x24, 0xb0, 0x58, 0x22, 0x67, 0xd0, 0x3d, 0x58, 0x7f, 0x96,
+	0x06, 0x82, 0x27, 0x7c, 0x6a, 0x01, 0x8a, 0x72, 0xfa, 0xcb, 0x94, 0xc7, 0x38, 0x51, 0xba, 0xd6,
+	0xfc, 0x8c, 0x96, 0xc7, 0xe8, 0x9b, 0x41, 0x5f, 0x9e, 0x3d, 0xdf, 0x92, 0xf4, 0x4f, 0x5e, 0xae,
+	0xc9, 0x44, 0xfd, 0x8a, 0x69, 0xb0, 0x80, 0xed, 0xab, 0x4b, 0xb0, 0x7d, 0xc1, 0x92, 0xda, 0x6a,
+	0x4b, 0xea, 0x05, 0x4b, 0xdc, 0x50, 0x34, 0x8a, 0x5d, 0xf7, 0x7b, 0x0f, 0xba, 0xfb, 0xc7, 0x87,
+	0xbb, 0xd6, 0xd3, 0x2d, 0x58, 0x8f, 0x71, 0xcc, 0xe7, 0x1c, 0x43, 0x51, 0xc0, 0x62, 0x65, 0xf6,
+	0x0a, 0x30, 0x66, 0x07, 0x7f, 0xcd, 0x19, 0xfc, 0x6f, 0x01, 0x48, 0x27, 0x76, 0xf4, 0xdd, 0xa2,
+	0x8b, 0xc3, 0xe1, 0xb8, 0x00, 0xae, 0x51, 0x00, 0x70, 0xf4, 0x8f, 0x55, 0xa8, 0x4b, 0xeb, 0x5e,
+	0x63, 0xfe, 0xdd, 0xe8, 0x89, 0x94, 0x99, 0x59, 0x77, 0xcc, 0x5c, 0xe2, 0x7a, 0x63, 0xb9, 0xeb,
+	0x14, 0x7a, 0x31, 0x9e, 0xa6, 0xe1, 0xa4, 0x08, 0x0a, 0x5d, 0xde, 0x55, 0xcf, 0x9f, 0x3c, 0x74,
+	0xed, 0x12, 0x8e, 0x95, 0x45, 0x28, 0x76, 0x2e, 0xfb, 0x1d, 0xa5, 0xd0, 0x92, 0x52, 0xd7, 0x3c,
+	0x46, 0x3e, 0x93, 0x97, 0x85, 0x46, 0x80, 0x19, 0x4d, 0xc7, 0xd0, 0x91, 0x11, 0x1a, 0xc9, 0xf2,
+	0x7d, 0x8d, 0x30, 0xb9, 0x2a, 0x6b, 0x45, 0x95, 0x16, 0xe8, 0xd5, 0x33, 0xa0, 0x47, 0x77, 0x61,
+	0x7d, 0x0f, 0x43, 0x8c, 0x99, 0x40, 0x5b, 0x28, 0xf7, 0x1c, 0xb0, 0x20, 0xcb, 0xd0, 0x50, 0x6e,
+	0x81, 0x57, 0x8b, 0xad, 0xf9, 0x8d, 0x5c, 0xc9, 0xc4, 0x64, 0x5e, 0x0e, 0x72, 0x96, 0x9c, 0xa1,
+	0x9e, 0x87, 0x3d, 0xdf, 0x50, 0xf4, 0xd7, 0x1e, 0xf4, 0x9e, 0xf1, 0x50, 0xe6, 0x4b, 0x30, 0x91,
+	0xaa, 0xf1, 0x97, 0x08, 0x59, 0x20, 0xba, 0x69, 0x34, 0x41, 0x36, 0xa1, 0xab, 0x4f, 0x7d, 0xc6,
+	0x43, 0x9c, 0x18, 0x07, 0x5d, 0x96, 0xea, 0x74, 0xc1, 0x62, 0xa1, 0xd2, 0x60, 0x9e, 0x84, 0x19,
+	0x63, 0xe5, 0x35, 0xb8, 0x01, 0x35, 0xc1, 0xe7, 0x26, 0xfb, 0xf2, 0x53, 0x0e, 0x4e, 0x38, 0xe4,
+	0xe1, 0xf9, 0x53, 0x96, 0x06, 0x42, 0x5d, 0x12, 0x73, 0x34, 0xcf, 0xbc, 0x8e, 0xaf, 0xbe, 0xa5,
+	0xe3, 0x01, 0x13, 0x18, 0x8e, 0x2f, 0x8d, 0x21, 0x96, 0x94, 0xc7, 0xfc, 0x9c, 0x0b, 0x81, 0xb1,
+	0xc5, 0x13, 0x9a, 0x92, 0x5a, 0x82, 0x28, 0xd1, 0x1d, 0xe1, 0xf9, 0xea, 0x5b, 0x6a, 0x89, 0x31,
+	0x8a, 0xe5, 0x1b, 0xb2, 0xa1, 0xd8, 0x96, 0x94, 0xbb, 0x27, 0xd1, 0xcb, 0x50, 0x15, 0x5b, 0xdb,
+	0x57, 0xdf, 0xf4, 0x3b, 0x00, 0xda, 0x22, 0x05, 0x0c, 0xb7, 0x24, 0xc4, 0x0b, 0x33, 0xf8, 0x46,
+	0xec, 0xe5, 0x92, 0x1b, 0xee, 0xeb, 0x0d, 0xf4, 0x53, 0x68, 0x1f, 0x46, 0xd3, 0x43, 0xbc, 0xc0,
+	0x40, 0x85, 0x28, 0x3d, 0x49, 0x2e, 0x13, 0x81, 0x33, 0xe3, 0x50, 0xce, 0x50, 0xb0, 0x51, 0x6e,
+	0x33, 0xc9, 0xd4, 0x04, 0x7d, 0x0c, 0x1d, 0x2b, 0x9f, 0x90, 0x2d, 0x68, 0x2a, 0xae, 0x3d, 0x77,
+	0x23, 0x3b, 0xd7, 0x6c, 0xf1, 0xcd, 0xfa, 0x90, 0x41, 0x4b, 0xa2, 0x58, 0x89, 0xd5, 0xdf, 0x87,
+	0xd6, 0x6e, 0x14, 0x86, 0x38, 0x16, 0x24, 0x83, 0x99, 0xea, 0x31, 0x3b, 0xc8, 0x00, 0xcb, 0xf6,
+	0xf8, 0x9c, 0x56, 0xc8, 0x7d, 0x68, 0xef, 0xa1, 0x90, 0x62, 0x49, 0xbe, 0x53, 0xbd, 0x7f, 0x07,
+	0xd9, 0x41, 0x16, 0x1a, 0xd3, 0xca, 0xf0, 0x77, 0x75, 0xe8, 0x15, 0x90, 0xf7, 0x27, 0x40, 0x7c,
+	0x7d, 0x3f, 0x39, 0x6c, 0xb2, 0x0c, 0x22, 0x0e, 0x8a, 0xea, 0x69, 0x85, 0xec, 0xc3, 0xfa, 0x08,
+	0xc3, 0x89, 0x2b, 0x38, 0x58, 0x22, 0x68, 0x5a, 0x62, 0xf0, 0x7f, 0x4b, 0xd6, 0xe4, 0x93, 0x8b,
+	0x56, 0xc8, 0x33, 0xb8, 0xad, 0xef, 0xae, 0x9b, 0xea, 0xba, 0xe2, 0x69, 0x40, 0x2b, 0xe4, 0x73,
+	0x58, 0x97, 0x0f, 0x95, 0xa5, 0xca, 0x16, 0x05, 0xae, 0x51, 0x76, 0x04, 0x77, 0x76, 0xa3, 0xd9,
+	0x09, 0x0f, 0xb1, 0x10, 0xb8, 0xff, 0x5f, 0x2d, 0x94, 0x5c, 0xa3, 0xf1, 0x10, 0xee, 0x3c, 0xe5,
+	0x21, 0x0b, 0xf8, 0x2f, 0xf0, 0xa6, 0x26, 0x5e, 0x11, 0xbb, 0xa7, 0x70, 0x77, 0x27, 0x8e, 0xd8,
+	0x64, 0xcc, 0x12, 0x71, 0x6d, 0x0e, 0x57, 0xeb, 0x19, 0x3e, 0x81, 0xa6, 0x19, 0x3b, 0x0f, 0xa0,
+	0x67, 0x6a, 0x42, 0x31, 0x48, 0xf1, 0xa1, 0xb3, 0x50, 0x07, 0xc3, 0x7f, 0xd6, 0xa0, 0x31, 0x52,
+	0x13, 0xe7, 0xbb, 0x72, 0x86, 0x89, 0x42, 0x9c, 0x4a, 0x45, 0xb9, 0xcc, 0x2a, 0x5a, 0x79, 0xe8,
+	0x91, 0xfb, 0xd0, 0xd9, 0x43, 0x61, 0x2c, 0x28, 0x09, 0x15, 0x0d, 0x50, 0xdb, 0x3f, 0x52, 0x75,
+	0xaf, 0xed, 0x23, 0x85, 0x65, 0xf5, 0xac, 0x58, 0x10, 0x21, 0x1f, 0xc9, 0x09, 0x95, 0xd8, 0x03,
+	0x0a, 0xaf, 0x89, 0xc1, 0xed, 0xc2, 0x66, 0xc9, 0xa2, 0x15, 0x72, 0x00, 0xa4, 0xe8, 0x8b, 0x7a,
+	0x51, 0xf5, 0x97, 0xd8, 0xaf, 0x4f, 0x5c, 0x16, 0x5a, 0x29, 0x42, 0x2b, 0xe4, 0x7b, 0x2a, 0x2c,
+	0x06, 0xe3, 0xc8, 0x47, 0x40, 0x42, 0xee, 0x96, 0xa0, 0xad, 0xd6, 0xb1, 0xe1, 0xbe, 0x14, 0x8c,
+	0x1d, 0xbb, 0x70, 0x3b, 0x17, 0xb6, 0x78, 0x79, 0xb9, 0xf8, 0xbd, 0x12, 0xd7, 0xec, 0x2e, 0x2b,
+	0xb1, 0x08, 0xf0, 0x66, 0x4a, 0xcc, 0x6e, 0x5a, 0x19, 0xfe, 0xab, 0x0a, 0xcd, 0x9f, 0xb0, 0x20,
+	0x40, 0x41, 0x3e, 0x06, 0xf8, 0x21, 0xbe, 0xb4, 0xa8, 0x74, 0x3d, 0x13, 0xd1, 0x8c, 0xc1, 0xbd,
+	0x12, 0xc3, 0x00, 0x53, 0x5a, 0x21, 0x0f, 0x00, 0x64, 0xde, 0x8c, 0x05, 0xa5, 0x3c, 0x67, 0x7a,
+	0xb2, 0x33, 0xc9, 0x63, 0xb5, 0xdf, 0xc2, 0xc3, 0xd2, 0xfe, 0xd5, 0xc7, 0x7c, 0x06, 0xb7, 0x34,
+	0x61, 0x01, 0x26, 0xc9, 0xd2, 0x53, 0x02, 0xaf, 0x83, 0x85, 0x05, 0x73, 0x18, 0xad, 0x90, 0x1d,
+	0xb8, 0xbb, 0x87, 0xa2, 0xc4, 0xc7, 0x05, 0x33, 0x56, 0x6b, 0x78, 0xe8, 0x91, 0x47, 0xca, 0x01,
+	0x9b, 0xb7, 0x62, 0xdd, 0xad, 0xcc, 0xd7, 0xf0, 0xef, 0x1e, 0x34, 0x46, 0x2f, 0xd9, 0x3c, 0x21,
+	0xdf, 0x02, 0xd0, 0x9e, 0x28, 0xa0, 0x97, 0xb5, 0x8f, 0x03, 0x4a, 0x07, 0x3d, 0x97, 0x49, 0x2b,
+	0xe4, 0x13, 0x00, 0x1f, 0x27, 0x88, 0x33, 0x25, 0x72, 0xdb, 0x5d, 0x55, 0x38, 0xe8, 0xaa, 0x69,
+	0xa2, 0x64, 0x25, 0x4e, 0xfb, 0x0a, 0xb2, 0x1f, 0x42, 0x4b, 0xba, 0xba, 0x42, 0xb0, 0x64, 0xe5,
+	0xf0, 0x1f, 0x1e, 0x34, 0x24, 0x0a, 0x89, 0xc9, 0x7d, 0xe8, 0x8e, 0x04, 0x8b, 0x85, 0x86, 0x34,
+	0x2b, 0x9b, 0xdf, 0x5e, 0x3b, 0x1f, 0x02, 0x8c, 0x44, 0x34, 0xbf, 0xe1, 0xee, 0x27, 0xaa, 0xf7,
+	0x0a, 0x68, 0xa9, 0x24, 0x92, 0xb5, 0x81, 0xbb, 0x49, 0xd7, 0x90, 0x05, 0x64, 0x66, 0x6a, 0x64,
+	0xbe, 0x97, 0xd0, 0xde, 0x60, 0x61, 0xc1, 0x20, 0x38, 0x5a, 0x19, 0xfe, 0xb2, 0x0a, 0x8d, 0xed,
+	0xc9, 0x8c, 0x87, 0xe4, 0x21, 0x74, 0x46, 0x28, 0x2c, 0x44, 0x5a, 0x44, 0x1f, 0x8b, 0xb6, 0xdf,
+	0x87, 0xee, 0x6e, 0x80, 0x2c, 0x36, 0x32, 0xd7, 0xb9, 0xfa, 0x50, 0x8d, 0xd0, 0xe5, 0x9b, 0xb3,
+	0xf3, 0x72, 0x40, 0x44, 0x2b, 0xe4, 0x11, 0x74, 0x47, 0x28, 0x32, 0xac, 0xb3, 0x00, 0x4d, 0x06,
+	0xb7, 0xcb, 0x1c, 0x19, 0x98, 0x21, 0xf4, 0xf6, 0x72, 0xa9, 0x85, 0xa3, 0x96, 0xc9, 0x9c, 0xe8,
+	0xbf, 0x97, 0xbe, 0xfd, 0x9f, 0x01, 0x00, 0x39, 0xdf, 0xba, 0x47, 0x73, 0x1a, 0x00, 0x00,
 }
@@ -0,0 +1,589 @@
+// HTTP/JSON gateway to a node's gRPC services, for tools which can't
+// speak gRPC e.g. anything in a browser. Each request is turned into the
+// matching RPC over conn, so it works in front of any node:
+//
+//	curl localhost:8080/blocks?limit=5
+//	curl localhost:8080/blocks/<hash or height>
+//	curl localhost:8080/tx/<txid>.hex
+//	curl localhost:8080/address/<address>/utxos?offset=20
+//	curl -H 'Content-Type: application/json' -H "Authorization: Bearer $TOKEN" \
+//		-d '{"hex": "..."}' localhost:8080/tx
+//
+// Blocks and transactions are JSON, or the serialized bytes in hex with
+// a .hex suffix, which is also what POST /tx and POST /blocks take as
+// {"hex": ...}. Lists along the chain are newest first and paged with
+// offset and limit. openapi.json, served at /openapi.json, describes
+// every route.
+//
+// Only GETs can be made from other origins, and POSTs have to be JSON, so
+// a web page can't get a browser to make them. Spending, mining and
+// broadcasting also need the gateway's token, and are off without one.
+//
+// bitcoind serves the gateway with -rest, along with the explorer.
+package rest
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/serialize"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	MAX_BODY   = 4 << 20 // Bytes, enough for a full block in hex
+	HEX_SUFFIX = ".hex"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+type Gateway struct {
+	conn   *grpc.ClientConn
+	params *chain.ChainParams // Addresses are for this network
+	token  string             // For the private routes, which are off if empty
+	mux    *http.ServeMux
+}
+
+type handler func(g *Gateway, r *http.Request) (interface{}, error)
+
+type route struct {
+	method  string
+	path    string // With {name} for path parameters, as in openapi.json
+	handler handler
+}
+
+var routes = []route{
+	{"GET", "/blocks", (*Gateway).listBlocks},
+	{"POST", "/blocks", (*Gateway).receiveBlock},
+	{"GET", "/blocks/{id}", (*Gateway).getBlock},
+	{"GET", "/tx/{id}", (*Gateway).getTransaction},
+	{"POST", "/tx", private((*Gateway).broadcastTransaction)},
+	{"GET", "/mempool", (*Gateway).getMempool},
+	{"GET", "/address/{address}/utxos", (*Gateway).getUTXOs},
+	{"GET", "/address/{address}/history", (*Gateway).getHistory},
+	{"GET", "/address/{address}/balance", (*Gateway).getAddressBalance},
+	{"GET", "/peers", (*Gateway).getPeers},
+	{"POST", "/wallet/account", private((*Gateway).newAccount)},
+	{"GET", "/wallet/address", private((*Gateway).getAddress)},
+	{"GET", "/wallet/balance", private((*Gateway).getBalance)},
+	{"GET", "/wallet/history", private((*Gateway).getWalletHistory)},
+	{"GET", "/wallet/multisig", private((*Gateway).getMultisig)},
+	{"POST", "/wallet/send", private((*Gateway).send)},
+	{"GET", "/mining/status", (*Gateway).getMiningStatus},
+	{"POST", "/mining/start", private((*Gateway).startMining)},
+	{"POST", "/mining/stop", private((*Gateway).stopMining)},
+	{"POST", "/mining/generate", private((*Gateway).generate)},
+}
+
+// A response which isn't JSON, the hex encodings
+type hexResponse string
+
+// Bad requests found before getting to the node
+type requestError struct{ error }
+
+func badRequest(format string, a ...interface{}) error {
+	return requestError{fmt.Errorf(format, a...)}
+}
+
+// Refused before getting to the node for some other reason
+type statusError struct {
+	status int
+	error
+}
+
+// Reads, spends or mines with the node's wallet, or tells the network
+// something in the node's name, so needs the token
+func private(h handler) handler {
+	return func(g *Gateway, r *http.Request) (interface{}, error) {
+		if g.token == "" {
+			return nil, statusError{http.StatusForbidden, fmt.Errorf("%s is off, the node needs a rest token", r.URL.Path)}
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(g.token)) != 1 {
+			return nil, statusError{http.StatusUnauthorized, errors.New("Need the node's rest token as a bearer token")}
+		}
+		return h(g, r)
+	}
+}
+
+// Private routes need token as a bearer token, and are off if it's empty
+func NewGateway(conn *grpc.ClientConn, params *chain.ChainParams, token string) *Gateway {
+	g := &Gateway{conn: conn, params: params, token: token, mux: http.NewServeMux()}
+	for _, rt := range routes {
+		handler := rt.handler
+		method := rt.method
+		g.mux.HandleFunc(rt.method+" "+rt.path, func(w http.ResponseWriter, r *http.Request) {
+			if method == "POST" {
+				if err := checkJSON(r); err != nil {
+					g.write(w, nil, err)
+					return
+				}
+			}
+			response, err := handler(g, r)
+			g.write(w, response, err)
+		})
+	}
+	g.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	return g
+}
+
+// Connection to the node at grpcAddress, for NewGateway
+func Dial(grpcAddress string) (*grpc.ClientConn, error) {
+	// Just a port is this host
+	if strings.HasPrefix(grpcAddress, ":") {
+		grpcAddress = "localhost" + grpcAddress
+	}
+	return grpc.Dial(grpcAddress, grpc.WithInsecure())
+}
+
+// Serve something else alongside the API, e.g. the explorer
+func (g *Gateway) Mount(pattern string, handler http.Handler) {
+	g.mux.Handle(pattern, handler)
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Browser tools are usually served from somewhere else, but only get
+	// to read. Without these a browser won't send another origin's POST
+	// with a JSON body at all
+	switch r.Method {
+	case "GET", "HEAD":
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	case "OPTIONS":
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	g.mux.ServeHTTP(w, r)
+}
+
+// HTTP status for an error from the node
+func httpStatus(err error) int {
+	if _, ok := err.(requestError); ok {
+		return http.StatusBadRequest
+	}
+	if e, ok := err.(statusError); ok {
+		return e.status
+	}
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return http.StatusServiceUnavailable
+	case codes.Internal, codes.Unimplemented:
+		return http.StatusInternalServerError
+	}
+	// The node rejecting what it was asked to do, e.g. not enough coin
+	return http.StatusBadRequest
+}
+
+func (g *Gateway) write(w http.ResponseWriter, response interface{}, err error) {
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(httpStatus(err))
+		message := err.Error()
+		if s, ok := status.FromError(err); ok {
+			message = s.Message()
+		}
+		json.NewEncoder(w).Encode(Error{message})
+		return
+	}
+	if encoded, ok := response.(hexResponse); ok {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintln(w, encoded)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(response)
+}
+
+// offset and limit from the query, the node picks the default limit
+func readPage(r *http.Request) (*pb.Page, error) {
+	var page pb.Page
+	query := r.URL.Query()
+	if offset := query.Get("offset"); offset != "" {
+		n, err := strconv.ParseUint(offset, 10, 64)
+		if err != nil {
+			return nil, badRequest("Invalid offset %q", offset)
+		}
+		page.Offset = n
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil {
+			return nil, badRequest("Invalid limit %q", limit)
+		}
+		page.Limit = uint32(n)
+	}
+	return &page, nil
+}
+
+// A path parameter and whether it asked for hex
+func hexPathValue(r *http.Request, name string) (string, bool) {
+	return strings.CutSuffix(r.PathValue(name), HEX_SUFFIX)
+}
+
+// Forms and other simple requests can be sent cross origin without asking
+func checkJSON(r *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return statusError{http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json")}
+	}
+	return nil
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MAX_BODY))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && err != io.EOF {
+		return badRequest("Invalid request body: %v", err)
+	}
+	return nil
+}
+
+// Serialized bytes, as {"hex": ...}
+func readEncoded(r *http.Request) ([]byte, error) {
+	var encoded Encoded
+	if err := readJSON(r, &encoded); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(encoded.Hex))
+	if err != nil || len(data) == 0 {
+		return nil, badRequest("Need the serialized bytes in hex")
+	}
+	return data, nil
+}
+
+func decodeHash(id string) ([]byte, error) {
+	hash, err := hex.DecodeString(id)
+	if err != nil || len(hash) != 32 {
+		return nil, badRequest("Invalid hash %q, need 64 hex characters", id)
+	}
+	return hash, nil
+}
+
+// A key's address, or a script address e.g. multisig
+func (g *Gateway) readRecipient(address string, amount uint64) (*pb.TXO, error) {
+	if chain.IsScriptAddress(address) {
+		script, err := chain.GetScriptFromAddress(address)
+		if err != nil {
+			return nil, requestError{err}
+		}
+		return &pb.TXO{LockingScript: script, Value: amount}, nil
+	}
+	pubKey, err := g.params.GetPubKeyFromAddress(address)
+	if err != nil {
+		return nil, requestError{err}
+	}
+	return &pb.TXO{ReceiverPubKey: pubKey, Value: amount}, nil
+}
+
+func (g *Gateway) listBlocks(r *http.Request) (interface{}, error) {
+	page, err := readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := pb.NewStateClient(g.conn).ListBlocks(r.Context(), page)
+	if err != nil {
+		return nil, err
+	}
+	response := BlockPage{Total: blocks.Total, Offset: page.Offset, Blocks: []Block{}}
+	for _, block := range blocks.Blocks {
+		response.Blocks = append(response.Blocks, NewBlock(g.params, block))
+	}
+	return response, nil
+}
+
+// By hash, or by height since hashes are always 64 characters
+func (g *Gateway) getBlock(r *http.Request) (interface{}, error) {
+	id, asHex := hexPathValue(r, "id")
+	var query pb.BlockQuery
+	if height, err := strconv.ParseUint(id, 10, 64); err == nil && len(id) < 64 {
+		query.Height = height
+	} else if query.Hash, err = decodeHash(id); err != nil {
+		return nil, err
+	}
+	block, err := pb.NewStateClient(g.conn).GetBlock(r.Context(), &query)
+	if err != nil {
+		return nil, err
+	}
+	if asHex {
+		return hexResponse(hex.EncodeToString(serialize.EncodeBlock(block))), nil
+	}
+	return NewBlock(g.params, block), nil
+}
+
+// A block mined elsewhere, handled as if a peer sent it
+func (g *Gateway) receiveBlock(r *http.Request) (interface{}, error) {
+	data, err := readEncoded(r)
+	if err != nil {
+		return nil, err
+	}
+	block, err := serialize.DecodeBlock(data)
+	if err != nil {
+		return nil, requestError{err}
+	}
+	if _, err := pb.NewBlocksClient(g.conn).ReceiveBlock(r.Context(), block); err != nil {
+		return nil, err
+	}
+	// Invalid blocks are dropped without an error, so look for it
+	hash := chain.GetBlockHash(block)
+	if _, err := pb.NewStateClient(g.conn).GetBlock(r.Context(), &pb.BlockQuery{Hash: hash}); err != nil {
+		return nil, badRequest("Block %x was rejected, it is invalid or not on our tip", hash)
+	}
+	return BlockHash{hex.EncodeToString(hash)}, nil
+}
+
+func (g *Gateway) getTransaction(r *http.Request) (interface{}, error) {
+	id, asHex := hexPathValue(r, "id")
+	txID, err := decodeHash(id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := pb.NewStateClient(g.conn).GetTransactionInfo(r.Context(), &pb.TransactionQuery{TxID: txID})
+	if err != nil {
+		return nil, err
+	}
+	if asHex {
+		return hexResponse(hex.EncodeToString(serialize.EncodeTransaction(info.Transaction))), nil
+	}
+	return NewTransactionInfo(g.params, info), nil
+}
+
+func (g *Gateway) broadcastTransaction(r *http.Request) (interface{}, error) {
+	data, err := readEncoded(r)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := serialize.DecodeTransaction(data)
+	if err != nil {
+		return nil, requestError{err}
+	}
+	sent, err := pb.NewTransactionsClient(g.conn).BroadcastTransaction(r.Context(), tx)
+	if err != nil {
+		return nil, err
+	}
+	return NewSent(sent), nil
+}
+
+func (g *Gateway) getMempool(r *http.Request) (interface{}, error) {
+	page, err := readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := pb.NewStateClient(g.conn).GetTransactions(r.Context(), &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	response := TransactionPage{Offset: page.Offset, Transactions: []Transaction{}}
+	limit := uint64(page.Limit)
+	for {
+		tx, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if response.Total >= page.Offset && (limit == 0 || response.Total < page.Offset+limit) {
+			response.Transactions = append(response.Transactions, NewTransaction(g.params, tx))
+		}
+		response.Total++
+	}
+	return response, nil
+}
+
+func (g *Gateway) getUTXOs(r *http.Request) (interface{}, error) {
+	page, err := readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := pb.NewStateClient(g.conn).GetAddressUTXOs(r.Context(),
+		&pb.AddressQuery{Address: r.PathValue("address"), Page: page})
+	if err != nil {
+		return nil, err
+	}
+	response := UTXOPage{Total: utxos.Total, Offset: page.Offset, Balance: utxos.Balance, UTXOs: []UTXO{}}
+	for _, utxo := range utxos.Utxos {
+		response.UTXOs = append(response.UTXOs, NewUTXO(g.params, utxo))
+	}
+	return response, nil
+}
+
+func (g *Gateway) getHistory(r *http.Request) (interface{}, error) {
+	page, err := readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	history, err := pb.NewStateClient(g.conn).GetAddressHistory(r.Context(),
+		&pb.AddressQuery{Address: r.PathValue("address"), Page: page})
+	if err != nil {
+		return nil, err
+	}
+	return NewAddressHistory(history, page.Offset), nil
+}
+
+func (g *Gateway) getAddressBalance(r *http.Request) (interface{}, error) {
+	balance, err := pb.NewStateClient(g.conn).GetAddressBalance(r.Context(), &pb.AddressQuery{Address: r.PathValue("address")})
+	if err != nil {
+		return nil, err
+	}
+	return AddressBalance{balance.Balance, balance.Utxos, balance.Received, balance.Sent, balance.Transactions}, nil
+}
+
+func (g *Gateway) getPeers(r *http.Request) (interface{}, error) {
+	peers, err := pb.NewPeeringClient(g.conn).GetPeers(r.Context(), &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	response := []Peer{}
+	for _, peer := range peers.Peers {
+		response = append(response, Peer{peer})
+	}
+	return response, nil
+}
+
+func (g *Gateway) newAccount(r *http.Request) (interface{}, error) {
+	var req AccountRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	account, err := pb.NewWalletClient(g.conn).NewAccount(r.Context(), &pb.Account{Name: req.Name})
+	if err != nil {
+		return nil, err
+	}
+	return Address{account.Address}, nil
+}
+
+func (g *Gateway) getAddress(r *http.Request) (interface{}, error) {
+	account, err := pb.NewWalletClient(g.conn).GetAddress(r.Context(), &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return Address{account.Address}, nil
+}
+
+func (g *Gateway) getBalance(r *http.Request) (interface{}, error) {
+	balance, err := pb.NewWalletClient(g.conn).GetBalance(r.Context(), &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return Balance{balance.Balance, balance.Spendable}, nil
+}
+
+func (g *Gateway) getWalletHistory(r *http.Request) (interface{}, error) {
+	page, err := readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	history, err := pb.NewWalletClient(g.conn).GetHistory(r.Context(), page)
+	if err != nil {
+		return nil, err
+	}
+	return NewAddressHistory(history, page.Offset), nil
+}
+
+func (g *Gateway) getMultisig(r *http.Request) (interface{}, error) {
+	stream, err := pb.NewWalletClient(g.conn).GetMultisigAddresses(r.Context(), &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	addresses := []Multisig{}
+	for {
+		address, err := stream.Recv()
+		if err == io.EOF {
+			return addresses, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, NewMultisig(g.params, address))
+	}
+}
+
+func (g *Gateway) send(r *http.Request) (interface{}, error) {
+	var req SendRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	recipients := req.Recipients
+	if req.Address != "" {
+		recipients = append(recipients, Recipient{req.Address, req.Amount})
+	}
+	if len(recipients) == 0 {
+		return nil, badRequest("Need an address or recipients to pay")
+	}
+	request := &pb.TransactionRequest{Fee: req.Fee, FeeRate: req.FeeRate, Strategy: req.Strategy, LockTime: req.LockTime}
+	for _, recipient := range recipients {
+		output, err := g.readRecipient(recipient.Address, recipient.Amount)
+		if err != nil {
+			return nil, err
+		}
+		request.Outputs = append(request.Outputs, output)
+	}
+	sent, err := pb.NewTransactionsClient(g.conn).SendTransaction(r.Context(), request)
+	if err != nil {
+		return nil, err
+	}
+	return NewSent(sent), nil
+}
+
+func (g *Gateway) getMiningStatus(r *http.Request) (interface{}, error) {
+	s, err := pb.NewMinerClient(g.conn).GetMiningStatus(r.Context(), &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return MiningStatus{s.State, s.BlocksMined, s.StartTime, s.Height, hex.EncodeToString(s.Tip)}, nil
+}
+
+func (g *Gateway) startMining(r *http.Request) (interface{}, error) {
+	if _, err := pb.NewMinerClient(g.conn).StartMining(r.Context(), &pb.Empty{}); err != nil {
+		return nil, err
+	}
+	return g.getMiningStatus(r)
+}
+
+func (g *Gateway) stopMining(r *http.Request) (interface{}, error) {
+	if _, err := pb.NewMinerClient(g.conn).StopMining(r.Context(), &pb.Empty{}); err != nil {
+		return nil, err
+	}
+	return g.getMiningStatus(r)
+}
+
+func (g *Gateway) generate(r *http.Request) (interface{}, error) {
+	var req GenerateRequest
+	if err := readJSON(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Blocks == 0 {
+		return nil, badRequest("Need a number of blocks")
+	}
+	generated, err := pb.NewMinerClient(g.conn).GenerateBlocks(r.Context(),
+		&pb.GenerateRequest{Blocks: req.Blocks, Address: req.Address})
+	if err != nil {
+		return nil, err
+	}
+	response := Generated{Hashes: []string{}}
+	for _, hash := range generated.Hashes {
+		response.Hashes = append(response.Hashes, hex.EncodeToString(hash))
+	}
+	return response, nil
+}
@@ -0,0 +1,337 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"github.com/connorwstein/Blockchain/bitcoin/nodetest"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/connorwstein/Blockchain/bitcoin/serialize"
+	"github.com/connorwstein/Blockchain/bitcoin/wallet"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const TEST_TOKEN = "s3cret"
+
+// Request with just the headers given
+func request(t *testing.T, server *httptest.Server, method string, path string, body string, header map[string]string) *http.Response {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// Request against the gateway as a trusted JSON client, decoding the
+// JSON response into v if given
+func call(t *testing.T, server *httptest.Server, method string, path string, body string, v interface{}) int {
+	resp := request(t, server, method, path, body, map[string]string{"Content-Type": "application/json",
+		"Authorization": "Bearer " + TEST_TOKEN})
+	defer resp.Body.Close()
+	contents, _ := ioutil.ReadAll(resp.Body)
+	if v != nil && resp.StatusCode == http.StatusOK {
+		if s, ok := v.(*string); ok {
+			*s = strings.TrimSpace(string(contents))
+		} else if err := json.Unmarshal(contents, v); err != nil {
+			t.Fatalf("%s %s returned %s: %v", method, path, contents, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestGateway(t *testing.T) {
+	network := nodetest.NewNetwork(t, nodetest.Line(2))
+	n := network.Nodes[0]
+	server := httptest.NewServer(NewGateway(n.Client(), n.Blockchain.Params, TEST_TOKEN))
+	defer server.Close()
+	network.Mine(0, 3)
+	height := n.Height()
+
+	// Newest first, in pages
+	var page BlockPage
+	if code := call(t, server, "GET", "/blocks?limit=2", "", &page); code != http.StatusOK {
+		t.Fatalf("List blocks returned %d", code)
+	}
+	if page.Total != height || len(page.Blocks) != 2 || page.Blocks[0].Height != height || page.Blocks[1].Height != height-1 {
+		t.Errorf("First page is %+v", page)
+	}
+	call(t, server, "GET", "/blocks?offset=2&limit=10", "", &page)
+	if len(page.Blocks) != int(height)-2 || page.Blocks[len(page.Blocks)-1].Hash != hex.EncodeToString(chain.REGTEST.GenesisHash()) {
+		t.Errorf("Last page should end at genesis, got %+v", page)
+	}
+	if call(t, server, "GET", "/blocks?limit=x", "", nil) != http.StatusBadRequest {
+		t.Error("Invalid limit should be a bad request")
+	}
+
+	// By hash, height or as hex
+	tipHash := hex.EncodeToString(chain.GetBlockHash(n.Tip()))
+	var byHash, byHeight Block
+	call(t, server, "GET", "/blocks/"+tipHash, "", &byHash)
+	call(t, server, "GET", "/blocks/"+strconv.FormatUint(height, 10), "", &byHeight)
+	if byHash.Hash != tipHash || !reflect.DeepEqual(byHash, byHeight) {
+		t.Errorf("Block by hash %+v and by height %+v", byHash, byHeight)
+	}
+	var encoded string
+	call(t, server, "GET", "/blocks/"+tipHash+".hex", "", &encoded)
+	data, _ := hex.DecodeString(encoded)
+	if block, err := serialize.DecodeBlock(data); err != nil || !bytes.Equal(chain.GetBlockHash(block), chain.GetBlockHash(n.Tip())) {
+		t.Errorf("Hex block doesn't decode to the tip, %v", err)
+	}
+	if code := call(t, server, "GET", "/blocks/"+strings.Repeat("0", 64), "", nil); code != http.StatusNotFound {
+		t.Errorf("Unknown block returned %d", code)
+	}
+	if code := call(t, server, "GET", "/blocks/nope", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Invalid block id returned %d", code)
+	}
+
+	// A transaction signed elsewhere, spending a coinbase of node 0
+	coinbase := n.Tip().Transactions[0]
+	tx := &pb.Transaction{Vin: []*pb.TXI{{TxID: chain.GetTransactionHash(coinbase), Index: 0}},
+		Vout: []*pb.TXO{{ReceiverPubKey: chain.GetPubKeyBytes(network.Nodes[1].Wallet.Key), Value: coinbase.Vout[0].Value - 1}}}
+	wallet.SignTransaction(tx, n.Wallet.Key)
+	var sent Sent
+	body := `{"hex": "` + hex.EncodeToString(serialize.EncodeTransaction(tx)) + `"}`
+	if code := call(t, server, "POST", "/tx", body, &sent); code != http.StatusOK || sent.Fee != 1 {
+		t.Fatalf("Broadcast returned %d %+v", code, sent)
+	}
+	if !network.WaitForTransaction(chain.GetTransactionHash(tx)) {
+		t.Error("Broadcast transaction didn't reach the other node")
+	}
+	var info TransactionInfo
+	call(t, server, "GET", "/tx/"+sent.TxID, "", &info)
+	if info.TxID != sent.TxID || info.BlockHash != "" || info.Confirmations != 0 {
+		t.Errorf("Unconfirmed transaction is %+v", info)
+	}
+	var mempool TransactionPage
+	call(t, server, "GET", "/mempool", "", &mempool)
+	if mempool.Total != 1 || mempool.Transactions[0].TxID != sent.TxID {
+		t.Errorf("Mempool is %+v", mempool)
+	}
+	// Not twice once it's confirmed
+	var generated Generated
+	if code := call(t, server, "POST", "/mining/generate", `{"blocks": 1}`, &generated); code != http.StatusOK || len(generated.Hashes) != 1 {
+		t.Fatalf("Generate returned %d %+v", code, generated)
+	}
+	if code := call(t, server, "POST", "/tx", body, nil); code != http.StatusBadRequest {
+		t.Errorf("Confirmed transaction broadcast again returned %d", code)
+	}
+	call(t, server, "GET", "/tx/"+sent.TxID, "", &info)
+	if info.BlockHash != generated.Hashes[0] || info.BlockHeight != height+1 || info.Confirmations != 1 {
+		t.Errorf("Confirmed transaction is %+v", info)
+	}
+	if code := call(t, server, "POST", "/tx", `{"hex": "zz"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("Invalid hex returned %d", code)
+	}
+
+	// Pay from the wallet then find it in the recipient's UTXOs
+	address := network.Nodes[1].Address()
+	if code := call(t, server, "POST", "/wallet/send", `{"address": "`+address+`", "amount": 3}`, &sent); code != http.StatusOK {
+		t.Fatalf("Send returned %d", code)
+	}
+	if code := call(t, server, "POST", "/wallet/send", `{"address": "`+address+`", "amount": 100000}`, nil); code != http.StatusBadRequest {
+		t.Errorf("Sending more than the balance returned %d", code)
+	}
+	// A mainnet address is valid hex when it's an even number of digits, but
+	// not a script to pay
+	mainnet := chain.MAINNET.GetAddress(chain.GetPubKeyBytes(network.Nodes[1].Wallet.Key))
+	for _, to := range []string{mainnet, mainnet + "0", "script:zz"} {
+		if code := call(t, server, "POST", "/wallet/send", `{"address": "`+to+`", "amount": 3}`, nil); code != http.StatusBadRequest {
+			t.Errorf("Sending to %s returned %d", to, code)
+		}
+	}
+	multisig := chain.GetScriptAddress(chain.MultisigScript(1, [][]byte{chain.GetPubKeyBytes(network.Nodes[1].Wallet.Key)}))
+	if code := call(t, server, "POST", "/wallet/send", `{"address": "`+multisig+`", "amount": 3}`, nil); code != http.StatusOK {
+		t.Errorf("Sending to %s returned %d", multisig, code)
+	}
+	network.Mine(0, 1)
+	var utxos UTXOPage
+	call(t, server, "GET", "/address/"+address+"/utxos?limit=1", "", &utxos)
+	if utxos.Total != 2 || utxos.Balance != coinbase.Vout[0].Value-1+3 || len(utxos.UTXOs) != 1 || utxos.UTXOs[0].TxID != sent.TxID {
+		t.Errorf("UTXOs of %s are %+v", address, utxos)
+	}
+	var history AddressHistory
+	call(t, server, "GET", "/address/"+address+"/history", "", &history)
+	if history.Total != 2 || history.Received != utxos.Balance || history.Transactions[0].TxID != sent.TxID ||
+		history.Transactions[0].Confirmations != 1 {
+		t.Errorf("History of %s is %+v", address, history)
+	}
+	var addressBalance AddressBalance
+	call(t, server, "GET", "/address/"+address+"/balance", "", &addressBalance)
+	if addressBalance.Balance != utxos.Balance || addressBalance.UTXOs != 2 || addressBalance.Transactions != 2 {
+		t.Errorf("Balance of %s is %+v", address, addressBalance)
+	}
+	var peers []Peer
+	if call(t, server, "GET", "/peers", "", &peers); len(peers) != 1 {
+		t.Errorf("Peers are %+v", peers)
+	}
+	if code := call(t, server, "GET", "/address/nope/utxos", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Invalid address returned %d", code)
+	}
+
+	var status MiningStatus
+	call(t, server, "GET", "/mining/status", "", &status)
+	if status.Height != n.Height() || status.Tip != hex.EncodeToString(chain.GetBlockHash(n.Tip())) {
+		t.Errorf("Mining status is %+v", status)
+	}
+	var balance Balance
+	call(t, server, "GET", "/wallet/balance", "", &balance)
+	if balance.Balance != n.Balance(n.Address()) {
+		t.Errorf("Balance is %+v", balance)
+	}
+	// The wallet paid the send
+	var walletHistory AddressHistory
+	call(t, server, "GET", "/wallet/history?limit=5", "", &walletHistory)
+	if len(walletHistory.Transactions) != 5 || walletHistory.Total <= 5 || walletHistory.Sent == 0 {
+		t.Errorf("Wallet history is %+v", walletHistory)
+	}
+}
+
+// Other origins can only read, and only someone with the token can spend
+func TestGatewayAccess(t *testing.T) {
+	network := nodetest.NewNetwork(t, nodetest.Line(1))
+	n := network.Nodes[0]
+	server := httptest.NewServer(NewGateway(n.Client(), n.Blockchain.Params, TEST_TOKEN))
+	defer server.Close()
+	jsonHeader := map[string]string{"Content-Type": "application/json"}
+	resp := request(t, server, "GET", "/peers", "", nil)
+	resp.Body.Close()
+	if resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("GET should be readable from other origins")
+	}
+	preflight := request(t, server, "OPTIONS", "/mining/generate", "", map[string]string{"Origin": "http://example.com",
+		"Access-Control-Request-Method": "POST"})
+	preflight.Body.Close()
+	if methods := preflight.Header.Get("Access-Control-Allow-Methods"); strings.Contains(methods, "POST") {
+		t.Errorf("Preflight allows %s", methods)
+	}
+	for _, test := range []struct {
+		path   string
+		header map[string]string
+		status int
+	}{
+		// A form post from another page
+		{"/mining/generate", map[string]string{"Content-Type": "application/x-www-form-urlencoded",
+			"Authorization": "Bearer " + TEST_TOKEN}, http.StatusUnsupportedMediaType},
+		{"/blocks", map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"/mining/generate", jsonHeader, http.StatusUnauthorized},
+		{"/mining/generate", map[string]string{"Content-Type": "application/json",
+			"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"/wallet/send", jsonHeader, http.StatusUnauthorized},
+		{"/tx", jsonHeader, http.StatusUnauthorized},
+		// Public, so gets as far as the bad block
+		{"/blocks", map[string]string{"Content-Type": "application/json; charset=utf-8"}, http.StatusBadRequest},
+	} {
+		resp := request(t, server, "POST", test.path, `{"blocks": 1}`, test.header)
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("POST %s with %v returned %d, want %d", test.path, test.header, resp.StatusCode, test.status)
+		}
+		if resp.Header.Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("POST %s has CORS headers", test.path)
+		}
+	}
+	if n.Height() != 1 {
+		t.Errorf("Mined without the token, at height %d", n.Height())
+	}
+	// Nor is the wallet readable without it
+	for _, path := range []string{"/wallet/address", "/wallet/balance", "/wallet/history", "/wallet/multisig"} {
+		resp := request(t, server, "GET", path, "", nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("GET %s without the token returned %d", path, resp.StatusCode)
+		}
+	}
+	// No token turns them off altogether
+	open := httptest.NewServer(NewGateway(n.Client(), n.Blockchain.Params, ""))
+	defer open.Close()
+	if code := call(t, open, "POST", "/mining/generate", `{"blocks": 1}`, nil); code != http.StatusForbidden {
+		t.Errorf("Generate without a gateway token returned %d", code)
+	}
+	if code := call(t, open, "GET", "/mining/status", "", nil); code != http.StatusOK {
+		t.Errorf("Status without a gateway token returned %d", code)
+	}
+}
+
+// Names of the JSON fields of a struct, including embedded ones
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+		if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// Every route is documented and every schema has the same fields as
+// what the gateway actually sends and takes
+func TestOpenAPI(t *testing.T) {
+	var doc struct {
+		Paths      map[string]map[string]interface{}
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]interface{}
+			}
+		}
+	}
+	if err := json.Unmarshal(openAPI, &doc); err != nil {
+		t.Fatal(err)
+	}
+	operations := 0
+	for _, rt := range routes {
+		if _, ok := doc.Paths[rt.path][strings.ToLower(rt.method)]; !ok {
+			t.Errorf("%s %s isn't in openapi.json", rt.method, rt.path)
+		}
+	}
+	for _, methods := range doc.Paths {
+		operations += len(methods)
+	}
+	if operations != len(routes) {
+		t.Errorf("openapi.json has %d operations, the gateway %d", operations, len(routes))
+	}
+	types := []interface{}{Input{}, Output{}, Transaction{}, Block{}, TransactionInfo{}, UTXO{}, AddressTransaction{}, AddressHistory{}, AddressBalance{},
+		Peer{}, BlockPage{},
+		UTXOPage{}, TransactionPage{}, Balance{}, Address{}, MiningStatus{}, BlockHash{}, Generated{}, Sent{},
+		Multisig{}, Error{}, Encoded{}, AccountRequest{}, Recipient{}, SendRequest{}, GenerateRequest{}}
+	if len(types) != len(doc.Components.Schemas) {
+		t.Errorf("openapi.json has %d schemas, want %d", len(doc.Components.Schemas), len(types))
+	}
+	for _, v := range types {
+		typ := reflect.TypeOf(v)
+		schema, ok := doc.Components.Schemas[typ.Name()]
+		if !ok {
+			t.Errorf("No schema for %s", typ.Name())
+			continue
+		}
+		var documented []string
+		for name := range schema.Properties {
+			documented = append(documented, name)
+		}
+		fields := jsonFields(typ)
+		sort.Strings(documented)
+		sort.Strings(fields)
+		if !reflect.DeepEqual(documented, fields) {
+			t.Errorf("Schema %s has %v, the type has %v", typ.Name(), documented, fields)
+		}
+	}
+}
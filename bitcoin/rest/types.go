@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"encoding/hex"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+)
+
+// JSON the gateway sends and takes. Names are the same as the client's
+// -output=json so scripts can use either, and the same as openapi.json.
+// Add fields but don't rename or remove them
+
+type Input struct {
+	TxID            string `json:"txid"`
+	Index           uint64 `json:"index"`
+	Sequence        uint64 `json:"sequence"`
+	UnlockingScript string `json:"unlockingScript,omitempty"` // Hex
+}
+
+// Pays either a key, with address and pubKey set, or a locking script
+type Output struct {
+	Index     int    `json:"index"`
+	Value     uint64 `json:"value"`
+	Address   string `json:"address,omitempty"`
+	PubKey    string `json:"pubKey,omitempty"`
+	Script    string `json:"script,omitempty"` // Opcodes and data
+	ScriptHex string `json:"scriptHex,omitempty"`
+}
+
+type Transaction struct {
+	TxID      string   `json:"txid"`
+	Coinbase  bool     `json:"coinbase"`
+	Height    uint64   `json:"height"` // Only set on coinbase transactions
+	LockTime  uint64   `json:"lockTime"`
+	Value     uint64   `json:"value"` // Of all the outputs
+	Inputs    []Input  `json:"inputs"`
+	Outputs   []Output `json:"outputs"`
+	Signature string   `json:"signature,omitempty"`
+}
+
+type Block struct {
+	Hash         string        `json:"hash"`
+	Height       uint64        `json:"height"`
+	PrevHash     string        `json:"prevHash"`
+	MerkleRoot   string        `json:"merkleRoot"`
+	Time         uint64        `json:"time"` // Seconds from epoch
+	Difficulty   uint32        `json:"difficulty"`
+	Nonce        uint32        `json:"nonce"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// A transaction and where it is, blockHash is empty while it's unconfirmed
+type TransactionInfo struct {
+	Transaction
+	BlockHash     string `json:"blockHash"`
+	BlockHeight   uint64 `json:"blockHeight"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+type UTXO struct {
+	TxID string `json:"txid"`
+	Output
+	Height   uint64 `json:"height"` // Of the block it confirmed in
+	Coinbase bool   `json:"coinbase"`
+}
+
+// What one transaction paid to and spent from an address
+type AddressTransaction struct {
+	TxID          string `json:"txid"`
+	Height        uint64 `json:"height"` // 0 while in the mempool
+	BlockHash     string `json:"blockHash"`
+	Confirmations uint64 `json:"confirmations"`
+	Time          uint64 `json:"time"` // Of the block
+	Received      uint64 `json:"received"`
+	Sent          uint64 `json:"sent"`
+}
+
+type AddressHistory struct {
+	Total        uint64               `json:"total"`
+	Offset       uint64               `json:"offset"`
+	Received     uint64               `json:"received"` // Over the whole history, not just this page
+	Sent         uint64               `json:"sent"`
+	Transactions []AddressTransaction `json:"transactions"`
+}
+
+// Of the confirmed transactions
+type AddressBalance struct {
+	Balance      uint64 `json:"balance"`
+	UTXOs        uint64 `json:"utxos"`
+	Received     uint64 `json:"received"`
+	Sent         uint64 `json:"sent"`
+	Transactions uint64 `json:"transactions"`
+}
+
+type Peer struct {
+	Address string `json:"address"` // host:port
+}
+
+type BlockPage struct {
+	Total  uint64  `json:"total"`
+	Offset uint64  `json:"offset"`
+	Blocks []Block `json:"blocks"`
+}
+
+type UTXOPage struct {
+	Total   uint64 `json:"total"`
+	Offset  uint64 `json:"offset"`
+	Balance uint64 `json:"balance"` // Of every UTXO, not just this page
+	UTXOs   []UTXO `json:"utxos"`
+}
+
+type TransactionPage struct {
+	Total        uint64        `json:"total"`
+	Offset       uint64        `json:"offset"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+type Balance struct {
+	Balance   uint64 `json:"balance"`
+	Spendable uint64 `json:"spendable"` // Excludes immature coinbase
+}
+
+type Address struct {
+	Address string `json:"address"`
+}
+
+type MiningStatus struct {
+	State       string `json:"state"`
+	BlocksMined uint64 `json:"blocksMined"`
+	StartTime   uint64 `json:"startTime"` // Seconds from epoch, 0 if never started
+	Height      uint64 `json:"height"`
+	Tip         string `json:"tip"`
+}
+
+type BlockHash struct {
+	Hash string `json:"hash"`
+}
+
+type Generated struct {
+	Hashes []string `json:"hashes"`
+}
+
+type Sent struct {
+	TxID   string  `json:"txid"`
+	Fee    uint64  `json:"fee"`
+	Change uint64  `json:"change"`
+	Inputs []Input `json:"inputs"`
+}
+
+type Multisig struct {
+	Address  string   `json:"address"` // script: then the hex locking script
+	Required uint32   `json:"required"`
+	Keys     []string `json:"keys"` // Addresses
+	Balance  uint64   `json:"balance"`
+}
+
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Request bodies
+
+// A serialized transaction or block, the same as the .hex endpoints return
+type Encoded struct {
+	Hex string `json:"hex"`
+}
+
+type AccountRequest struct {
+	Name string `json:"name"`
+}
+
+type Recipient struct {
+	Address string `json:"address"` // A key's address or script address
+	Amount  uint64 `json:"amount"`
+}
+
+// Pay address, or every recipient in one transaction
+type SendRequest struct {
+	Address    string      `json:"address"`
+	Amount     uint64      `json:"amount"`
+	Recipients []Recipient `json:"recipients"`
+	Fee        uint64      `json:"fee"`     // Absolute, overrides feeRate
+	FeeRate    uint64      `json:"feeRate"` // Per 1000 bytes
+	Strategy   string      `json:"strategy"`
+	LockTime   uint64      `json:"lockTime"`
+}
+
+type GenerateRequest struct {
+	Blocks  uint32 `json:"blocks"`
+	Address string `json:"address"` // The node's wallet if empty
+}
+
+func NewInputs(inputs []*pb.TXI) []Input {
+	converted := make([]Input, 0, len(inputs))
+	for _, input := range inputs {
+		converted = append(converted, Input{hex.EncodeToString(input.TxID), input.Index, input.Sequence,
+			hex.EncodeToString(input.UnlockingScript)})
+	}
+	return converted
+}
+
+func NewOutput(params *chain.ChainParams, index int, output *pb.TXO) Output {
+	converted := Output{Index: index, Value: output.Value}
+	if len(output.LockingScript) != 0 {
+		converted.Script = chain.GetScriptString(output.LockingScript)
+		converted.ScriptHex = hex.EncodeToString(output.LockingScript)
+	} else if len(output.ReceiverPubKey) == 64 {
+		converted.Address = params.GetAddress(output.ReceiverPubKey)
+		converted.PubKey = hex.EncodeToString(output.ReceiverPubKey)
+	}
+	return converted
+}
+
+func NewTransaction(params *chain.ChainParams, tx *pb.Transaction) Transaction {
+	converted := Transaction{
+		TxID:      hex.EncodeToString(chain.GetTransactionHash(tx)),
+		Coinbase:  len(tx.Vin) == 0,
+		Height:    tx.Height,
+		LockTime:  tx.LockTime,
+		Inputs:    NewInputs(tx.Vin),
+		Outputs:   make([]Output, 0, len(tx.Vout)),
+		Signature: hex.EncodeToString(tx.Signature)}
+	for i, output := range tx.Vout {
+		converted.Outputs = append(converted.Outputs, NewOutput(params, i, output))
+		converted.Value += output.Value
+	}
+	return converted
+}
+
+func NewBlock(params *chain.ChainParams, block *pb.Block) Block {
+	converted := Block{
+		Hash:         hex.EncodeToString(chain.GetBlockHash(block)),
+		Height:       block.Header.Height,
+		PrevHash:     hex.EncodeToString(block.Header.PrevBlockHash),
+		MerkleRoot:   hex.EncodeToString(block.Header.MerkleRoot),
+		Time:         block.Header.TimeStamp,
+		Difficulty:   block.Header.DifficultyTarget,
+		Nonce:        block.Header.Nonce,
+		Transactions: make([]Transaction, 0, len(block.Transactions))}
+	for _, tx := range block.Transactions {
+		converted.Transactions = append(converted.Transactions, NewTransaction(params, tx))
+	}
+	return converted
+}
+
+func NewTransactionInfo(params *chain.ChainParams, info *pb.TransactionInfo) TransactionInfo {
+	return TransactionInfo{
+		Transaction:   NewTransaction(params, info.Transaction),
+		BlockHash:     hex.EncodeToString(info.BlockHash),
+		BlockHeight:   info.Height,
+		Confirmations: info.Confirmations}
+}
+
+func NewUTXO(params *chain.ChainParams, utxo *pb.UTXO) UTXO {
+	return UTXO{
+		TxID:     hex.EncodeToString(utxo.TxID),
+		Output:   NewOutput(params, int(utxo.Index), utxo.Output),
+		Height:   utxo.Height,
+		Coinbase: utxo.Coinbase}
+}
+
+func NewAddressTransaction(entry *pb.AddressTransaction) AddressTransaction {
+	return AddressTransaction{
+		TxID:          hex.EncodeToString(entry.TxID),
+		Height:        entry.Height,
+		BlockHash:     hex.EncodeToString(entry.BlockHash),
+		Confirmations: entry.Confirmations,
+		Time:          entry.Time,
+		Received:      entry.Received,
+		Sent:          entry.Sent}
+}
+
+func NewAddressHistory(history *pb.AddressHistory, offset uint64) AddressHistory {
+	converted := AddressHistory{Total: history.Total, Offset: offset, Received: history.Received,
+		Sent: history.Sent, Transactions: []AddressTransaction{}}
+	for _, entry := range history.Transactions {
+		converted.Transactions = append(converted.Transactions, NewAddressTransaction(entry))
+	}
+	return converted
+}
+
+func NewSent(sent *pb.TransactionSent) Sent {
+	return Sent{hex.EncodeToString(sent.TxID), sent.Fee, sent.Change, NewInputs(sent.Inputs)}
+}
+
+func NewMultisig(params *chain.ChainParams, address *pb.MultisigAddress) Multisig {
+	converted := Multisig{Address: address.Address, Required: address.Required, Keys: []string{},
+		Balance: address.Balance}
+	for _, pubKey := range address.PubKeys {
+		converted.Keys = append(converted.Keys, params.GetAddress(pubKey))
+	}
+	return converted
+}
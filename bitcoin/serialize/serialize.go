@@ -0,0 +1,286 @@
+// Canonical binary encoding of transactions and blocks, shared by the
+// node and the client so they always agree on hashes and sizes.
+// Numbers are fixed width little endian and every byte string and
+// list is prefixed with its length, so there is exactly one encoding
+// of each value and no two values share one.
+package serialize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"io"
+)
+
+// Nothing we store comes close, stops a bad length making us allocate
+// gigabytes when reading from disk
+const MAX_RECORD_SIZE = 32 * 1024 * 1024
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) uint32(n uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], n)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) uint64(n uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) bytes(data []byte) {
+	e.uint32(uint32(len(data)))
+	e.buf.Write(data)
+}
+
+// Leaving out the signature and unlocking scripts gives what is hashed,
+// they can't be part of the hash they sign
+func (e *encoder) transaction(tx *pb.Transaction, signed bool) {
+	e.uint32(uint32(len(tx.Vin)))
+	for _, txi := range tx.Vin {
+		if txi == nil {
+			txi = &pb.TXI{}
+		}
+		e.bytes(txi.TxID)
+		e.uint64(txi.Index)
+		if signed {
+			e.bytes(txi.UnlockingScript)
+		}
+		e.uint64(txi.Sequence)
+	}
+	e.uint32(uint32(len(tx.Vout)))
+	for _, txo := range tx.Vout {
+		if txo == nil {
+			txo = &pb.TXO{}
+		}
+		e.bytes(txo.ReceiverPubKey)
+		e.uint64(txo.Value)
+		e.bytes(txo.LockingScript)
+	}
+	if signed {
+		e.bytes(tx.Signature)
+	}
+	e.uint64(tx.Height)
+	e.uint64(tx.LockTime)
+}
+
+func (e *encoder) header(header *pb.BlockHeader) {
+	if header == nil {
+		header = &pb.BlockHeader{}
+	}
+	e.bytes(header.PrevBlockHash)
+	e.bytes(header.MerkleRoot)
+	e.uint64(header.TimeStamp)
+	e.uint32(header.DifficultyTarget)
+	e.uint32(header.Nonce)
+	e.uint64(header.Height)
+}
+
+func EncodeTransaction(tx *pb.Transaction) []byte {
+	var e encoder
+	e.transaction(tx, true)
+	return e.buf.Bytes()
+}
+
+func EncodeBlockHeader(header *pb.BlockHeader) []byte {
+	var e encoder
+	e.header(header)
+	return e.buf.Bytes()
+}
+
+func EncodeBlock(block *pb.Block) []byte {
+	var e encoder
+	e.header(block.Header)
+	e.uint32(uint32(len(block.Transactions)))
+	for _, tx := range block.Transactions {
+		e.bytes(EncodeTransaction(tx))
+	}
+	return e.buf.Bytes()
+}
+
+// Everything but the signatures
+func EncodeTransactionForHash(tx *pb.Transaction) []byte {
+	var e encoder
+	e.transaction(tx, false)
+	return e.buf.Bytes()
+}
+
+func TransactionHash(tx *pb.Transaction) []byte {
+	sum := sha256.Sum256(EncodeTransactionForHash(tx))
+	return sum[:]
+}
+
+// The merkle root in the header commits to the transactions
+func BlockHash(block *pb.Block) []byte {
+	sum := sha256.Sum256(EncodeBlockHeader(block.Header))
+	return sum[:]
+}
+
+// Keeps the first error, later reads return zero values
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data) {
+		d.err = errors.New(fmt.Sprintf("Need %d bytes, only %d left", n, len(d.data)))
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// Empty decodes to nil so encoding it again gives the same bytes
+func (d *decoder) bytes() []byte {
+	n := d.uint32()
+	if n == 0 || d.err != nil {
+		return nil
+	}
+	if uint64(n) > uint64(len(d.data)) {
+		d.err = errors.New(fmt.Sprintf("Length %d is more than the %d bytes left", n, len(d.data)))
+		return nil
+	}
+	return append([]byte(nil), d.next(int(n))...)
+}
+
+// Each item takes at least minSize bytes, which bounds a sane count
+func (d *decoder) count(minSize int) int {
+	n := d.uint32()
+	if d.err == nil && uint64(n)*uint64(minSize) > uint64(len(d.data)) {
+		d.err = errors.New(fmt.Sprintf("Count %d doesn't fit in the %d bytes left", n, len(d.data)))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.data) != 0 {
+		d.err = errors.New(fmt.Sprintf("%d trailing bytes", len(d.data)))
+	}
+	return d.err
+}
+
+func (d *decoder) transaction() *pb.Transaction {
+	var tx pb.Transaction
+	for i, n := 0, d.count(24); i < n && d.err == nil; i++ {
+		var txi pb.TXI
+		txi.TxID = d.bytes()
+		txi.Index = d.uint64()
+		txi.UnlockingScript = d.bytes()
+		txi.Sequence = d.uint64()
+		tx.Vin = append(tx.Vin, &txi)
+	}
+	for i, n := 0, d.count(16); i < n && d.err == nil; i++ {
+		var txo pb.TXO
+		txo.ReceiverPubKey = d.bytes()
+		txo.Value = d.uint64()
+		txo.LockingScript = d.bytes()
+		tx.Vout = append(tx.Vout, &txo)
+	}
+	tx.Signature = d.bytes()
+	tx.Height = d.uint64()
+	tx.LockTime = d.uint64()
+	return &tx
+}
+
+func (d *decoder) header() *pb.BlockHeader {
+	var header pb.BlockHeader
+	header.PrevBlockHash = d.bytes()
+	header.MerkleRoot = d.bytes()
+	header.TimeStamp = d.uint64()
+	header.DifficultyTarget = d.uint32()
+	header.Nonce = d.uint32()
+	header.Height = d.uint64()
+	return &header
+}
+
+func DecodeTransaction(data []byte) (*pb.Transaction, error) {
+	d := decoder{data: data}
+	tx := d.transaction()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func DecodeBlockHeader(data []byte) (*pb.BlockHeader, error) {
+	d := decoder{data: data}
+	header := d.header()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func DecodeBlock(data []byte) (*pb.Block, error) {
+	d := decoder{data: data}
+	var block pb.Block
+	block.Header = d.header()
+	for i, n := 0, d.count(4); i < n && d.err == nil; i++ {
+		tx, err := DecodeTransaction(d.bytes())
+		if err != nil && d.err == nil {
+			d.err = errors.New(fmt.Sprintf("Transaction %d: %v", i, err))
+		}
+		block.Transactions = append(block.Transactions, tx)
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// Blocks on disk are one after the other, each prefixed with its length
+func WriteBlock(w io.Writer, block *pb.Block) error {
+	var e encoder
+	e.bytes(EncodeBlock(block))
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+// Returns io.EOF once there are no more blocks
+func ReadBlock(r io.Reader) (*pb.Block, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(size[:])
+	if n > MAX_RECORD_SIZE {
+		return nil, errors.New(fmt.Sprintf("Block of %d bytes is too big", n))
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return DecodeBlock(data)
+}
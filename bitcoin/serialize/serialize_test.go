@@ -0,0 +1,155 @@
+package serialize
+
+import (
+	"bytes"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"github.com/golang/protobuf/proto"
+	"testing"
+)
+
+func sampleTransaction() *pb.Transaction {
+	return &pb.Transaction{
+		Vin: []*pb.TXI{&pb.TXI{TxID: bytes.Repeat([]byte{1}, 32), Index: 2, UnlockingScript: []byte{0x51}, Sequence: 3},
+			&pb.TXI{TxID: bytes.Repeat([]byte{4}, 32)}},
+		Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: bytes.Repeat([]byte{5}, 64), Value: 6},
+			&pb.TXO{LockingScript: []byte{0x6a, 0x01, 0x07}}},
+		Signature: bytes.Repeat([]byte{8}, 64), Height: 9, LockTime: 10}
+}
+
+func sampleBlock() *pb.Block {
+	return &pb.Block{Header: &pb.BlockHeader{PrevBlockHash: bytes.Repeat([]byte{1}, 32),
+		MerkleRoot: bytes.Repeat([]byte{2}, 32), TimeStamp: 1500000000, Nonce: 3, Height: 4},
+		Transactions: []*pb.Transaction{&pb.Transaction{Vout: []*pb.TXO{&pb.TXO{Value: 10}}, Height: 4}, sampleTransaction()}}
+}
+
+// The signature and unlocking scripts aren't hashed
+func stripSignatures(tx *pb.Transaction) *pb.Transaction {
+	stripped := proto.Clone(tx).(*pb.Transaction)
+	stripped.Signature = nil
+	for _, txi := range stripped.Vin {
+		txi.UnlockingScript = nil
+	}
+	return stripped
+}
+
+func TestRoundTrip(t *testing.T) {
+	tx := sampleTransaction()
+	decoded, err := DecodeTransaction(EncodeTransaction(tx))
+	if err != nil || !proto.Equal(tx, decoded) {
+		t.Errorf("Transaction round trip gave %v %v", decoded, err)
+	}
+	block := sampleBlock()
+	decodedBlock, err := DecodeBlock(EncodeBlock(block))
+	if err != nil || !proto.Equal(block, decodedBlock) {
+		t.Errorf("Block round trip gave %v %v", decodedBlock, err)
+	}
+	var buf bytes.Buffer
+	WriteBlock(&buf, block)
+	WriteBlock(&buf, block)
+	for i := 0; i < 2; i++ {
+		if read, err := ReadBlock(&buf); err != nil || !proto.Equal(block, read) {
+			t.Errorf("Read block %d gave %v", i, err)
+		}
+	}
+	if _, err = ReadBlock(&buf); err == nil {
+		t.Error("Should be nothing left to read")
+	}
+}
+
+// Moving bytes from one field to the next used to give the same hash
+func TestNoCollisions(t *testing.T) {
+	a := &pb.Transaction{Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: []byte{1, 2}, Value: 1}, &pb.TXO{LockingScript: []byte{3}}}}
+	b := &pb.Transaction{Vout: []*pb.TXO{&pb.TXO{ReceiverPubKey: []byte{1}, Value: 1}, &pb.TXO{LockingScript: []byte{2, 3}}}}
+	if bytes.Equal(TransactionHash(a), TransactionHash(b)) {
+		t.Error("Different outputs hash the same")
+	}
+	// Signatures don't change the hash
+	tx := sampleTransaction()
+	if !bytes.Equal(TransactionHash(tx), TransactionHash(stripSignatures(tx))) {
+		t.Error("Signatures should not be part of the hash")
+	}
+	block := sampleBlock()
+	hash := BlockHash(block)
+	block.Header.Nonce++
+	if bytes.Equal(hash, BlockHash(block)) {
+		t.Error("Nonce should change the block hash")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	encoded := EncodeTransaction(sampleTransaction())
+	cases := map[string][]byte{
+		"truncated":    encoded[:len(encoded)-1],
+		"trailing":     append(append([]byte(nil), encoded...), 0),
+		"empty":        nil,
+		"huge count":   {0xff, 0xff, 0xff, 0xff},
+		"huge length":  {1, 0, 0, 0, 0xff, 0xff, 0xff, 0x7f},
+		"zero and one": {0, 0, 0, 0, 1},
+	}
+	for name, data := range cases {
+		if _, err := DecodeTransaction(data); err == nil {
+			t.Errorf("Decoding %s should fail", name)
+		}
+	}
+}
+
+// Anything that decodes encodes back to exactly the same bytes, so there
+// is only one encoding of each transaction
+func FuzzTransaction(f *testing.F) {
+	f.Add(EncodeTransaction(sampleTransaction()))
+	f.Add(EncodeTransaction(&pb.Transaction{}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tx, err := DecodeTransaction(data)
+		if err != nil {
+			return
+		}
+		encoded := EncodeTransaction(tx)
+		if !bytes.Equal(encoded, data) {
+			t.Fatalf("Encoding %x decoded then encoded to %x", data, encoded)
+		}
+		again, err := DecodeTransaction(encoded)
+		if err != nil || !proto.Equal(tx, again) {
+			t.Fatalf("Round trip of %v gave %v %v", tx, again, err)
+		}
+	})
+}
+
+func FuzzBlock(f *testing.F) {
+	f.Add(EncodeBlock(sampleBlock()))
+	f.Add(EncodeBlock(&pb.Block{}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		block, err := DecodeBlock(data)
+		if err != nil {
+			return
+		}
+		encoded := EncodeBlock(block)
+		if !bytes.Equal(encoded, data) {
+			t.Fatalf("Encoding %x decoded then encoded to %x", data, encoded)
+		}
+		again, err := DecodeBlock(encoded)
+		if err != nil || !proto.Equal(block, again) {
+			t.Fatalf("Round trip of %v gave %v %v", block, again, err)
+		}
+	})
+}
+
+// Two transactions only hash the same bytes if they are the same
+// transaction, ignoring signatures
+func FuzzTransactionCollision(f *testing.F) {
+	tx := sampleTransaction()
+	other := sampleTransaction()
+	other.Vout[0].Value++
+	f.Add(EncodeTransaction(tx), EncodeTransaction(other))
+	f.Add(EncodeTransaction(tx), EncodeTransaction(stripSignatures(tx)))
+	f.Fuzz(func(t *testing.T, a []byte, b []byte) {
+		txA, errA := DecodeTransaction(a)
+		txB, errB := DecodeTransaction(b)
+		if errA != nil || errB != nil {
+			return
+		}
+		same := proto.Equal(stripSignatures(txA), stripSignatures(txB))
+		if bytes.Equal(EncodeTransactionForHash(txA), EncodeTransactionForHash(txB)) != same {
+			t.Fatalf("%v and %v equal %v but hash encodings disagree", txA, txB, same)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+// Coin selection, deciding which of our UTXOs fund a transaction.
+// All strategies work with effective values (the value of a UTXO minus
+// the fee needed to spend it) so adding an input can never make a
+// transaction harder to pay for than it looks.
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// Upper bound on the number of nodes branch and bound will visit
+	BNB_MAX_TRIES          = 100000
+	DEFAULT_COIN_SELECTION = "bnb"
+)
+
+var ErrInsufficientFunds = errors.New("Not enough coin to cover amount plus fee")
+
+// The inputs chosen for a transaction, the fee they pay and the change
+// owed back to us (0 means no change output)
+type CoinSelection struct {
+	Inputs []*chain.UTXO
+	Fee    uint64
+	Change uint64
+}
+
+type CoinSelector interface {
+	SelectCoins(utxos []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) (*CoinSelection, error)
+}
+
+func GetCoinSelector(strategy string) (CoinSelector, error) {
+	switch strategy {
+	case "", DEFAULT_COIN_SELECTION:
+		// Exact matches are rare, so fall back to something that always works
+		return branchAndBoundSelector{fallback: largestFirstSelector{}}, nil
+	case "largest":
+		return largestFirstSelector{}, nil
+	case "smallest":
+		return smallestFirstSelector{}, nil
+	case "random":
+		return newRandomImproveSelector(time.Now().UnixNano()), nil
+	default:
+		return nil, errors.New("Unknown coin selection strategy " + strategy)
+	}
+}
+
+// Value of the UTXO after paying for the input which spends it,
+// zero if it is not worth spending at this fee rate
+func effectiveValue(utxo *chain.UTXO, feeRate uint64) uint64 {
+	if utxo.Value() <= chain.InputFee(feeRate) {
+		return 0
+	}
+	return utxo.Value() - chain.InputFee(feeRate)
+}
+
+// Work out the fee and change for a chosen set of inputs paying numOutputs
+// recipients. Change which
+// would be dust is left to the miner instead of creating an output for it.
+// Returns nil if the inputs can't cover the amount and fee.
+func FinishSelection(inputs []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) *CoinSelection {
+	var total uint64
+	for _, utxo := range inputs {
+		total += utxo.Value()
+	}
+	feeNoChange := chain.TransactionFee(len(inputs), numOutputs, feeRate)
+	if total < amount+feeNoChange {
+		return nil
+	}
+	feeWithChange := chain.TransactionFee(len(inputs), numOutputs+1, feeRate)
+	if total >= amount+feeWithChange && !chain.IsDust(total-amount-feeWithChange, feeRate) {
+		return &CoinSelection{Inputs: inputs, Fee: feeWithChange, Change: total - amount - feeWithChange}
+	}
+	return &CoinSelection{Inputs: inputs, Fee: total - amount}
+}
+
+// Add UTXOs in the given order until they cover the amount
+func accumulateCoins(utxos []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) (*CoinSelection, error) {
+	var inputs []*chain.UTXO
+	for _, utxo := range utxos {
+		if effectiveValue(utxo, feeRate) == 0 {
+			continue
+		}
+		inputs = append(inputs, utxo)
+		if selection := FinishSelection(inputs, amount, numOutputs, feeRate); selection != nil {
+			return selection, nil
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
+
+func sortByValue(utxos []*chain.UTXO, descending bool) []*chain.UTXO {
+	sorted := make([]*chain.UTXO, len(utxos))
+	copy(sorted, utxos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Value() > sorted[j].Value()
+		}
+		return sorted[i].Value() < sorted[j].Value()
+	})
+	return sorted
+}
+
+// Fewest inputs, keeps transactions small
+type largestFirstSelector struct{}
+
+func (largestFirstSelector) SelectCoins(utxos []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) (*CoinSelection, error) {
+	return accumulateCoins(sortByValue(utxos, true), amount, numOutputs, feeRate)
+}
+
+// Consolidates small outputs (e.g. mining rewards) at the cost of bigger transactions
+type smallestFirstSelector struct{}
+
+func (smallestFirstSelector) SelectCoins(utxos []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) (*CoinSelection, error) {
+	return accumulateCoins(sortByValue(utxos, false), amount, numOutputs, feeRate)
+}
+
+// Depth first search for a set of inputs which pays the amount and fee
+// without needing a change output, i.e. overshoots by less than it would
+// cost to create and later spend the change. See Murch's "An Evaluation
+// of Coin Selection Strategies" which bitcoin core's implementation follows.
+type branchAndBoundSelector struct {
+	fallback CoinSelector
+}
+
+func (b branchAndBoundSelector) SelectCoins(utxos []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) (*CoinSelection, error) {
+	var candidates []*chain.UTXO
+	var available uint64
+	for _, utxo := range sortByValue(utxos, true) {
+		if effectiveValue(utxo, feeRate) > 0 {
+			candidates = append(candidates, utxo)
+			available += effectiveValue(utxo, feeRate)
+		}
+	}
+	target := amount + chain.TransactionFee(0, numOutputs, feeRate)
+	costOfChange := chain.TransactionFee(0, numOutputs+1, feeRate) - chain.TransactionFee(0, numOutputs, feeRate) + chain.InputFee(feeRate)
+	if available >= target {
+		if inputs := branchAndBound(candidates, target, costOfChange, available, feeRate); inputs != nil {
+			var total uint64
+			for _, utxo := range inputs {
+				total += utxo.Value()
+			}
+			return &CoinSelection{Inputs: inputs, Fee: total - amount}, nil
+		}
+	}
+	if b.fallback == nil {
+		return nil, ErrInsufficientFunds
+	}
+	return b.fallback.SelectCoins(utxos, amount, numOutputs, feeRate)
+}
+
+// Returns the set with the least waste (overshoot) found within BNB_MAX_TRIES,
+// candidates must be sorted by descending value
+func branchAndBound(candidates []*chain.UTXO, target uint64, costOfChange uint64, available uint64, feeRate uint64) []*chain.UTXO {
+	var best []bool
+	var bestWaste uint64
+	selected := make([]bool, len(candidates))
+	var current uint64
+	tries := 0
+	var search func(depth int, remaining uint64)
+	search = func(depth int, remaining uint64) {
+		tries++
+		if tries > BNB_MAX_TRIES || current+remaining < target || current > target+costOfChange {
+			// Can't reach the target down this branch, or already overshot
+			return
+		}
+		if current >= target {
+			if waste := current - target; best == nil || waste < bestWaste {
+				best = append([]bool{}, selected...)
+				bestWaste = waste
+			}
+			return
+		}
+		if depth == len(candidates) {
+			return
+		}
+		value := effectiveValue(candidates[depth], feeRate)
+		// Try including this UTXO first, then excluding it
+		selected[depth] = true
+		current += value
+		search(depth+1, remaining-value)
+		current -= value
+		selected[depth] = false
+		search(depth+1, remaining-value)
+	}
+	search(0, available)
+	if best == nil {
+		return nil
+	}
+	var inputs []*chain.UTXO
+	for i, in := range best {
+		if in {
+			inputs = append(inputs, candidates[i])
+		}
+	}
+	return inputs
+}
+
+// Random selection until the amount is covered, then keep adding random
+// UTXOs while they bring the change closer to the amount being paid.
+// Change of a similar size to payments keeps the UTXO set healthy for
+// future transactions (from the cardano wallet's coin selection).
+type randomImproveSelector struct {
+	rand *rand.Rand
+}
+
+func newRandomImproveSelector(seed int64) randomImproveSelector {
+	return randomImproveSelector{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (r randomImproveSelector) SelectCoins(utxos []*chain.UTXO, amount uint64, numOutputs int, feeRate uint64) (*CoinSelection, error) {
+	shuffled := make([]*chain.UTXO, len(utxos))
+	for i, j := range r.rand.Perm(len(utxos)) {
+		shuffled[i] = utxos[j]
+	}
+	var inputs []*chain.UTXO
+	var selection *CoinSelection
+	next := 0
+	for ; next < len(shuffled) && selection == nil; next++ {
+		if effectiveValue(shuffled[next], feeRate) == 0 {
+			continue
+		}
+		inputs = append(inputs, shuffled[next])
+		selection = FinishSelection(inputs, amount, numOutputs, feeRate)
+	}
+	if selection == nil {
+		return nil, ErrInsufficientFunds
+	}
+	// Improve: aim for change equal to the amount, never more than twice it
+	distance := func(change uint64) uint64 {
+		if change > amount {
+			return change - amount
+		}
+		return amount - change
+	}
+	for ; next < len(shuffled); next++ {
+		if effectiveValue(shuffled[next], feeRate) == 0 {
+			continue
+		}
+		candidate := FinishSelection(append(inputs[:len(inputs):len(inputs)], shuffled[next]), amount, numOutputs, feeRate)
+		if candidate.Change <= 2*amount && distance(candidate.Change) < distance(selection.Change) {
+			inputs = candidate.Inputs
+			selection = candidate
+		}
+	}
+	return selection, nil
+}
+
+// Determine a set of UTXOs which can cover the transaction amount
+// return nil if it is not possible
+func GetUTXOsToCoverTransaction(blockChain chain.Blockchain, key *ecdsa.PrivateKey, desiredAmount uint64) []*chain.UTXO {
+	selection, err := largestFirstSelector{}.SelectCoins(blockChain.GetUTXOs(&key.PublicKey), desiredAmount, 1, 0)
+	if err != nil {
+		// No such utxo
+		return nil
+	}
+	return selection.Inputs
+}
@@ -0,0 +1,105 @@
+package wallet
+
+import (
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"testing"
+)
+
+func makeUTXOs(values ...uint64) []*chain.UTXO {
+	var utxos []*chain.UTXO
+	for i, value := range values {
+		trans := pb.Transaction{Vout: []*pb.TXO{&pb.TXO{Value: value}}, Height: uint64(i)}
+		utxos = append(utxos, &chain.UTXO{Transaction: &trans, Index: 0})
+	}
+	return utxos
+}
+
+func selectionTotal(selection *CoinSelection) uint64 {
+	var total uint64
+	for _, utxo := range selection.Inputs {
+		total += utxo.Value()
+	}
+	return total
+}
+
+func TestCoinSelectionCoversAmount(t *testing.T) {
+	strategies := map[string]CoinSelector{
+		"bnb":      branchAndBoundSelector{fallback: largestFirstSelector{}},
+		"largest":  largestFirstSelector{},
+		"smallest": smallestFirstSelector{},
+		"random":   newRandomImproveSelector(1),
+	}
+	for name, selector := range strategies {
+		for _, feeRate := range []uint64{0, 10, 50} {
+			selection, err := selector.SelectCoins(makeUTXOs(1, 2, 3, 10, 10, 10), 12, 1, feeRate)
+			if err != nil {
+				t.Errorf("%s at fee rate %d failed: %v", name, feeRate, err)
+				continue
+			}
+			total := selectionTotal(selection)
+			if total != 12+selection.Fee+selection.Change {
+				t.Errorf("%s at fee rate %d: inputs %d != amount 12 + fee %d + change %d",
+					name, feeRate, total, selection.Fee, selection.Change)
+			}
+			numOutputs := 1
+			if selection.Change != 0 {
+				numOutputs = 2
+			}
+			if selection.Fee < chain.TransactionFee(len(selection.Inputs), numOutputs, feeRate) {
+				t.Errorf("%s at fee rate %d: fee %d too low", name, feeRate, selection.Fee)
+			}
+		}
+	}
+}
+
+func TestCoinSelectionInsufficientFunds(t *testing.T) {
+	for _, selector := range []CoinSelector{branchAndBoundSelector{fallback: largestFirstSelector{}},
+		largestFirstSelector{}, smallestFirstSelector{}, newRandomImproveSelector(1)} {
+		if _, err := selector.SelectCoins(makeUTXOs(5, 5), 11, 1, 0); err == nil {
+			t.Error("Selection should fail when the amount is more than the balance")
+		}
+		// Enough coin for the amount but not for the fee
+		if _, err := selector.SelectCoins(makeUTXOs(5, 5), 10, 1, 50); err == nil {
+			t.Error("Selection should fail when the fee can't be covered")
+		}
+	}
+}
+
+func TestBranchAndBoundExactMatch(t *testing.T) {
+	selection, err := branchAndBoundSelector{}.SelectCoins(makeUTXOs(10, 10, 10, 4, 3), 7, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selection.Inputs) != 2 || selectionTotal(selection) != 7 || selection.Change != 0 {
+		t.Errorf("Expected 4 + 3 with no change, got %d inputs totalling %d",
+			len(selection.Inputs), selectionTotal(selection))
+	}
+	// No exact match and no fallback
+	if _, err = (branchAndBoundSelector{}).SelectCoins(makeUTXOs(10, 10), 7, 1, 0); err == nil {
+		t.Error("Branch and bound without a fallback should fail when there is no exact match")
+	}
+}
+
+func TestLargestAndSmallestFirst(t *testing.T) {
+	largest, _ := largestFirstSelector{}.SelectCoins(makeUTXOs(1, 2, 3, 10), 4, 1, 0)
+	if len(largest.Inputs) != 1 || largest.Change != 6 {
+		t.Errorf("Largest first should use the 10, got %d inputs", len(largest.Inputs))
+	}
+	smallest, _ := smallestFirstSelector{}.SelectCoins(makeUTXOs(1, 2, 3, 10), 4, 1, 0)
+	if len(smallest.Inputs) != 3 || smallest.Change != 2 {
+		t.Errorf("Smallest first should use 1, 2 and 3, got %d inputs", len(smallest.Inputs))
+	}
+}
+
+func TestDustChangeGoesToFee(t *testing.T) {
+	// At 50 coin/kB spending an input costs 2 and the transaction with
+	// change costs 13 so 25 - 10 - 13 = 2 change which is dust
+	selection, err := largestFirstSelector{}.SelectCoins(makeUTXOs(25), 10, 1, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Change != 0 || selection.Fee != 15 {
+		t.Errorf("Dust change should go to the fee, change %d fee %d", selection.Change, selection.Fee)
+	}
+}
@@ -0,0 +1,105 @@
+// Partially signed transactions, so the keys which own the inputs
+// can sign somewhere other than this node, e.g. an offline machine
+// running the client with a key file. Loosely follows bitcoin's BIP 174:
+// CreateTransaction -> SignTransaction (here or offline) ->
+// CombineTransactions (if signatures were collected separately) ->
+// FinalizeTransaction which broadcasts it
+package wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+)
+
+func AddPartialSignature(psbt *pb.PartialTransaction, key *ecdsa.PrivateKey) error {
+	if psbt.Transaction == nil || len(psbt.Spending) != len(psbt.Transaction.Vin) {
+		return errors.New("Malformed partial transaction")
+	}
+	pubKey := chain.GetPubKeyBytes(key)
+	owner := false
+	for _, txo := range psbt.Spending {
+		if CanSign(txo, pubKey) {
+			owner = true
+		}
+	}
+	if !owner {
+		return errors.New("Key does not own any of the inputs")
+	}
+	r, sig, err := ecdsa.Sign(rand.Reader, key, chain.GetTransactionHash(psbt.Transaction))
+	if err != nil {
+		return err
+	}
+	AddSignatures(psbt, []*pb.PartialSignature{&pb.PartialSignature{PubKey: pubKey, Signature: chain.GetSignatureBytes(r, sig)}})
+	return nil
+}
+
+// Whether a signature from pubKey helps unlock the output
+func CanSign(txo *pb.TXO, pubKey []byte) bool {
+	if len(txo.LockingScript) == 0 {
+		return bytes.Equal(txo.ReceiverPubKey, pubKey)
+	}
+	if _, pubKeys, ok := chain.ParseMultisigScript(txo.LockingScript); ok {
+		for _, multisigKey := range pubKeys {
+			if bytes.Equal(multisigKey, pubKey) {
+				return true
+			}
+		}
+		return false
+	}
+	return bytes.Equal(txo.LockingScript, chain.PayToPubKeyHashScript(pubKey))
+}
+
+// Add signatures from keys which haven't signed yet
+func AddSignatures(psbt *pb.PartialTransaction, signatures []*pb.PartialSignature) {
+	for _, signature := range signatures {
+		found := false
+		for _, existing := range psbt.Signatures {
+			if bytes.Equal(existing.PubKey, signature.PubKey) {
+				found = true
+			}
+		}
+		if !found {
+			psbt.Signatures = append(psbt.Signatures, signature)
+		}
+	}
+}
+
+func FindSignature(signatures []*pb.PartialSignature, pubKey []byte, hash []byte) []byte {
+	for _, signature := range signatures {
+		if bytes.Equal(signature.PubKey, pubKey) && chain.VerifySignature(pubKey, hash, signature.Signature) {
+			return signature.Signature
+		}
+	}
+	return nil
+}
+
+// Unlocking script for the standard scripts we know how to sign for
+func BuildUnlockingScript(lockingScript []byte, signatures []*pb.PartialSignature, hash []byte) ([]byte, error) {
+	var b chain.ScriptBuilder
+	if m, pubKeys, ok := chain.ParseMultisigScript(lockingScript); ok {
+		// Signatures go in the same order as the pubkeys
+		found := 0
+		for _, pubKey := range pubKeys {
+			if signature := FindSignature(signatures, pubKey, hash); signature != nil && found < m {
+				b.AddData(signature)
+				found++
+			}
+		}
+		if found < m {
+			return nil, errors.New(fmt.Sprintf("Have %d of the %d signatures needed", found, m))
+		}
+		return b.Script(), nil
+	}
+	for _, signature := range signatures {
+		if bytes.Equal(lockingScript, chain.PayToPubKeyHashScript(signature.PubKey)) &&
+			chain.VerifySignature(signature.PubKey, hash, signature.Signature) {
+			return chain.PayToPubKeyHashUnlockingScript(signature.Signature, signature.PubKey), nil
+		}
+	}
+	return nil, errors.New("Missing signature for script " + chain.GetScriptString(lockingScript))
+}
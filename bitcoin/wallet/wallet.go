@@ -0,0 +1,93 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/connorwstein/Blockchain/bitcoin/chain"
+	pb "github.com/connorwstein/Blockchain/bitcoin/protos"
+	"io/ioutil"
+)
+
+// In theory you can have many keys, not currently supported
+type Wallet struct {
+	Key   *ecdsa.PrivateKey
+	Curve elliptic.Curve
+	// Locking scripts we want to follow, e.g. multisig addresses we are part of
+	Scripts [][]byte
+}
+
+func (wallet *Wallet) WatchScript(script []byte) {
+	for _, existing := range wallet.Scripts {
+		if bytes.Equal(existing, script) {
+			return
+		}
+	}
+	wallet.Scripts = append(wallet.Scripts, script)
+}
+
+func (wallet *Wallet) CreateKey() error {
+	// Create a key pair
+	// Get a keypair
+	// Need the curve for our trapdoor
+	// Allocate memory for a private key
+	curve := elliptic.P256()
+	ellipticKey := new(ecdsa.PrivateKey)
+	// Generate the keypair based on the curve
+	ellipticKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return err
+	}
+	wallet.Key = ellipticKey
+	wallet.Curve = curve
+	return nil
+}
+
+// Use a key from a file, e.g. one made with the client's keygen
+func (wallet *Wallet) LoadKey(path string) error {
+	key, err := ReadKey(path)
+	if err != nil {
+		return err
+	}
+	wallet.Key = key
+	wallet.Curve = key.Curve
+	return nil
+}
+
+func ReadKey(path string) (*ecdsa.PrivateKey, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, errors.New("No PEM key found in " + path)
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// PEM file ReadKey and LoadKey can read back
+func WriteKey(path string, key *ecdsa.PrivateKey) error {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)
+}
+
+func SignTransaction(transaction *pb.Transaction, key *ecdsa.PrivateKey) *pb.Transaction {
+	transaction.Signature = SignTransactionHash(transaction, key)
+	return transaction
+}
+
+func SignTransactionHash(transaction *pb.Transaction, key *ecdsa.PrivateKey) []byte {
+	// Use the private key to create a signature associated with this transaction
+	// Note we treat public keys as just the concatenation of the x,y points on the elliptic curve
+	r, s, _ := ecdsa.Sign(rand.Reader, key, chain.GetTransactionHash(transaction))
+	// Returns two big ints which we concatenate as the signature
+	return chain.GetSignatureBytes(r, s)
+}